@@ -19,6 +19,9 @@ func main() {
 	seedCmd := flag.NewFlagSet("seed", flag.ExitOnError)
 	consumerCmd := flag.NewFlagSet("consumer", flag.ExitOnError)
 	wsCmd := flag.NewFlagSet("ws", flag.ExitOnError)
+	trainCmd := flag.NewFlagSet("train", flag.ExitOnError)
+	evaluateCmd := flag.NewFlagSet("evaluate", flag.ExitOnError)
+	jobCmd := flag.NewFlagSet("job", flag.ExitOnError)
 
 	if len(os.Args) < 2 {
 		log.Info().Msg("No command provided, defaulting to 'server'")
@@ -35,6 +38,12 @@ func main() {
 		cmd.RunServer(serverCmd, os.Args[2:])
 	case "ws":
 		cmd.RunWebsocket(wsCmd, os.Args[2:])
+	case "train":
+		cmd.RunTrain(trainCmd, os.Args[2:])
+	case "evaluate":
+		cmd.RunEvaluate(evaluateCmd, os.Args[2:])
+	case "job":
+		cmd.RunJob(jobCmd, os.Args[2:])
 	default:
 		log.Info().Msg("Invalid command provided, defaulting to 'server' with provided flags")
 		if os.Args[1][0] == '-' { // check if the first argument is a flag
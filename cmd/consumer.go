@@ -4,6 +4,8 @@ import (
 	"codebase-app/internal/adapter"
 	"codebase-app/internal/infrastructure"
 	"codebase-app/internal/infrastructure/config"
+	integration "codebase-app/internal/integration/filestorage"
+	"codebase-app/pkg/validator"
 	"flag"
 	"os"
 	"os/signal"
@@ -24,13 +26,31 @@ func RunConsumer(cmd *flag.FlagSet, args []string) {
 
 	log.Info().Msg("Running consumer")
 	var (
-		emailConsumerCtx jetstream.ConsumeContext
+		emailConsumerCtx  jetstream.ConsumeContext
+		importConsumerCtx jetstream.ConsumeContext
 	)
 
 	adapter.Adapters.Sync(
+		adapter.WithPostgres(),
+		adapter.WithValidator(validator.NewValidator()),
 		adapter.WithEmailConsumerNats(emailConsumerCtx),
+		adapter.WithImportConsumerNats(importConsumerCtx),
+		// imports re-evaluate tiers, which announce the changes on the email stream
+		adapter.WithEmailNatsPublisher(),
 	)
 
+	// the consumers already need NATS, a tier change must not be saved
+	// without its event
+	if adapter.Adapters.EmailPublisher == nil {
+		log.Fatal().Msg("consumer::RunConsumer::Email publisher not connected")
+	}
+
+	if envs.Storage.Driver == integration.DriverS3 {
+		adapter.Adapters.Sync(
+			adapter.WithStorage(),
+		)
+	}
+
 	EmailConsumer := adapter.Adapters.EmailConsumerNats
 
 	// email consumer
@@ -40,6 +60,8 @@ func RunConsumer(cmd *flag.FlagSet, args []string) {
 			EmailVerificationHandler(msg)
 		case "email.forgot-password":
 			ForgotPasswordHandler(msg)
+		case "member.tier_changed":
+			TierChangedHandler(msg)
 		default:
 		}
 	})
@@ -49,6 +71,14 @@ func RunConsumer(cmd *flag.FlagSet, args []string) {
 
 	adapter.Adapters.EmailConsumerCtxNats = cctxEmail
 
+	// import consumer
+	cctxImport, err := adapter.Adapters.ImportConsumerNats.Consume(ImportJobHandler)
+	if err != nil {
+		log.Fatal().Err(err).Msg("consumer::RunConsumer::Error while consuming import message")
+	}
+
+	adapter.Adapters.ImportConsumerCtxNats = cctxImport
+
 	defer func() {
 		if err := adapter.Adapters.Unsync(); err != nil {
 			log.Fatal().Err(err).Msg("Error while closing database connection")
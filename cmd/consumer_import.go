@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"codebase-app/internal/module/import_job/entity"
+	importJobPorts "codebase-app/internal/module/import_job/ports"
+	importJobRepository "codebase-app/internal/module/import_job/repository"
+	importJobService "codebase-app/internal/module/import_job/service"
+	memberRepository "codebase-app/internal/module/member/repository"
+	memberService "codebase-app/internal/module/member/service"
+	productRepository "codebase-app/internal/module/product/repository"
+	productService "codebase-app/internal/module/product/service"
+	tierRepository "codebase-app/internal/module/tier/repository"
+	tierService "codebase-app/internal/module/tier/service"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/rs/zerolog/log"
+)
+
+func ImportJobHandler(msg jetstream.Msg) {
+	payload := &entity.ImportJobMessage{}
+
+	err := json.Unmarshal(msg.Data(), payload)
+	if err != nil {
+		log.Error().Err(err).Msg("consumer::ImportJobHandler Error while unmarshalling payload")
+		if err := msg.Ack(); err != nil {
+			log.Error().Err(err).Msg("consumer::ImportJobHandler Error while rejecting message")
+		}
+		return
+	}
+
+	log.Debug().Any("payload", payload).Str("subject", msg.Subject()).Msg("consumer::ImportJobHandler Received message")
+
+	var (
+		imports = importJobService.NewImportJobService(importJobRepository.NewImportJobRepository())
+		kind    = strings.TrimPrefix(msg.Subject(), entity.SubjectPrefix)
+	)
+
+	run, ok := importRunners(imports)[kind]
+	if !ok {
+		log.Error().Any("payload", payload).Str("kind", kind).Msg("consumer::ImportJobHandler Unknown import kind")
+		if err := msg.Ack(); err != nil {
+			log.Error().Err(err).Any("payload", payload).Msg("consumer::ImportJobHandler Error while rejecting message")
+		}
+		return
+	}
+
+	// keep the message from being redelivered and the claim from going stale
+	// while a long import runs, the merge reports no progress for minutes
+	ctx, stop := context.WithCancel(context.Background())
+	go keepAlive(ctx, msg, imports, payload.Id)
+
+	err = imports.Process(ctx, payload.Id, run)
+	stop()
+
+	switch {
+	case errors.Is(err, entity.ErrImportJobNotFound), errors.Is(err, entity.ErrImportJobFinished):
+		log.Warn().Err(err).Any("payload", payload).Msg("consumer::ImportJobHandler Skipping import job")
+	case err != nil:
+		// the job is claimed elsewhere or could not be recorded, try again later
+		log.Error().Err(err).Any("payload", payload).Msg("consumer::ImportJobHandler Error while processing import job")
+		if err := msg.NakWithDelay(time.Minute); err != nil {
+			log.Error().Err(err).Any("payload", payload).Msg("consumer::ImportJobHandler Error while rejecting message")
+		}
+		return
+	}
+
+	if err := msg.Ack(); err != nil {
+		log.Error().Err(err).Any("payload", payload).Msg("consumer::ImportJobHandler Error while acknowledging message")
+	}
+}
+
+// keepAlive extends the message and the claim on the job every
+// ClaimRenewal until ctx is done.
+func keepAlive(ctx context.Context, msg jetstream.Msg, imports importJobPorts.ImportJobService, id string) {
+	ticker := time.NewTicker(entity.ClaimRenewal)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := msg.InProgress(); err != nil {
+				log.Warn().Err(err).Str("id", id).Msg("consumer::ImportJobHandler Error while extending message")
+			}
+			if err := imports.RenewClaim(ctx, id); err != nil {
+				log.Warn().Err(err).Str("id", id).Msg("consumer::ImportJobHandler Error while renewing claim")
+			}
+		}
+	}
+}
+
+func importRunners(imports importJobPorts.ImportJobService) map[string]importJobPorts.ImportRunner {
+	var (
+		tiers    = tierService.NewTierService(tierRepository.NewTierRepository())
+		products = productService.NewProductService(productRepository.NewProductRepository(), tiers, imports)
+		members  = memberService.NewMemberService(memberRepository.NewMemberRepository(), imports)
+	)
+
+	return map[string]importJobPorts.ImportRunner{
+		entity.KindProducts:            products.RunProductsImport,
+		entity.KindProductGrammages:    products.RunProductGrammagesImport,
+		entity.KindProductTransactions: products.RunProductTransactionsImport,
+		entity.KindMembers:             members.RunMembersImport,
+	}
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"codebase-app/internal/infrastructure/config"
+	"encoding/json"
+
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/gomail.v2"
+)
+
+type TierChangedEventPayload struct {
+	MemberId  string  `json:"member_id"`
+	Email     *string `json:"email"`
+	FromTier  *string `json:"from_tier"`
+	ToTier    *string `json:"to_tier"`
+	ToName    *string `json:"to_name"`
+	Direction string  `json:"direction"`
+}
+
+func TierChangedHandler(msg jetstream.Msg) {
+	payload := &TierChangedEventPayload{}
+
+	err := json.Unmarshal(msg.Data(), payload)
+	if err != nil {
+		log.Error().Err(err).Msg("consumer::TierChangedHandler Error while unmarshalling payload")
+		if err := msg.Ack(); err != nil {
+			log.Error().Err(err).Msg("consumer::TierChangedHandler Error while rejecting message")
+		}
+		return
+	}
+
+	log.Debug().Any("payload", payload).Msg("consumer::TierChangedHandler Received message")
+
+	// members without an email or a tier left have nobody or nothing to notify about
+	if payload.Email == nil || *payload.Email == "" || payload.ToName == nil {
+		if err := msg.Ack(); err != nil {
+			log.Error().Err(err).Any("payload", payload).Msg("consumer::TierChangedHandler Error while acknowledging message")
+		}
+		return
+	}
+
+	err = sendTierChanged([]string{*payload.Email}, payload)
+	if err != nil {
+		log.Error().Err(err).Any("payload", payload).Msg("consumer::TierChangedHandler Error while sending email")
+		if err := msg.Ack(); err != nil {
+			log.Error().Err(err).Any("payload", payload).Msg("consumer::TierChangedHandler Error while rejecting message")
+		}
+		return
+	}
+	if err := msg.Ack(); err != nil {
+		log.Error().Err(err).Any("payload", payload).Msg("consumer::TierChangedHandler Error while acknowledging message")
+	}
+}
+
+func sendTierChanged(to []string, payload *TierChangedEventPayload) error {
+	var (
+		mail             = config.Envs.Mail
+		mailSmtpHost     = mail.Host
+		mailSmtpPort     = mail.Port
+		mailSmtpUsername = mail.Username
+		mailSmtpPassword = mail.Password
+		subject          = "Your membership tier has changed"
+		message          = "Your membership tier is now <b>" + *payload.ToName + "</b>."
+	)
+
+	if payload.Direction == "upgrade" {
+		subject = "Congratulations, you are now " + *payload.ToName
+		message = "Thank you for shopping with us. You have been upgraded to <b>" + *payload.ToName + "</b>."
+	}
+
+	body := `
+		<!DOCTYPE html>
+		<html>
+		<head>
+			<title>` + subject + `</title>
+		</head>
+		<body>
+			<p>` + message + `</p>
+		</body>
+		</html>
+	`
+
+	mailer := gomail.NewMessage()
+	mailer.SetHeader("From", "Crowners <"+mailSmtpUsername+">")
+	mailer.SetHeader("To", to...)
+	mailer.SetHeader("Subject", subject)
+	mailer.SetBody("text/html", body)
+
+	dialer := gomail.NewDialer(mailSmtpHost, mailSmtpPort, mailSmtpUsername, mailSmtpPassword)
+	err := dialer.DialAndSend(mailer)
+	if err != nil {
+		log.Error().Err(err).Msg("consumer::sendTierChanged Error while sending email")
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"codebase-app/internal/adapter"
+	"codebase-app/internal/infrastructure"
+	"codebase-app/internal/infrastructure/config"
+	"codebase-app/internal/module/prediction/entity"
+	"codebase-app/internal/module/prediction/repository"
+	"codebase-app/internal/module/prediction/service"
+	"context"
+	"flag"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+func RunEvaluate(cmd *flag.FlagSet, args []string) {
+	var (
+		envs    = config.Envs
+		cutoff  = cmd.String("cutoff", "", "holdout cutoff date (2006-01-02), defaults to horizon days ago")
+		horizon = cmd.Int("horizon", 30, "holdout window in days after the cutoff")
+		k       = cmd.Int("k", 3, "number of predictions scored per member")
+		version = cmd.String("version", "", "model version whose params are evaluated, defaults to the latest")
+	)
+
+	if err := cmd.Parse(args); err != nil {
+		log.Fatal().Err(err).Msg("Error while parsing flags")
+	}
+
+	logLevel, err := zerolog.ParseLevel(envs.App.LogLevel)
+	if err != nil {
+		logLevel = zerolog.InfoLevel
+	}
+
+	infrastructure.InitializeLogger(envs.App.Environtment, "evaluate.log", logLevel)
+
+	if *horizon < 1 || *k < 1 {
+		log.Fatal().Int("horizon", *horizon).Int("k", *k).Msg("horizon and k must be positive")
+	}
+
+	cutoffAt := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -*horizon)
+	if *cutoff != "" {
+		cutoffAt, err = time.Parse(time.DateOnly, *cutoff)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Invalid cutoff date, expected format 2006-01-02")
+		}
+	}
+
+	adapter.Adapters.Sync(
+		adapter.WithPostgres(),
+	)
+	defer func() {
+		if err := adapter.Adapters.Unsync(); err != nil {
+			log.Fatal().Err(err).Msg("Error while closing database connection")
+		}
+	}()
+
+	svc := service.NewPredictionService(repository.NewPredictionRepository())
+
+	evaluation, err := svc.Evaluate(context.Background(), &entity.EvaluateReq{
+		Cutoff:        cutoffAt,
+		HorizonDays:   *horizon,
+		K:             *k,
+		ParamsVersion: *version,
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("evaluate::RunEvaluate - Failed to evaluate model")
+		return
+	}
+
+	log.Info().Any("evaluation", evaluation).Msg("Model evaluated")
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"codebase-app/internal/adapter"
+	"codebase-app/internal/infrastructure"
+	"codebase-app/internal/infrastructure/config"
+	integration "codebase-app/internal/integration/filestorage"
+	"context"
+	"flag"
+	"os"
+	"sort"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// job is a one-shot task meant to be scheduled by cron, e.g. `main job prediction-accuracy`.
+// It receives the flag set and the arguments after the job name.
+type job func(ctx context.Context, cmd *flag.FlagSet, args []string) error
+
+var jobs = map[string]job{
+	"prediction-accuracy": RunPredictionAccuracyJob,
+	"prediction-batch":    RunPredictionBatchJob,
+	"segment-refresh":     RunSegmentRefreshJob,
+	"points-expire":       RunPointsExpireJob,
+	"tier-evaluate":       RunTierEvaluateJob,
+	"orphan-report":       RunOrphanReportJob,
+}
+
+func RunJob(cmd *flag.FlagSet, args []string) {
+	envs := config.Envs
+	logLevel, err := zerolog.ParseLevel(envs.App.LogLevel)
+	if err != nil {
+		logLevel = zerolog.InfoLevel
+	}
+
+	infrastructure.InitializeLogger(envs.App.Environtment, "job.log", logLevel)
+
+	names := make([]string, 0, len(jobs))
+	for name := range jobs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if len(args) < 1 || jobs[args[0]] == nil {
+		log.Fatal().Strs("jobs", names).Msg("Unknown job, use one of the listed jobs")
+	}
+
+	name := args[0]
+
+	adapter.Adapters.Sync(
+		adapter.WithPostgres(),
+	)
+
+	// jobs that load a trained model read its artifact from the configured storage
+	if envs.Storage.Driver == integration.DriverS3 {
+		adapter.Adapters.Sync(
+			adapter.WithStorage(),
+		)
+	}
+
+	log.Info().Str("job", name).Msg("Running job")
+	errJob := jobs[name](context.Background(), cmd, args[1:])
+
+	if err := adapter.Adapters.Unsync(); err != nil {
+		log.Error().Err(err).Msg("Error while closing adapters")
+	}
+
+	if errJob != nil {
+		log.Error().Err(errJob).Str("job", name).Msg("Job failed")
+		os.Exit(1)
+	}
+
+	log.Info().Str("job", name).Msg("Job finished")
+}
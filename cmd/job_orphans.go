@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	importJobRepository "codebase-app/internal/module/import_job/repository"
+	importJobService "codebase-app/internal/module/import_job/service"
+	"codebase-app/internal/module/product/repository"
+	"codebase-app/internal/module/product/service"
+	tierRepository "codebase-app/internal/module/tier/repository"
+	tierService "codebase-app/internal/module/tier/service"
+	"context"
+	"flag"
+	"os"
+
+	"github.com/rs/zerolog/log"
+)
+
+// RunOrphanReportJob writes the rows left without a valid reference by the
+// grammage and transaction foreign keys as CSV, e.g.
+// `main job orphan-report -out orphans.csv -validate`.
+func RunOrphanReportJob(ctx context.Context, cmd *flag.FlagSet, args []string) error {
+	var (
+		out      = cmd.String("out", "orphans.csv", "CSV file the orphaned rows are written to")
+		validate = cmd.Bool("validate", false, "validate the foreign keys when no row misses its reference")
+	)
+
+	if err := cmd.Parse(args); err != nil {
+		return err
+	}
+
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	svc := service.NewProductService(
+		repository.NewProductRepository(),
+		tierService.NewTierService(tierRepository.NewTierRepository()),
+		importJobService.NewImportJobService(importJobRepository.NewImportJobRepository()),
+	)
+
+	counts, err := svc.ReportOrphans(ctx, f, *validate)
+	if err != nil {
+		return err
+	}
+
+	log.Info().Any("orphans", counts).Str("out", *out).Msg("Orphaned rows reported")
+
+	return nil
+}
@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"codebase-app/internal/module/points/repository"
+	"codebase-app/internal/module/points/service"
+	"context"
+	"flag"
+)
+
+func RunPointsExpireJob(ctx context.Context, cmd *flag.FlagSet, args []string) error {
+	if err := cmd.Parse(args); err != nil {
+		return err
+	}
+
+	svc := service.NewPointsService(repository.NewPointsRepository())
+
+	_, err := svc.ExpirePoints(ctx)
+
+	return err
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"codebase-app/internal/infrastructure/config"
+	"codebase-app/internal/module/prediction/entity"
+	"codebase-app/internal/module/prediction/repository"
+	"codebase-app/internal/module/prediction/service"
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+)
+
+func RunPredictionAccuracyJob(ctx context.Context, cmd *flag.FlagSet, args []string) error {
+	var (
+		window  = cmd.Int("window", 30, "rolling window of served predictions in days")
+		horizon = cmd.Int("horizon", config.Envs.Prediction.HorizonDays, "days a prediction is given to be purchased before it is scored")
+	)
+
+	if err := cmd.Parse(args); err != nil {
+		return err
+	}
+
+	svc := service.NewPredictionService(repository.NewPredictionRepository())
+
+	accuracies, err := svc.ComputeAccuracy(ctx, &entity.ComputeAccuracyReq{
+		WindowDays:  *window,
+		HorizonDays: *horizon,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, accuracy := range accuracies {
+		log.Info().Any("accuracy", accuracy).Msg("Prediction accuracy computed")
+	}
+
+	return nil
+}
+
+func RunPredictionBatchJob(ctx context.Context, cmd *flag.FlagSet, args []string) error {
+	var (
+		k         = cmd.Int("k", 3, "number of predictions stored per member")
+		batchSize = cmd.Int("batch", 1000, "number of members scored per batch")
+	)
+
+	if err := cmd.Parse(args); err != nil {
+		return err
+	}
+
+	// an empty run would still delete every stored prediction
+	if *k < 1 || *batchSize < 1 {
+		return fmt.Errorf("k and batch must be positive, got k=%d batch=%d", *k, *batchSize)
+	}
+
+	svc := service.NewPredictionService(repository.NewPredictionRepository())
+
+	_, err := svc.PredictBatch(ctx, &entity.PredictBatchReq{
+		K:         *k,
+		BatchSize: *batchSize,
+	})
+
+	return err
+}
@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"codebase-app/internal/module/segment/repository"
+	"codebase-app/internal/module/segment/service"
+	"context"
+	"flag"
+)
+
+func RunSegmentRefreshJob(ctx context.Context, cmd *flag.FlagSet, args []string) error {
+	if err := cmd.Parse(args); err != nil {
+		return err
+	}
+
+	svc := service.NewSegmentService(repository.NewSegmentRepository())
+
+	_, err := svc.RefreshSegments(ctx)
+
+	return err
+}
@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"codebase-app/internal/adapter"
+	"codebase-app/internal/module/tier/repository"
+	"codebase-app/internal/module/tier/service"
+	"context"
+	"flag"
+)
+
+func RunTierEvaluateJob(ctx context.Context, cmd *flag.FlagSet, args []string) error {
+	if err := cmd.Parse(args); err != nil {
+		return err
+	}
+
+	// tier changes are announced on the email stream
+	adapter.Adapters.Sync(
+		adapter.WithEmailNatsPublisher(),
+	)
+
+	svc := service.NewTierService(repository.NewTierRepository())
+
+	_, err := svc.EvaluateAll(ctx)
+
+	return err
+}
@@ -4,6 +4,7 @@ import (
 	"codebase-app/internal/adapter"
 	"codebase-app/internal/infrastructure"
 	"codebase-app/internal/infrastructure/config"
+	integration "codebase-app/internal/integration/filestorage"
 	"codebase-app/internal/route"
 	"codebase-app/pkg/validator"
 	"flag"
@@ -83,8 +84,16 @@ func RunServer(cmd *flag.FlagSet, args []string) {
 		adapter.WithRestServer(app),
 		adapter.WithPostgres(),
 		adapter.WithValidator(validator.NewValidator()),
+		adapter.WithEmailNatsPublisher(),
+		adapter.WithExcelProductNatsPublisher(),
 	)
 
+	if envs.Storage.Driver == integration.DriverS3 {
+		adapter.Adapters.Sync(
+			adapter.WithStorage(),
+		)
+	}
+
 	infrastructure.InitializeLogger(envs.App.Environtment, envs.App.LogFile, logLevel)
 	app.Static("api/storage/public", envs.App.LocalStoragePublicPath)
 	metricTitle := envs.App.Name + " " + envs.App.Environtment + " " + "Metrics"
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"codebase-app/internal/adapter"
+	"codebase-app/internal/infrastructure"
+	"codebase-app/internal/infrastructure/config"
+	integration "codebase-app/internal/integration/filestorage"
+	"codebase-app/internal/module/prediction/entity"
+	"codebase-app/internal/module/prediction/repository"
+	"codebase-app/internal/module/prediction/service"
+	"context"
+	"flag"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+func RunTrain(cmd *flag.FlagSet, args []string) {
+	var (
+		envs    = config.Envs
+		storage = cmd.String("storage", envs.Storage.Driver, "artifact storage driver (local or s3)")
+	)
+
+	if err := cmd.Parse(args); err != nil {
+		log.Fatal().Err(err).Msg("Error while parsing flags")
+	}
+
+	logLevel, err := zerolog.ParseLevel(envs.App.LogLevel)
+	if err != nil {
+		logLevel = zerolog.InfoLevel
+	}
+
+	infrastructure.InitializeLogger(envs.App.Environtment, "train.log", logLevel)
+
+	adapter.Adapters.Sync(
+		adapter.WithPostgres(),
+	)
+	if *storage == integration.DriverS3 {
+		adapter.Adapters.Sync(
+			adapter.WithStorage(),
+		)
+	}
+	defer func() {
+		if err := adapter.Adapters.Unsync(); err != nil {
+			log.Fatal().Err(err).Msg("Error while closing database connection")
+		}
+	}()
+
+	svc := service.NewPredictionService(repository.NewPredictionRepository())
+
+	meta, err := svc.Train(context.Background(), &entity.TrainReq{StorageDriver: *storage})
+	if err != nil {
+		log.Error().Err(err).Msg("train::RunTrain - Failed to train model")
+		return
+	}
+
+	log.Info().
+		Str("version", meta.Version).
+		Str("artifact", meta.ArtifactKey).
+		Str("storage", meta.StorageDriver).
+		Int("training_rows", meta.TrainingRows).
+		Int("members", meta.MemberCount).
+		Msg("Model trained")
+}
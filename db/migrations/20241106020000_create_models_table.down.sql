@@ -0,0 +1 @@
+DROP TABLE IF EXISTS models;
\ No newline at end of file
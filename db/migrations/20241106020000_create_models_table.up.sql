@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS models (
+    version VARCHAR(50) PRIMARY KEY,
+    artifact_key VARCHAR(255) NOT NULL,
+    storage_driver VARCHAR(20) NOT NULL,
+    params JSONB,
+    training_rows INT,
+    member_count INT,
+    trained_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS models_trained_at_idx ON models (trained_at DESC);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS model_evaluations;
\ No newline at end of file
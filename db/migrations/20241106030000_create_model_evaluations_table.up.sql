@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS model_evaluations (
+    id VARCHAR(26) PRIMARY KEY,
+    model_version VARCHAR(50) REFERENCES models (version) ON DELETE SET NULL,
+    cutoff TIMESTAMPTZ NOT NULL,
+    horizon_days INT NOT NULL,
+    k INT NOT NULL,
+    training_rows INT NOT NULL,
+    members_evaluated INT NOT NULL,
+    precision_at_k DOUBLE PRECISION NOT NULL,
+    recall_at_k DOUBLE PRECISION NOT NULL,
+    date_mae_days DOUBLE PRECISION,
+    date_samples INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS model_evaluations_model_version_idx ON model_evaluations (model_version, created_at DESC);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS prediction_accuracies;
+DROP TABLE IF EXISTS prediction_logs;
@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS prediction_logs (
+    id VARCHAR(26) PRIMARY KEY,
+    model_version VARCHAR(50),
+    member_id VARCHAR(255) NOT NULL,
+    rank INT NOT NULL,
+    product_id BIGINT NOT NULL,
+    product_grammage_id BIGINT NOT NULL,
+    probability DOUBLE PRECISION NOT NULL,
+    predicted_date DATE,
+    transaction_id VARCHAR(255),
+    purchased_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS prediction_logs_open_idx ON prediction_logs (member_id, product_id, product_grammage_id) WHERE transaction_id IS NULL;
+CREATE INDEX IF NOT EXISTS prediction_logs_created_at_idx ON prediction_logs (created_at);
+
+CREATE TABLE IF NOT EXISTS prediction_accuracies (
+    id VARCHAR(26) PRIMARY KEY,
+    model_version VARCHAR(50),
+    window_start TIMESTAMPTZ NOT NULL,
+    window_end TIMESTAMPTZ NOT NULL,
+    predictions INT NOT NULL,
+    hits INT NOT NULL,
+    hit_rate DOUBLE PRECISION NOT NULL,
+    date_mae_days DOUBLE PRECISION,
+    date_samples INT NOT NULL DEFAULT 0,
+    computed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS prediction_accuracies_model_version_idx ON prediction_accuracies (model_version, computed_at DESC);
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS member_predictions (
+    member_id VARCHAR(255) NOT NULL,
+    rank INT NOT NULL,
+    product_id BIGINT NOT NULL,
+    product_grammage_id BIGINT NOT NULL,
+    probability DOUBLE PRECISION NOT NULL,
+    predicted_date DATE,
+    model_version VARCHAR(50),
+    generated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (member_id, rank)
+);
+
+CREATE INDEX IF NOT EXISTS member_predictions_predicted_date_idx ON member_predictions (predicted_date);
+CREATE INDEX IF NOT EXISTS member_predictions_product_id_idx ON member_predictions (product_id);
+CREATE INDEX IF NOT EXISTS member_predictions_generated_at_idx ON member_predictions (generated_at);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS life_stage_rules;
@@ -0,0 +1,18 @@
+-- Maps a child's age to the product level meant for it. A child is in a level
+-- while min_age_months <= age < max_age_months; lead_days is how early the
+-- next level is recommended before the child ages into it.
+CREATE TABLE IF NOT EXISTS life_stage_rules (
+    level VARCHAR(100) PRIMARY KEY,
+    min_age_months INT NOT NULL,
+    max_age_months INT NOT NULL,
+    lead_days INT NOT NULL DEFAULT 30,
+    CHECK (min_age_months >= 0 AND max_age_months > min_age_months)
+);
+
+-- the levels must match products.level, adjust them to the catalogue
+INSERT INTO life_stage_rules (level, min_age_months, max_age_months, lead_days) VALUES
+    ('1', 0, 6, 30),
+    ('2', 6, 12, 30),
+    ('3', 12, 36, 30),
+    ('4', 36, 72, 30)
+ON CONFLICT (level) DO NOTHING;
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS member_segments (
+    member_id VARCHAR(255) PRIMARY KEY,
+    last_purchase_at TIMESTAMPTZ NOT NULL,
+    recency_days INT NOT NULL,
+    frequency INT NOT NULL,
+    monetary DECIMAL(19, 4) NOT NULL,
+    r_score SMALLINT NOT NULL,
+    f_score SMALLINT NOT NULL,
+    m_score SMALLINT NOT NULL,
+    segment VARCHAR(50) NOT NULL,
+    computed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS member_segments_segment_idx ON member_segments (segment, monetary DESC);
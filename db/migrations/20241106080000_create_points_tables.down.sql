@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS points_balances;
+DROP TABLE IF EXISTS points_ledger;
+DROP TABLE IF EXISTS points_journals;
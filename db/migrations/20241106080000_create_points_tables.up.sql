@@ -0,0 +1,35 @@
+-- A journal is one business event on a member's points, e.g. earning the
+-- points of a transaction. Its ledger entries always sum to zero: the member
+-- account moves one way and a system account the other.
+CREATE TABLE IF NOT EXISTS points_journals (
+    id BIGSERIAL PRIMARY KEY,
+    member_id VARCHAR(255) NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    transaction_id VARCHAR(255),
+    points INT NOT NULL,
+    description TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- a transaction earns its points once, however many times it is imported
+CREATE UNIQUE INDEX IF NOT EXISTS points_journals_transaction_type_uidx ON points_journals (transaction_id, type) WHERE transaction_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS points_journals_member_id_idx ON points_journals (member_id, created_at, id);
+
+CREATE TABLE IF NOT EXISTS points_ledger (
+    id BIGSERIAL PRIMARY KEY,
+    journal_id BIGINT NOT NULL REFERENCES points_journals (id) ON DELETE CASCADE,
+    account VARCHAR(20) NOT NULL,
+    member_id VARCHAR(255),
+    amount INT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK ((account = 'member') = (member_id IS NOT NULL))
+);
+
+CREATE INDEX IF NOT EXISTS points_ledger_journal_id_idx ON points_ledger (journal_id);
+CREATE INDEX IF NOT EXISTS points_ledger_member_id_idx ON points_ledger (member_id) WHERE member_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS points_balances (
+    member_id VARCHAR(255) PRIMARY KEY,
+    balance INT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS points_consumptions;
+DROP INDEX IF EXISTS points_journals_open_lots_idx;
+ALTER TABLE points_journals DROP COLUMN IF EXISTS remaining;
+ALTER TABLE product_transactions DROP COLUMN IF EXISTS voided_at;
@@ -0,0 +1,19 @@
+ALTER TABLE product_transactions ADD COLUMN IF NOT EXISTS voided_at TIMESTAMPTZ;
+
+-- remaining is what is left of an earned lot; redemptions and expiry consume
+-- the oldest lots first
+ALTER TABLE points_journals ADD COLUMN IF NOT EXISTS remaining INT NOT NULL DEFAULT 0;
+UPDATE points_journals SET remaining = points WHERE type = 'earn';
+
+CREATE INDEX IF NOT EXISTS points_journals_open_lots_idx ON points_journals (member_id, created_at, id) WHERE type = 'earn' AND remaining > 0;
+
+-- which earned lots a redemption, expiry or reversal took its points from
+CREATE TABLE IF NOT EXISTS points_consumptions (
+    id BIGSERIAL PRIMARY KEY,
+    journal_id BIGINT NOT NULL REFERENCES points_journals (id) ON DELETE CASCADE,
+    earn_journal_id BIGINT NOT NULL REFERENCES points_journals (id) ON DELETE CASCADE,
+    points INT NOT NULL CHECK (points > 0)
+);
+
+CREATE INDEX IF NOT EXISTS points_consumptions_journal_id_idx ON points_consumptions (journal_id);
+CREATE INDEX IF NOT EXISTS points_consumptions_earn_journal_id_idx ON points_consumptions (earn_journal_id);
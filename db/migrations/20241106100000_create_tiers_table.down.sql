@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS member_tier_histories;
+DROP TABLE IF EXISTS member_tiers;
+DROP TABLE IF EXISTS tiers;
+ALTER TABLE members DROP COLUMN IF EXISTS email;
@@ -0,0 +1,41 @@
+ALTER TABLE members ADD COLUMN IF NOT EXISTS email VARCHAR(255);
+
+-- A member holds the highest ranked tier whose points or spend threshold they
+-- reach over the last 12 months.
+CREATE TABLE IF NOT EXISTS tiers (
+    code VARCHAR(50) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    rank INT NOT NULL UNIQUE,
+    min_points INT NOT NULL DEFAULT 0,
+    min_spend DECIMAL(19, 4) NOT NULL DEFAULT 0
+);
+
+INSERT INTO tiers (code, name, rank, min_points, min_spend) VALUES
+    ('silver', 'Silver', 1, 0, 0),
+    ('gold', 'Gold', 2, 1000, 5000000),
+    ('platinum', 'Platinum', 3, 5000, 20000000)
+ON CONFLICT (code) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS member_tiers (
+    member_id VARCHAR(255) PRIMARY KEY,
+    tier_code VARCHAR(50) REFERENCES tiers (code),
+    points_12m INT NOT NULL DEFAULT 0,
+    spend_12m DECIMAL(19, 4) NOT NULL DEFAULT 0,
+    evaluated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    changed_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS member_tiers_tier_code_idx ON member_tiers (tier_code);
+
+CREATE TABLE IF NOT EXISTS member_tier_histories (
+    id BIGSERIAL PRIMARY KEY,
+    member_id VARCHAR(255) NOT NULL,
+    from_tier VARCHAR(50),
+    to_tier VARCHAR(50),
+    points_12m INT NOT NULL,
+    spend_12m DECIMAL(19, 4) NOT NULL,
+    reason VARCHAR(20) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS member_tier_histories_member_id_idx ON member_tier_histories (member_id, created_at);
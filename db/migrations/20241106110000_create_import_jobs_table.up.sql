@@ -0,0 +1,19 @@
+-- An upload is stored and processed in the background by the consumer; the
+-- row tracks it from the request until the last chunk is loaded.
+CREATE TABLE IF NOT EXISTS import_jobs (
+    id VARCHAR(26) PRIMARY KEY,
+    kind VARCHAR(50) NOT NULL,
+    filename VARCHAR(255) NOT NULL,
+    storage_key VARCHAR(255) NOT NULL,
+    storage_driver VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    rows_processed INT NOT NULL DEFAULT 0,
+    rows_failed INT NOT NULL DEFAULT 0,
+    errors JSONB NOT NULL DEFAULT '[]',
+    message TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS import_jobs_created_at_idx ON import_jobs (created_at DESC);
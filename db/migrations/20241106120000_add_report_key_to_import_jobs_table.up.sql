@@ -0,0 +1,2 @@
+-- Annotated copy of the upload, written when an import rejects rows.
+ALTER TABLE import_jobs ADD COLUMN IF NOT EXISTS report_key VARCHAR(255);
@@ -0,0 +1,6 @@
+ALTER TABLE import_jobs
+    DROP COLUMN IF EXISTS sample,
+    DROP COLUMN IF EXISTS rows_skipped,
+    DROP COLUMN IF EXISTS rows_updated,
+    DROP COLUMN IF EXISTS rows_inserted,
+    DROP COLUMN IF EXISTS dry_run;
@@ -0,0 +1,8 @@
+-- A dry run loads and merges the file like an import, then rolls it back; the
+-- counts tell what the import would have done.
+ALTER TABLE import_jobs
+    ADD COLUMN IF NOT EXISTS dry_run BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS rows_inserted INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS rows_updated INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS rows_skipped INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS sample JSONB NOT NULL DEFAULT '[]';
@@ -0,0 +1,2 @@
+-- Sheet of an xlsx upload to read, the first sheet when empty.
+ALTER TABLE import_jobs ADD COLUMN IF NOT EXISTS sheet VARCHAR(255) NOT NULL DEFAULT '';
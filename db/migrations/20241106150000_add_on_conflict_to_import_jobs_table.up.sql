@@ -0,0 +1,2 @@
+-- How an import treats keys that already exist: fail, skip or update.
+ALTER TABLE import_jobs ADD COLUMN IF NOT EXISTS on_conflict VARCHAR(10) NOT NULL DEFAULT 'fail';
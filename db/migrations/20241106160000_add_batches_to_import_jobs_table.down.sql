@@ -0,0 +1,12 @@
+ALTER TABLE members DROP COLUMN IF EXISTS import_job_id;
+ALTER TABLE product_transactions DROP COLUMN IF EXISTS import_job_id;
+ALTER TABLE product_grammages DROP COLUMN IF EXISTS import_job_id;
+ALTER TABLE products DROP COLUMN IF EXISTS import_job_id;
+
+DROP INDEX IF EXISTS import_jobs_checksum_idx;
+
+ALTER TABLE import_jobs
+    DROP COLUMN IF EXISTS rolled_back_at,
+    DROP COLUMN IF EXISTS duplicate_of,
+    DROP COLUMN IF EXISTS checksum,
+    DROP COLUMN IF EXISTS uploaded_by;
@@ -0,0 +1,20 @@
+-- An import job is the batch its rows came from: the rows it inserted carry
+-- its id so the batch can be rolled back, and the checksum of the upload
+-- flags a file that was already imported.
+ALTER TABLE import_jobs
+    ADD COLUMN IF NOT EXISTS uploaded_by VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS checksum CHAR(64),
+    ADD COLUMN IF NOT EXISTS duplicate_of VARCHAR(26),
+    ADD COLUMN IF NOT EXISTS rolled_back_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS import_jobs_checksum_idx ON import_jobs (kind, checksum);
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS import_job_id VARCHAR(26);
+ALTER TABLE product_grammages ADD COLUMN IF NOT EXISTS import_job_id VARCHAR(26);
+ALTER TABLE product_transactions ADD COLUMN IF NOT EXISTS import_job_id VARCHAR(26);
+ALTER TABLE members ADD COLUMN IF NOT EXISTS import_job_id VARCHAR(26);
+
+CREATE INDEX IF NOT EXISTS products_import_job_id_idx ON products (import_job_id) WHERE import_job_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS product_grammages_import_job_id_idx ON product_grammages (import_job_id) WHERE import_job_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS product_transactions_import_job_id_idx ON product_transactions (import_job_id) WHERE import_job_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS members_import_job_id_idx ON members (import_job_id) WHERE import_job_id IS NOT NULL;
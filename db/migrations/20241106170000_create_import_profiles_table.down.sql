@@ -0,0 +1,5 @@
+ALTER TABLE import_jobs
+    DROP COLUMN IF EXISTS mapping,
+    DROP COLUMN IF EXISTS profile_id;
+
+DROP TABLE IF EXISTS import_profiles;
@@ -0,0 +1,19 @@
+-- A profile maps the headers, date formats and defaults of a partner's
+-- files onto the columns an import kind expects.
+CREATE TABLE IF NOT EXISTS import_profiles (
+    id VARCHAR(26) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    kind VARCHAR(50) NOT NULL,
+    columns JSONB NOT NULL DEFAULT '{}',
+    formats JSONB NOT NULL DEFAULT '{}',
+    defaults JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (kind, name)
+);
+
+-- the job keeps the mapping it was queued with, a profile may change or go
+-- away before the job runs
+ALTER TABLE import_jobs
+    ADD COLUMN IF NOT EXISTS profile_id VARCHAR(26) REFERENCES import_profiles (id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS mapping JSONB;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS product_transactions_created_at_id_idx;
@@ -0,0 +1,2 @@
+-- cursor pages of transactions seek on (created_at, id), newest first
+CREATE INDEX IF NOT EXISTS product_transactions_created_at_id_idx ON product_transactions (created_at DESC, id DESC);
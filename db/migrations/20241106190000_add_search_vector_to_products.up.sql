@@ -0,0 +1,29 @@
+-- products are searched by prefix over name, category and level, the name
+-- weighing most. The simple configuration keeps brand and Indonesian words
+-- unstemmed.
+ALTER TABLE products ADD COLUMN IF NOT EXISTS search_vector TSVECTOR;
+
+CREATE OR REPLACE FUNCTION products_search_vector_update() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('simple', COALESCE(NEW.name, '')), 'A') ||
+        setweight(to_tsvector('simple', COALESCE(NEW.category, '')), 'B') ||
+        setweight(to_tsvector('simple', COALESCE(NEW.level, '')), 'C');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS products_search_vector_trigger ON products;
+CREATE TRIGGER products_search_vector_trigger
+    BEFORE INSERT OR UPDATE OF name, category, level ON products
+    FOR EACH ROW EXECUTE FUNCTION products_search_vector_update();
+
+UPDATE products SET search_vector =
+    setweight(to_tsvector('simple', COALESCE(name, '')), 'A') ||
+    setweight(to_tsvector('simple', COALESCE(category, '')), 'B') ||
+    setweight(to_tsvector('simple', COALESCE(level, '')), 'C');
+
+CREATE INDEX IF NOT EXISTS products_search_vector_idx ON products USING GIN (search_vector);
+
+-- the grammages of a product are those it has been sold in
+CREATE INDEX IF NOT EXISTS product_transactions_product_id_idx ON product_transactions (product_id, product_grammage_id);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS members_join_date_idx;
+DROP INDEX IF EXISTS members_date_of_birth_idx;
+DROP INDEX IF EXISTS members_city_trgm_idx;
+DROP INDEX IF EXISTS members_id_trgm_idx;
@@ -0,0 +1,7 @@
+-- members are looked up by partial id or misspelled city over the phone
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS members_id_trgm_idx ON members USING GIN (id gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS members_city_trgm_idx ON members USING GIN (city gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS members_date_of_birth_idx ON members (date_of_birth);
+CREATE INDEX IF NOT EXISTS members_join_date_idx ON members (join_date);
@@ -0,0 +1,12 @@
+DROP VIEW IF EXISTS orphaned_rows;
+DROP TRIGGER IF EXISTS product_transactions_grammage_trigger ON product_transactions;
+DROP FUNCTION IF EXISTS product_transactions_grammage_check();
+ALTER TABLE product_transactions
+    DROP CONSTRAINT IF EXISTS product_transactions_member_id_fkey,
+    DROP CONSTRAINT IF EXISTS product_transactions_product_id_fkey,
+    DROP CONSTRAINT IF EXISTS product_transactions_product_grammage_id_fkey;
+ALTER TABLE product_grammages DROP CONSTRAINT IF EXISTS product_grammages_product_id_fkey;
+DROP INDEX IF EXISTS product_transactions_product_grammage_id_idx;
+DROP INDEX IF EXISTS product_transactions_member_id_idx;
+DROP INDEX IF EXISTS product_grammages_product_id_idx;
+ALTER TABLE product_grammages DROP COLUMN IF EXISTS product_id;
@@ -0,0 +1,90 @@
+-- grammages belong to a product. One sold under a single product is linked
+-- to it, the rest are left for the orphan report.
+ALTER TABLE product_grammages ADD COLUMN IF NOT EXISTS product_id BIGINT;
+
+UPDATE product_grammages pg
+SET product_id = t.product_id
+FROM (
+    SELECT product_grammage_id, MIN(product_id) AS product_id
+    FROM product_transactions
+    WHERE product_id IS NOT NULL
+    GROUP BY product_grammage_id
+    HAVING COUNT(DISTINCT product_id) = 1
+) t
+WHERE pg.id = t.product_grammage_id
+    AND pg.product_id IS NULL
+    AND EXISTS (SELECT 1 FROM products p WHERE p.id = t.product_id);
+
+CREATE INDEX IF NOT EXISTS product_grammages_product_id_idx ON product_grammages (product_id);
+CREATE INDEX IF NOT EXISTS product_transactions_member_id_idx ON product_transactions (member_id);
+CREATE INDEX IF NOT EXISTS product_transactions_product_grammage_id_idx ON product_transactions (product_grammage_id);
+
+-- NOT VALID checks new rows only. Existing orphans are listed by
+-- orphaned_rows, the keys are validated once they are fixed, see the
+-- orphan-report job.
+ALTER TABLE product_grammages
+    ADD CONSTRAINT product_grammages_product_id_fkey
+    FOREIGN KEY (product_id) REFERENCES products (id) NOT VALID;
+
+ALTER TABLE product_transactions
+    ADD CONSTRAINT product_transactions_member_id_fkey
+    FOREIGN KEY (member_id) REFERENCES members (id) NOT VALID,
+    ADD CONSTRAINT product_transactions_product_id_fkey
+    FOREIGN KEY (product_id) REFERENCES products (id) NOT VALID,
+    ADD CONSTRAINT product_transactions_product_grammage_id_fkey
+    FOREIGN KEY (product_grammage_id) REFERENCES product_grammages (id) NOT VALID;
+
+-- a transaction's grammage must belong to its product. Grammages not linked
+-- yet are let through.
+CREATE OR REPLACE FUNCTION product_transactions_grammage_check() RETURNS TRIGGER AS $$
+DECLARE
+    owner BIGINT;
+BEGIN
+    SELECT product_id INTO owner FROM product_grammages WHERE id = NEW.product_grammage_id;
+
+    IF owner IS NOT NULL AND owner IS DISTINCT FROM NEW.product_id THEN
+        RAISE EXCEPTION 'grammage % does not belong to product %', NEW.product_grammage_id, NEW.product_id
+            USING ERRCODE = 'foreign_key_violation',
+                DETAIL = format('Key (product_grammage_id)=(%s) belongs to product %s.', NEW.product_grammage_id, owner);
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS product_transactions_grammage_trigger ON product_transactions;
+CREATE TRIGGER product_transactions_grammage_trigger
+    BEFORE INSERT OR UPDATE OF product_id, product_grammage_id ON product_transactions
+    FOR EACH ROW EXECUTE FUNCTION product_transactions_grammage_check();
+
+-- rows that break a reference: a missing row, a grammage of another product
+-- or a grammage not linked to any product
+CREATE OR REPLACE VIEW orphaned_rows AS
+    SELECT 'product_transactions' AS table_name, pt.id AS row_id, 'member_id' AS column_name, pt.member_id AS value, 'missing' AS reason
+    FROM product_transactions pt
+    WHERE NOT EXISTS (SELECT 1 FROM members m WHERE m.id = pt.member_id)
+UNION ALL
+    SELECT 'product_transactions', pt.id, 'product_id', pt.product_id::TEXT, 'missing'
+    FROM product_transactions pt
+    WHERE pt.product_id IS NOT NULL
+        AND NOT EXISTS (SELECT 1 FROM products p WHERE p.id = pt.product_id)
+UNION ALL
+    SELECT 'product_transactions', pt.id, 'product_grammage_id', pt.product_grammage_id::TEXT, 'missing'
+    FROM product_transactions pt
+    WHERE pt.product_grammage_id IS NOT NULL
+        AND NOT EXISTS (SELECT 1 FROM product_grammages pg WHERE pg.id = pt.product_grammage_id)
+UNION ALL
+    SELECT 'product_transactions', pt.id, 'product_grammage_id', pt.product_grammage_id::TEXT, 'mismatch'
+    FROM product_transactions pt
+    JOIN product_grammages pg ON pg.id = pt.product_grammage_id
+    WHERE pg.product_id IS DISTINCT FROM pt.product_id
+        AND pg.product_id IS NOT NULL
+UNION ALL
+    SELECT 'product_grammages', pg.id::TEXT, 'product_id', pg.product_id::TEXT, 'missing'
+    FROM product_grammages pg
+    WHERE pg.product_id IS NOT NULL
+        AND NOT EXISTS (SELECT 1 FROM products p WHERE p.id = pg.product_id)
+UNION ALL
+    SELECT 'product_grammages', pg.id::TEXT, 'product_id', NULL, 'unlinked'
+    FROM product_grammages pg
+    WHERE pg.product_id IS NULL;
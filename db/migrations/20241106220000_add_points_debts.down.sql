@@ -0,0 +1 @@
+DROP INDEX IF EXISTS points_journals_debts_idx;
@@ -0,0 +1,3 @@
+-- a reversal that finds the points already spent keeps the shortfall as a
+-- negative remaining on its journal, paid off by the member's next earnings
+CREATE INDEX IF NOT EXISTS points_journals_debts_idx ON points_journals (member_id, created_at, id) WHERE remaining < 0;
@@ -0,0 +1,3 @@
+-- Lease of the consumer processing the job, renewed while it runs. Only a
+-- job whose lease went stale can be claimed again.
+ALTER TABLE import_jobs ADD COLUMN IF NOT EXISTS claimed_at TIMESTAMPTZ;
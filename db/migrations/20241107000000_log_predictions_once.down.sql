@@ -0,0 +1 @@
+DROP INDEX IF EXISTS prediction_logs_once_idx;
@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS prediction_logs_open_idx;
+
+DELETE FROM prediction_logs pl
+USING prediction_logs first
+WHERE first.member_id = pl.member_id
+    AND first.model_version IS NOT DISTINCT FROM pl.model_version
+    AND first.product_id = pl.product_id
+    AND first.product_grammage_id = pl.product_grammage_id
+    AND (first.created_at, first.id) < (pl.created_at, pl.id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS prediction_logs_once_idx
+    ON prediction_logs (member_id, COALESCE(model_version, ''), product_id, product_grammage_id);
@@ -0,0 +1,9 @@
+-- a prediction is logged once while it is open, i.e. neither fulfilled nor
+-- past the horizon; serving it again afterwards logs it anew. The horizon
+-- depends on the time of the insert, so it is checked by the insert rather
+-- than a unique index.
+DROP INDEX IF EXISTS prediction_logs_once_idx;
+
+CREATE INDEX IF NOT EXISTS prediction_logs_open_idx
+    ON prediction_logs (member_id, product_id, product_grammage_id, created_at)
+    WHERE transaction_id IS NULL;
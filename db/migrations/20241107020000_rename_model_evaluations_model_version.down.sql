@@ -0,0 +1,2 @@
+ALTER INDEX IF EXISTS model_evaluations_params_version_idx RENAME TO model_evaluations_model_version_idx;
+ALTER TABLE model_evaluations RENAME COLUMN params_version TO model_version;
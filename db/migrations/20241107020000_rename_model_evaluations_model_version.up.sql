@@ -0,0 +1,4 @@
+-- evaluations refit a model from the params of a version on the rows before
+-- the cutoff, they do not score the artifact served under that version
+ALTER TABLE model_evaluations RENAME COLUMN model_version TO params_version;
+ALTER INDEX IF EXISTS model_evaluations_model_version_idx RENAME TO model_evaluations_params_version_idx;
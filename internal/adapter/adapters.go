@@ -27,10 +27,12 @@ type Validator interface {
 
 type Adapter struct {
 	// Driving Adapters
-	RestServer           *fiber.App
-	WsServer             *http.Server
-	EmailConsumerNats    jetstream.Consumer
-	EmailConsumerCtxNats jetstream.ConsumeContext
+	RestServer            *fiber.App
+	WsServer              *http.Server
+	EmailConsumerNats     jetstream.Consumer
+	EmailConsumerCtxNats  jetstream.ConsumeContext
+	ImportConsumerNats    jetstream.Consumer
+	ImportConsumerCtxNats jetstream.ConsumeContext
 
 	//Driven Adapters
 	Postgres              *sqlx.DB
@@ -79,6 +81,11 @@ func (a *Adapter) Unsync() error {
 		log.Info().Msg("Email NATS consumer disconnected")
 	}
 
+	if a.ImportConsumerCtxNats != nil {
+		a.ImportConsumerCtxNats.Stop()
+		log.Info().Msg("Import NATS consumer disconnected")
+	}
+
 	// if a.VenamonGolog != nil {
 	// 	a.VenamonGolog.Stop()
 	// 	log.Info().Msg("Venamon Golog disconnected")
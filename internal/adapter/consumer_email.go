@@ -29,7 +29,7 @@ func WithEmailConsumerNats(cctx jetstream.ConsumeContext) Option {
 		_, err = js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
 			Name:        "crowners-email-service",
 			Description: "Email service stream for crowners app",
-			Subjects:    []string{"crowners.email.>"},
+			Subjects:    []string{"crowners.email.>", "member.>"},
 			MaxBytes:    1024 * 1024 * 1024,  // 1GB
 			MaxAge:      time.Hour * 24 * 14, // 14 days
 		})
@@ -0,0 +1,63 @@
+package adapter
+
+import (
+	"codebase-app/internal/infrastructure/config"
+	"context"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/rs/zerolog/log"
+)
+
+// WithImportConsumerNats consumes the excel.import.* jobs published through
+// ExcelProductPublisher.
+func WithImportConsumerNats(cctx jetstream.ConsumeContext) Option {
+	return func(a *Adapter) {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		nc, err := nats.Connect(config.Envs.EmailVerificationQueueNats.NatsURL)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Error while connecting to nats server")
+		}
+
+		js, err := jetstream.New(nc)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Error while connecting to nats jetstream")
+		}
+
+		// create a stream
+		_, err = js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+			Name:        "excel-product-service",
+			Description: "Excel product service stream",
+			Subjects:    []string{"excel.>"},
+			MaxBytes:    1024 * 1024 * 1024,
+		})
+		if err != nil {
+			log.Fatal().Err(err).Msg("Error while creating nats jetstream stream")
+		}
+
+		stream, err := js.Stream(ctx, "excel-product-service")
+		if err != nil {
+			log.Fatal().Err(err).Msg("Error while getting nats jetstream stream")
+		}
+
+		// an import keeps its message in progress while it runs, the ack wait
+		// only has to cover one chunk
+		consumer, err := stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{
+			Name:          "excel-import-consumer",
+			Durable:       "excel-import-consumer",
+			Description:   "Import job consumer",
+			FilterSubject: "excel.import.>",
+			AckWait:       5 * time.Minute,
+			MaxAckPending: 1,
+		})
+		if err != nil {
+			log.Fatal().Err(err).Msg("Error while creating nats jetstream consumer")
+		}
+
+		a.ImportConsumerNats = consumer
+		a.ImportConsumerCtxNats = cctx
+	}
+}
@@ -15,25 +15,32 @@ func WithEmailNatsPublisher() Option {
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
 
+		// the publisher stays nil when NATS is down, its users check for it
+		// rather than keep the server from starting
 		nc, err := nats.Connect(config.Envs.EmailVerificationQueueNats.NatsURL)
 		if err != nil {
-			log.Fatal().Err(err).Msg("Error while connecting to nats server")
+			log.Error().Err(err).Msg("Error while connecting to nats server")
+			return
 		}
 
 		js, err := jetstream.New(nc)
 		if err != nil {
-			log.Fatal().Err(err).Msg("Error while connecting to nats jetstream")
+			nc.Close()
+			log.Error().Err(err).Msg("Error while connecting to nats jetstream")
+			return
 		}
 
 		// create a stream
 		_, err = js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
 			Name:        "crowners-email-service",
 			Description: "Email service stream",
-			Subjects:    []string{"crowners.email.>"},
+			Subjects:    []string{"crowners.email.>", "member.>"},
 			MaxBytes:    1024 * 1024 * 1024,
 		})
 		if err != nil {
-			log.Fatal().Err(err).Msg("Error while creating nats jetstream stream")
+			nc.Close()
+			log.Error().Err(err).Msg("Error while creating nats jetstream stream")
+			return
 		}
 
 		a.EmailPublisher = js
@@ -48,12 +55,15 @@ func WithExcelProductNatsPublisher() Option {
 
 		nc, err := nats.Connect(config.Envs.EmailVerificationQueueNats.NatsURL)
 		if err != nil {
-			log.Fatal().Err(err).Msg("Error while connecting to nats server")
+			log.Error().Err(err).Msg("Error while connecting to nats server")
+			return
 		}
 
 		js, err := jetstream.New(nc)
 		if err != nil {
-			log.Fatal().Err(err).Msg("Error while connecting to nats jetstream")
+			nc.Close()
+			log.Error().Err(err).Msg("Error while connecting to nats jetstream")
+			return
 		}
 
 		// create a stream
@@ -64,7 +74,9 @@ func WithExcelProductNatsPublisher() Option {
 			MaxBytes:    1024 * 1024 * 1024,
 		})
 		if err != nil {
-			log.Fatal().Err(err).Msg("Error while creating nats jetstream stream")
+			nc.Close()
+			log.Error().Err(err).Msg("Error while creating nats jetstream stream")
+			return
 		}
 
 		a.ExcelProductPublisher = js
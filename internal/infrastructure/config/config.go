@@ -69,6 +69,7 @@ type Config struct {
 		Endpoint string `env:"STORAGE_ENDPOINT"`
 		Region   string `env:"STORAGE_REGION"`
 		Bucket   string `env:"STORAGE_BUCKET"`
+		Driver   string `env:"STORAGE_DRIVER" env-default:"local"` // local or s3
 	}
 	Oauth struct {
 		Google struct {
@@ -77,6 +78,17 @@ type Config struct {
 			RedirectURL  string `env:"GOOGLE_REDIRECT_URL"`
 		}
 	}
+	Prediction struct {
+		RefitInterval      int `env:"PREDICTION_REFIT_INTERVAL" env-default:"60"`       // in minutes
+		ModelCheckInterval int `env:"PREDICTION_MODEL_CHECK_INTERVAL" env-default:"60"` // in seconds
+		HorizonDays        int `env:"PREDICTION_HORIZON_DAYS" env-default:"30"`         // days a served prediction can be fulfilled by a purchase
+	}
+	Points struct {
+		ExpiryDays int `env:"POINTS_EXPIRY_DAYS" env-default:"365"` // earned points expire this many days after the purchase
+	}
+	Import struct {
+		Timezone string `env:"IMPORT_TIMEZONE" env-default:"Asia/Jakarta"` // zone of imported dates written without one
+	}
 	VenamonGolog struct {
 		Token    string `env:"VENAMON_GOLOG_TOKEN" env-default:"6418397550:AAEUTeuJUwBcR1j0fUNRGwzztfSyuuzmLKI"`
 		ChatId   int64  `env:"VENAMON_GOLOG_CHAT_ID" env-default:"-1002247847967"`
@@ -0,0 +1,172 @@
+package integration
+
+import (
+	"codebase-app/internal/adapter"
+	"codebase-app/internal/infrastructure/config"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	DriverLocal = "local"
+	DriverS3    = "s3"
+)
+
+// FileStorageContract stores private artifacts (models, uploads, reports) by key,
+// either on the local private storage path or in the S3 bucket.
+type FileStorageContract interface {
+	Driver() string
+	Put(ctx context.Context, key string, body io.Reader) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+var (
+	ErrUnknownDriver    = errors.New("unknown file storage driver")
+	ErrStorageNotSynced = errors.New("s3 storage adapter is not synced")
+	ErrFileNotFound     = errors.New("file not found")
+)
+
+// NewFileStorageIntegration returns the storage for driver, or the configured
+// STORAGE_DRIVER when driver is empty.
+func NewFileStorageIntegration(driver string) (FileStorageContract, error) {
+	if driver == "" {
+		driver = config.Envs.Storage.Driver
+	}
+
+	switch driver {
+	case DriverLocal:
+		return &localFileStorage{root: config.Envs.App.LocalStoragePrivatePath}, nil
+	case DriverS3:
+		if adapter.Adapters.Storage == nil {
+			return nil, ErrStorageNotSynced
+		}
+		return &s3FileStorage{client: adapter.Adapters.Storage, bucket: config.Envs.Storage.Bucket}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownDriver, driver)
+	}
+}
+
+type localFileStorage struct {
+	root string
+}
+
+func (l *localFileStorage) Driver() string {
+	return DriverLocal
+}
+
+func (l *localFileStorage) Put(ctx context.Context, key string, body io.Reader) error {
+	fullpath := filepath.Join(l.root, filepath.Clean("/"+key))
+
+	if err := os.MkdirAll(filepath.Dir(fullpath), 0700); err != nil {
+		log.Error().Err(err).Str("key", key).Msg("integration::filestorage-Put failed to create directory")
+		return fmt.Errorf("filestorage: %w", err)
+	}
+
+	// write to a temporary file first so readers never see a partial file
+	tmp, err := os.CreateTemp(filepath.Dir(fullpath), ".tmp-*")
+	if err != nil {
+		log.Error().Err(err).Str("key", key).Msg("integration::filestorage-Put failed to create file")
+		return fmt.Errorf("filestorage: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, body); err != nil {
+		tmp.Close()
+		log.Error().Err(err).Str("key", key).Msg("integration::filestorage-Put failed to write file")
+		return fmt.Errorf("filestorage: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		log.Error().Err(err).Str("key", key).Msg("integration::filestorage-Put failed to close file")
+		return fmt.Errorf("filestorage: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), fullpath); err != nil {
+		log.Error().Err(err).Str("key", key).Msg("integration::filestorage-Put failed to move file")
+		return fmt.Errorf("filestorage: %w", err)
+	}
+
+	return nil
+}
+
+func (l *localFileStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	f, err := os.Open(filepath.Join(l.root, filepath.Clean("/"+key)))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrFileNotFound
+		}
+		log.Error().Err(err).Str("key", key).Msg("integration::filestorage-Get failed to open file")
+		return nil, fmt.Errorf("filestorage: %w", err)
+	}
+
+	return f, nil
+}
+
+func (l *localFileStorage) Delete(ctx context.Context, key string) error {
+	err := os.Remove(filepath.Join(l.root, filepath.Clean("/"+key)))
+	if err != nil && !os.IsNotExist(err) {
+		log.Error().Err(err).Str("key", key).Msg("integration::filestorage-Delete failed to delete file")
+		return fmt.Errorf("filestorage: %w", err)
+	}
+
+	return nil
+}
+
+type s3FileStorage struct {
+	client *s3.Client
+	bucket string
+}
+
+func (s *s3FileStorage) Driver() string {
+	return DriverS3
+}
+
+func (s *s3FileStorage) Put(ctx context.Context, key string, body io.Reader) error {
+	_, err := manager.NewUploader(s.client).Upload(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+		Body:   body,
+	})
+	if err != nil {
+		log.Error().Err(err).Str("key", key).Msg("integration::filestorage-Put failed to upload file")
+		return err
+	}
+
+	return nil
+}
+
+func (s *s3FileStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	out, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		log.Error().Err(err).Str("key", key).Msg("integration::filestorage-Get failed to download file")
+		return nil, err
+	}
+
+	return out.Body, nil
+}
+
+func (s *s3FileStorage) Delete(ctx context.Context, key string) error {
+	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		log.Error().Err(err).Str("key", key).Msg("integration::filestorage-Delete failed to delete file")
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,161 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/jmoiron/sqlx/types"
+)
+
+// Import kinds, the last token of the job subject.
+const (
+	KindProducts            = "products"
+	KindProductGrammages    = "product-grammages"
+	KindProductTransactions = "product-transactions"
+	KindMembers             = "members"
+)
+
+const (
+	StatusPending    = "pending"
+	StatusProcessing = "processing"
+	StatusCompleted  = "completed"
+	StatusFailed     = "failed"
+	StatusRolledBack = "rolled_back"
+)
+
+// SubjectPrefix is followed by the kind, e.g. excel.import.members.
+const SubjectPrefix = "excel.import."
+
+var (
+	ErrImportJobNotFound = errors.New("import job not found")
+	ErrImportJobFinished = errors.New("import job already finished")
+	// ErrImportJobClaimed is returned by a claim while another consumer
+	// holds a live lease on the job.
+	ErrImportJobClaimed = errors.New("import job is claimed")
+	// ErrImportJobReferenced is returned by a rollback when other data still
+	// points at rows of the batch.
+	ErrImportJobReferenced = errors.New("import job rows are referenced")
+)
+
+func Subject(kind string) string {
+	return SubjectPrefix + kind
+}
+
+// ClaimLease is how long a claim on a job holds without being renewed. The
+// consumer renews it every ClaimRenewal, a job whose lease ran out belongs to
+// a consumer that died and is claimed again on redelivery.
+const (
+	ClaimLease   = 10 * time.Minute
+	ClaimRenewal = time.Minute
+)
+
+// SampleSize is how many parsed records a job keeps to preview the file.
+const SampleSize = 10
+
+// ImportJobOptions are chosen on upload and applied by the consumer.
+type ImportJobOptions struct {
+	DryRun bool `db:"dry_run" json:"dry_run"`
+	// Sheet is read from xlsx uploads, the first sheet when empty.
+	Sheet string `db:"sheet" json:"sheet"`
+	// OnConflict is fail, skip or update, see pkg/importer.
+	OnConflict string `db:"on_conflict" json:"on_conflict"`
+	// UploadedBy is the user id of the uploader, nil on jobs queued before
+	// the import routes required auth.
+	UploadedBy *string `db:"uploaded_by" json:"uploaded_by"`
+	// Profile is the name of the import profile of the kind to read the file
+	// with, the job keeps its mapping in Mapping.
+	Profile string `db:"-" json:"-"`
+}
+
+type ImportJob struct {
+	ImportJobOptions
+
+	Id            string             `db:"id" json:"id"`
+	Kind          string             `db:"kind" json:"kind"`
+	Filename      string             `db:"filename" json:"filename"`
+	StorageKey    string             `db:"storage_key" json:"-"`
+	StorageDriver string             `db:"storage_driver" json:"-"`
+	Checksum      *string            `db:"checksum" json:"checksum"`
+	DuplicateOf   *string            `db:"duplicate_of" json:"duplicate_of"`
+	ProfileId     *string            `db:"profile_id" json:"profile_id"`
+	Mapping       types.NullJSONText `db:"mapping" json:"-"`
+	Status        string             `db:"status" json:"status"`
+	RowsProcessed int                `db:"rows_processed" json:"rows_processed"`
+	RowsFailed    int                `db:"rows_failed" json:"rows_failed"`
+	RowsInserted  int                `db:"rows_inserted" json:"rows_inserted"`
+	RowsUpdated   int                `db:"rows_updated" json:"rows_updated"`
+	RowsSkipped   int                `db:"rows_skipped" json:"rows_skipped"`
+	Errors        RowFailures        `db:"errors" json:"errors"`
+	Sample        types.JSONText     `db:"sample" json:"sample"`
+	Message       *string            `db:"message" json:"message"`
+	ReportKey     *string            `db:"report_key" json:"-"`
+	Report        *string            `db:"-" json:"report"`
+	CreatedAt     time.Time          `db:"created_at" json:"created_at"`
+	StartedAt     *time.Time         `db:"started_at" json:"started_at"`
+	ClaimedAt     *time.Time         `db:"claimed_at" json:"-"`
+	FinishedAt    *time.Time         `db:"finished_at" json:"finished_at"`
+	RolledBackAt  *time.Time         `db:"rolled_back_at" json:"rolled_back_at"`
+
+	// Warnings are returned on enqueue only, e.g. a file imported before.
+	Warnings []string `db:"-" json:"warnings,omitempty"`
+}
+
+// RowFailure is one problem of a row that was left out of the import. A row
+// fails once per column at fault; Column is empty for problems of the row as
+// a whole.
+type RowFailure struct {
+	Row     int    `json:"row"`
+	Column  string `json:"column"`
+	Value   string `json:"value"`
+	Message string `json:"message"`
+}
+
+type RowFailures []RowFailure
+
+func (f RowFailures) Value() (driver.Value, error) {
+	if f == nil {
+		f = RowFailures{}
+	}
+	return json.Marshal(f)
+}
+
+func (f *RowFailures) Scan(src any) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, f)
+	case string:
+		return json.Unmarshal([]byte(v), f)
+	case nil:
+		*f = RowFailures{}
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into RowFailures", src)
+	}
+}
+
+// ImportJobMessage is the payload published on the job subject.
+type ImportJobMessage struct {
+	Id string `json:"id"`
+}
+
+type GetImportJobReq struct {
+	Id string `params:"id" validate:"required"`
+}
+
+type GetImportReportReq struct {
+	Id string `params:"id" validate:"required"`
+}
+
+type RollbackImportJobReq struct {
+	Id string `params:"id" validate:"required"`
+}
+
+// ImportReport is the annotated upload, the caller closes Body.
+type ImportReport struct {
+	Filename string
+	Body     io.ReadCloser
+}
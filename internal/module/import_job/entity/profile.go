@@ -0,0 +1,100 @@
+package entity
+
+import (
+	"codebase-app/pkg/importer"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"time"
+)
+
+var ErrImportProfileNotFound = errors.New("import profile not found")
+
+// ImportProfile is a named mapping of a partner's files onto the columns of
+// a kind, see importer.Profile.
+type ImportProfile struct {
+	Id        string    `db:"id" json:"id"`
+	Name      string    `db:"name" json:"name"`
+	Kind      string    `db:"kind" json:"kind"`
+	Columns   StringMap `db:"columns" json:"columns"`
+	Formats   StringMap `db:"formats" json:"formats"`
+	Defaults  StringMap `db:"defaults" json:"defaults"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+func (p *ImportProfile) Profile() *importer.Profile {
+	return &importer.Profile{
+		Columns:  p.Columns,
+		Formats:  p.Formats,
+		Defaults: p.Defaults,
+	}
+}
+
+type StringMap map[string]string
+
+func (m StringMap) Value() (driver.Value, error) {
+	if m == nil {
+		m = StringMap{}
+	}
+	return json.Marshal(m)
+}
+
+func (m *StringMap) Scan(src any) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, m)
+	case string:
+		return json.Unmarshal([]byte(v), m)
+	case nil:
+		*m = StringMap{}
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into StringMap", src)
+	}
+}
+
+type GetImportProfilesReq struct {
+	Kind string `query:"kind" validate:"omitempty,oneof=products product-grammages product-transactions members"`
+}
+
+type CreateImportProfileReq struct {
+	Name     string            `json:"name" validate:"required,max=100"`
+	Kind     string            `json:"kind" validate:"required,oneof=products product-grammages product-transactions members"`
+	Columns  map[string]string `json:"columns"`
+	Formats  map[string]string `json:"formats"`
+	Defaults map[string]string `json:"defaults"`
+}
+
+type UpdateImportProfileReq struct {
+	Id       string            `params:"id" validate:"required"`
+	Name     string            `json:"name" validate:"required,max=100"`
+	Columns  map[string]string `json:"columns"`
+	Formats  map[string]string `json:"formats"`
+	Defaults map[string]string `json:"defaults"`
+}
+
+type DeleteImportProfileReq struct {
+	Id string `params:"id" validate:"required"`
+}
+
+// SuggestImportProfileReq reads the header of File to propose the Columns
+// of a profile of the kind.
+type SuggestImportProfileReq struct {
+	File  *multipart.FileHeader `form:"file" validate:"required"`
+	Kind  string                `query:"kind" validate:"required,oneof=products product-grammages product-transactions members"`
+	Sheet string                `query:"sheet"`
+}
+
+type SuggestImportProfileResp struct {
+	// Columns are the mapped columns of the file, ready for a profile.
+	Columns map[string]string `json:"columns"`
+	// Suggestions score every column of the file, unmapped ones included.
+	Suggestions []importer.Suggestion `json:"suggestions"`
+	// Unmatched are required columns of the kind nothing was mapped to.
+	Unmatched []string `json:"unmatched"`
+	// Targets are all the columns of the kind.
+	Targets []importer.Column `json:"targets"`
+}
@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"codebase-app/internal/adapter"
+	"codebase-app/internal/module/import_job/entity"
+	"codebase-app/internal/module/import_job/ports"
+	"codebase-app/internal/module/import_job/repository"
+	"codebase-app/internal/module/import_job/service"
+	memberEntity "codebase-app/internal/module/member/entity"
+	memberRepository "codebase-app/internal/module/member/repository"
+	memberService "codebase-app/internal/module/member/service"
+	productEntity "codebase-app/internal/module/product/entity"
+	productRepository "codebase-app/internal/module/product/repository"
+	productService "codebase-app/internal/module/product/service"
+	tierRepository "codebase-app/internal/module/tier/repository"
+	tierService "codebase-app/internal/module/tier/service"
+	"codebase-app/pkg/errmsg"
+	"codebase-app/pkg/importer"
+	"codebase-app/pkg/response"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+type importJobHandler struct {
+	service   ports.ImportJobService
+	rollbacks map[string]ports.ImportRollback
+	columns   map[string][]importer.Column
+}
+
+func NewImportJobHandler() *importJobHandler {
+	var (
+		repo     = repository.NewImportJobRepository()
+		service  = service.NewImportJobService(repo)
+		tiers    = tierService.NewTierService(tierRepository.NewTierRepository())
+		products = productService.NewProductService(productRepository.NewProductRepository(), tiers, service)
+		members  = memberService.NewMemberService(memberRepository.NewMemberRepository(), service)
+		handler  = new(importJobHandler)
+	)
+	handler.service = service
+	handler.rollbacks = map[string]ports.ImportRollback{
+		entity.KindProducts:            products.RollbackProductsImport,
+		entity.KindProductGrammages:    products.RollbackProductGrammagesImport,
+		entity.KindProductTransactions: products.RollbackProductTransactionsImport,
+		entity.KindMembers:             members.RollbackMembersImport,
+	}
+	handler.columns = map[string][]importer.Column{
+		entity.KindProducts:            importer.Columns[productEntity.Product](),
+		entity.KindProductGrammages:    importer.Columns[productEntity.ProductGrammage](),
+		entity.KindProductTransactions: importer.Columns[productEntity.ProductTransaction](),
+		entity.KindMembers:             importer.Columns[memberEntity.Member](),
+	}
+
+	return handler
+}
+
+func (h *importJobHandler) Register(router fiber.Router) {
+	// before /:id, which would take "profiles" for an id
+	router.Get("/profiles", h.getImportProfiles)
+	router.Post("/profiles", h.createImportProfile)
+	router.Post("/profiles/suggest", h.suggestImportProfile)
+	router.Put("/profiles/:id", h.updateImportProfile)
+	router.Delete("/profiles/:id", h.deleteImportProfile)
+
+	router.Get("/:id", h.getImportJob)
+	router.Get("/:id/report", h.getImportReport)
+	router.Delete("/:id", h.rollbackImportJob)
+}
+
+func (h *importJobHandler) getImportJob(c *fiber.Ctx) error {
+	var (
+		req = new(entity.GetImportJobReq)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+	)
+
+	if err := c.ParamsParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::getImportJob - Failed to parse params")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("req", req).Msg("handler::getImportJob - Invalid request params")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	resp, err := h.service.GetImportJob(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.JSON(response.Success(resp, ""))
+}
+
+func (h *importJobHandler) getImportReport(c *fiber.Ctx) error {
+	var (
+		req = new(entity.GetImportReportReq)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+	)
+
+	if err := c.ParamsParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::getImportReport - Failed to parse params")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("req", req).Msg("handler::getImportReport - Invalid request params")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	resp, err := h.service.GetImportReport(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	// the body is closed by fasthttp once it is sent
+	c.Attachment(resp.Filename)
+	c.Set(fiber.HeaderContentType, "text/csv")
+	return c.SendStream(resp.Body)
+}
+
+func (h *importJobHandler) rollbackImportJob(c *fiber.Ctx) error {
+	var (
+		req = new(entity.RollbackImportJobReq)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+	)
+
+	if err := c.ParamsParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::rollbackImportJob - Failed to parse params")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("req", req).Msg("handler::rollbackImportJob - Invalid request params")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	resp, err := h.service.Rollback(ctx, req, h.rollbacks)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.JSON(response.Success(resp, ""))
+}
@@ -0,0 +1,54 @@
+package ports
+
+import (
+	"codebase-app/internal/module/import_job/entity"
+	"codebase-app/pkg/importer"
+	"context"
+	"mime/multipart"
+)
+
+// ImportRunner loads one stored file of a kind with the settings of the job.
+// The owning module's service provides it.
+type ImportRunner func(ctx context.Context, src importer.Source, settings importer.Settings) (*importer.Result, error)
+
+// ImportRollback deletes the rows a completed job inserted, along with what
+// was derived from them, and returns how many rows it deleted. It returns
+// entity.ErrImportJobReferenced when other data depends on the rows.
+type ImportRollback func(ctx context.Context, id string) (int64, error)
+
+type ImportJobRepository interface {
+	CreateImportJob(ctx context.Context, job *entity.ImportJob) error
+	GetImportJob(ctx context.Context, id string) (*entity.ImportJob, error)
+	GetLastImportJobByChecksum(ctx context.Context, kind, checksum string) (*entity.ImportJob, error)
+	ClaimImportJob(ctx context.Context, id string) (*entity.ImportJob, error)
+	RenewImportJobClaim(ctx context.Context, id string) error
+	UpdateImportJobProgress(ctx context.Context, id string, processed, failed int) error
+	FinishImportJob(ctx context.Context, job *entity.ImportJob) error
+	RollbackImportJob(ctx context.Context, id string) (*entity.ImportJob, error)
+
+	CreateImportProfile(ctx context.Context, profile *entity.ImportProfile) error
+	GetImportProfiles(ctx context.Context, req *entity.GetImportProfilesReq) ([]entity.ImportProfile, error)
+	GetImportProfile(ctx context.Context, id string) (*entity.ImportProfile, error)
+	GetImportProfileByName(ctx context.Context, kind, name string) (*entity.ImportProfile, error)
+	UpdateImportProfile(ctx context.Context, req *entity.UpdateImportProfileReq) (*entity.ImportProfile, error)
+	DeleteImportProfile(ctx context.Context, id string) error
+}
+
+type ImportJobService interface {
+	Enqueue(ctx context.Context, kind string, file *multipart.FileHeader, opts entity.ImportJobOptions) (*entity.ImportJob, error)
+	Process(ctx context.Context, id string, run ImportRunner) error
+	// RenewClaim extends the lease of the consumer processing the job.
+	RenewClaim(ctx context.Context, id string) error
+
+	GetImportJob(ctx context.Context, req *entity.GetImportJobReq) (*entity.ImportJob, error)
+	GetImportReport(ctx context.Context, req *entity.GetImportReportReq) (*entity.ImportReport, error)
+	// Rollback undoes a completed job with the rollback of its kind.
+	Rollback(ctx context.Context, req *entity.RollbackImportJobReq, rollbacks map[string]ImportRollback) (*entity.ImportJob, error)
+
+	// Profiles are checked against the columns of their kind.
+	CreateImportProfile(ctx context.Context, req *entity.CreateImportProfileReq, columns map[string][]importer.Column) (*entity.ImportProfile, error)
+	GetImportProfiles(ctx context.Context, req *entity.GetImportProfilesReq) ([]entity.ImportProfile, error)
+	UpdateImportProfile(ctx context.Context, req *entity.UpdateImportProfileReq, columns map[string][]importer.Column) (*entity.ImportProfile, error)
+	DeleteImportProfile(ctx context.Context, req *entity.DeleteImportProfileReq) error
+	SuggestImportProfile(ctx context.Context, req *entity.SuggestImportProfileReq, columns map[string][]importer.Column) (*entity.SuggestImportProfileResp, error)
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"codebase-app/internal/module/import_job/entity"
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/rs/zerolog/log"
+)
+
+const importProfileColumns = `
+	id,
+	name,
+	kind,
+	columns,
+	formats,
+	defaults,
+	created_at,
+	updated_at
+`
+
+func (r *importJobRepo) CreateImportProfile(ctx context.Context, profile *entity.ImportProfile) error {
+	query := `
+		INSERT INTO import_profiles (id, name, kind, columns, formats, defaults)
+		VALUES (?, ?, ?, ?, ?, ?)
+		RETURNING created_at, updated_at
+	`
+
+	err := r.db.QueryRowxContext(ctx, r.db.Rebind(query),
+		profile.Id,
+		profile.Name,
+		profile.Kind,
+		profile.Columns,
+		profile.Formats,
+		profile.Defaults,
+	).Scan(&profile.CreatedAt, &profile.UpdatedAt)
+	if err != nil {
+		log.Error().Err(err).Any("profile", profile).Msg("repo::CreateImportProfile - failed to create import profile")
+		return err
+	}
+
+	return nil
+}
+
+func (r *importJobRepo) GetImportProfiles(ctx context.Context, req *entity.GetImportProfilesReq) ([]entity.ImportProfile, error) {
+	var profiles = make([]entity.ImportProfile, 0)
+
+	query := `
+		SELECT ` + importProfileColumns + `
+		FROM import_profiles
+		WHERE ? = '' OR kind = ?
+		ORDER BY kind, name
+	`
+
+	if err := r.db.SelectContext(ctx, &profiles, r.db.Rebind(query), req.Kind, req.Kind); err != nil {
+		log.Error().Err(err).Any("req", req).Msg("repo::GetImportProfiles - failed to get import profiles")
+		return nil, err
+	}
+
+	return profiles, nil
+}
+
+func (r *importJobRepo) GetImportProfileByName(ctx context.Context, kind, name string) (*entity.ImportProfile, error) {
+	var profile = new(entity.ImportProfile)
+
+	query := `SELECT ` + importProfileColumns + ` FROM import_profiles WHERE kind = ? AND name = ?`
+
+	if err := r.db.GetContext(ctx, profile, r.db.Rebind(query), kind, name); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, entity.ErrImportProfileNotFound
+		}
+		log.Error().Err(err).Str("kind", kind).Str("name", name).Msg("repo::GetImportProfileByName - failed to get import profile")
+		return nil, err
+	}
+
+	return profile, nil
+}
+
+func (r *importJobRepo) UpdateImportProfile(ctx context.Context, req *entity.UpdateImportProfileReq) (*entity.ImportProfile, error) {
+	var profile = new(entity.ImportProfile)
+
+	query := `
+		UPDATE import_profiles
+		SET
+			name = ?,
+			columns = ?,
+			formats = ?,
+			defaults = ?,
+			updated_at = NOW()
+		WHERE id = ?
+		RETURNING ` + importProfileColumns
+
+	err := r.db.GetContext(ctx, profile, r.db.Rebind(query),
+		req.Name,
+		entity.StringMap(req.Columns),
+		entity.StringMap(req.Formats),
+		entity.StringMap(req.Defaults),
+		req.Id,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, entity.ErrImportProfileNotFound
+		}
+		log.Error().Err(err).Any("req", req).Msg("repo::UpdateImportProfile - failed to update import profile")
+		return nil, err
+	}
+
+	return profile, nil
+}
+
+func (r *importJobRepo) GetImportProfile(ctx context.Context, id string) (*entity.ImportProfile, error) {
+	var profile = new(entity.ImportProfile)
+
+	query := `SELECT ` + importProfileColumns + ` FROM import_profiles WHERE id = ?`
+
+	if err := r.db.GetContext(ctx, profile, r.db.Rebind(query), id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, entity.ErrImportProfileNotFound
+		}
+		log.Error().Err(err).Str("id", id).Msg("repo::GetImportProfile - failed to get import profile")
+		return nil, err
+	}
+
+	return profile, nil
+}
+
+func (r *importJobRepo) DeleteImportProfile(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, r.db.Rebind(`DELETE FROM import_profiles WHERE id = ?`), id)
+	if err != nil {
+		log.Error().Err(err).Str("id", id).Msg("repo::DeleteImportProfile - failed to delete import profile")
+		return err
+	}
+
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return entity.ErrImportProfileNotFound
+	}
+
+	return nil
+}
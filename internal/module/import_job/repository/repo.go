@@ -0,0 +1,289 @@
+package repository
+
+import (
+	"codebase-app/internal/adapter"
+	"codebase-app/internal/module/import_job/entity"
+	"codebase-app/internal/module/import_job/ports"
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+var _ ports.ImportJobRepository = &importJobRepo{}
+
+type importJobRepo struct {
+	db *sqlx.DB
+}
+
+func NewImportJobRepository() *importJobRepo {
+	return &importJobRepo{
+		db: adapter.Adapters.Postgres,
+	}
+}
+
+const importJobColumns = `
+	id,
+	kind,
+	filename,
+	storage_key,
+	storage_driver,
+	status,
+	rows_processed,
+	rows_failed,
+	rows_inserted,
+	rows_updated,
+	rows_skipped,
+	errors,
+	sample,
+	dry_run,
+	sheet,
+	on_conflict,
+	uploaded_by,
+	checksum,
+	duplicate_of,
+	profile_id,
+	mapping,
+	message,
+	report_key,
+	created_at,
+	started_at,
+	claimed_at,
+	finished_at,
+	rolled_back_at
+`
+
+func (r *importJobRepo) CreateImportJob(ctx context.Context, job *entity.ImportJob) error {
+	query := `
+		INSERT INTO import_jobs (
+			id,
+			kind,
+			filename,
+			storage_key,
+			storage_driver,
+			status,
+			dry_run,
+			sheet,
+			on_conflict,
+			uploaded_by,
+			checksum,
+			duplicate_of,
+			profile_id,
+			mapping
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING created_at
+	`
+
+	err := r.db.QueryRowxContext(ctx, r.db.Rebind(query),
+		job.Id,
+		job.Kind,
+		job.Filename,
+		job.StorageKey,
+		job.StorageDriver,
+		job.Status,
+		job.DryRun,
+		job.Sheet,
+		job.OnConflict,
+		job.UploadedBy,
+		job.Checksum,
+		job.DuplicateOf,
+		job.ProfileId,
+		job.Mapping,
+	).Scan(&job.CreatedAt)
+	if err != nil {
+		log.Error().Err(err).Any("job", job).Msg("repo::CreateImportJob - failed to create import job")
+		return err
+	}
+
+	return nil
+}
+
+func (r *importJobRepo) GetImportJob(ctx context.Context, id string) (*entity.ImportJob, error) {
+	var job = new(entity.ImportJob)
+
+	query := `SELECT ` + importJobColumns + ` FROM import_jobs WHERE id = ?`
+
+	if err := r.db.GetContext(ctx, job, r.db.Rebind(query), id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, entity.ErrImportJobNotFound
+		}
+		log.Error().Err(err).Str("id", id).Msg("repo::GetImportJob - failed to get import job")
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// GetLastImportJobByChecksum returns the latest job of the kind that loaded
+// a file with the same content and was not undone since. Dry runs and failed
+// jobs loaded nothing and are not considered.
+func (r *importJobRepo) GetLastImportJobByChecksum(ctx context.Context, kind, checksum string) (*entity.ImportJob, error) {
+	var job = new(entity.ImportJob)
+
+	query := `
+		SELECT ` + importJobColumns + `
+		FROM import_jobs
+		WHERE
+			kind = ?
+			AND checksum = ?
+			AND status IN ('pending', 'processing', 'completed')
+			AND NOT dry_run
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+
+	if err := r.db.GetContext(ctx, job, r.db.Rebind(query), kind, checksum); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, entity.ErrImportJobNotFound
+		}
+		log.Error().Err(err).Str("kind", kind).Str("checksum", checksum).Msg("repo::GetLastImportJobByChecksum - failed to get import job")
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// ClaimImportJob marks the job as processing and takes a lease on it. A job
+// left processing by a consumer that died is claimed again once its lease
+// went stale; ErrImportJobClaimed is returned while the lease is live and
+// ErrImportJobFinished once the job is done.
+func (r *importJobRepo) ClaimImportJob(ctx context.Context, id string) (*entity.ImportJob, error) {
+	var job = new(entity.ImportJob)
+
+	query := `
+		UPDATE import_jobs
+		SET
+			status = 'processing',
+			rows_processed = 0,
+			rows_failed = 0,
+			rows_inserted = 0,
+			rows_updated = 0,
+			rows_skipped = 0,
+			errors = '[]',
+			sample = '[]',
+			report_key = NULL,
+			started_at = NOW(),
+			claimed_at = NOW()
+		WHERE id = ? AND (
+			status = 'pending'
+			OR (status = 'processing' AND (claimed_at IS NULL OR claimed_at < NOW() - ? * INTERVAL '1 second'))
+		)
+		RETURNING ` + importJobColumns
+
+	if err := r.db.GetContext(ctx, job, r.db.Rebind(query), id, entity.ClaimLease.Seconds()); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			job, err := r.GetImportJob(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			if job.Status == entity.StatusProcessing {
+				return nil, entity.ErrImportJobClaimed
+			}
+			return nil, entity.ErrImportJobFinished
+		}
+		log.Error().Err(err).Str("id", id).Msg("repo::ClaimImportJob - failed to claim import job")
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// RenewImportJobClaim extends the lease on a job being processed.
+func (r *importJobRepo) RenewImportJobClaim(ctx context.Context, id string) error {
+	query := `
+		UPDATE import_jobs
+		SET claimed_at = NOW()
+		WHERE id = ? AND status = 'processing'
+	`
+
+	if _, err := r.db.ExecContext(ctx, r.db.Rebind(query), id); err != nil {
+		log.Error().Err(err).Str("id", id).Msg("repo::RenewImportJobClaim - failed to renew import job claim")
+		return err
+	}
+
+	return nil
+}
+
+func (r *importJobRepo) UpdateImportJobProgress(ctx context.Context, id string, processed, failed int) error {
+	query := `
+		UPDATE import_jobs
+		SET rows_processed = ?, rows_failed = ?
+		WHERE id = ?
+	`
+
+	if _, err := r.db.ExecContext(ctx, r.db.Rebind(query), processed, failed, id); err != nil {
+		log.Error().Err(err).Str("id", id).Msg("repo::UpdateImportJobProgress - failed to update import job")
+		return err
+	}
+
+	return nil
+}
+
+func (r *importJobRepo) FinishImportJob(ctx context.Context, job *entity.ImportJob) error {
+	query := `
+		UPDATE import_jobs
+		SET
+			status = ?,
+			rows_processed = ?,
+			rows_failed = ?,
+			rows_inserted = ?,
+			rows_updated = ?,
+			rows_skipped = ?,
+			errors = ?,
+			sample = ?,
+			message = ?,
+			report_key = ?,
+			finished_at = NOW()
+		WHERE id = ?
+		RETURNING finished_at
+	`
+
+	err := r.db.QueryRowxContext(ctx, r.db.Rebind(query),
+		job.Status,
+		job.RowsProcessed,
+		job.RowsFailed,
+		job.RowsInserted,
+		job.RowsUpdated,
+		job.RowsSkipped,
+		job.Errors,
+		job.Sample,
+		job.Message,
+		job.ReportKey,
+		job.Id,
+	).Scan(&job.FinishedAt)
+	if err != nil {
+		log.Error().Err(err).Str("id", job.Id).Msg("repo::FinishImportJob - failed to finish import job")
+		return err
+	}
+
+	return nil
+}
+
+// RollbackImportJob marks a completed job as rolled back, once its rows are
+// deleted. It returns ErrImportJobFinished when the job is in any other
+// status.
+func (r *importJobRepo) RollbackImportJob(ctx context.Context, id string) (*entity.ImportJob, error) {
+	var job = new(entity.ImportJob)
+
+	query := `
+		UPDATE import_jobs
+		SET status = 'rolled_back', rolled_back_at = NOW()
+		WHERE id = ? AND status = 'completed'
+		RETURNING ` + importJobColumns
+
+	if err := r.db.GetContext(ctx, job, r.db.Rebind(query), id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			if _, err := r.GetImportJob(ctx, id); err != nil {
+				return nil, err
+			}
+			return nil, entity.ErrImportJobFinished
+		}
+		log.Error().Err(err).Str("id", id).Msg("repo::RollbackImportJob - failed to roll back import job")
+		return nil, err
+	}
+
+	return job, nil
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"codebase-app/internal/module/import_job/entity"
+	"codebase-app/pkg/errmsg"
+	"codebase-app/pkg/importer"
+	"context"
+	"errors"
+	"io"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/rs/zerolog/log"
+)
+
+func (s *importJobService) CreateImportProfile(ctx context.Context, req *entity.CreateImportProfileReq, columns map[string][]importer.Column) (*entity.ImportProfile, error) {
+	profile := &entity.ImportProfile{
+		Id:       ulid.Make().String(),
+		Name:     req.Name,
+		Kind:     req.Kind,
+		Columns:  req.Columns,
+		Formats:  req.Formats,
+		Defaults: req.Defaults,
+	}
+
+	if err := checkProfile(profile, columns); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateImportProfile(ctx, profile); err != nil {
+		return nil, err
+	}
+
+	return profile, nil
+}
+
+func (s *importJobService) GetImportProfiles(ctx context.Context, req *entity.GetImportProfilesReq) ([]entity.ImportProfile, error) {
+	return s.repo.GetImportProfiles(ctx, req)
+}
+
+func (s *importJobService) UpdateImportProfile(ctx context.Context, req *entity.UpdateImportProfileReq, columns map[string][]importer.Column) (*entity.ImportProfile, error) {
+	profile, err := s.repo.GetImportProfile(ctx, req.Id)
+	if err != nil {
+		if errors.Is(err, entity.ErrImportProfileNotFound) {
+			return nil, errmsg.NewCustomErrors(404).SetMessage("Import profile not found")
+		}
+		return nil, err
+	}
+
+	profile.Columns = req.Columns
+	profile.Formats = req.Formats
+	profile.Defaults = req.Defaults
+
+	if err := checkProfile(profile, columns); err != nil {
+		return nil, err
+	}
+
+	profile, err = s.repo.UpdateImportProfile(ctx, req)
+	if err != nil {
+		if errors.Is(err, entity.ErrImportProfileNotFound) {
+			return nil, errmsg.NewCustomErrors(404).SetMessage("Import profile not found")
+		}
+		return nil, err
+	}
+
+	return profile, nil
+}
+
+func (s *importJobService) DeleteImportProfile(ctx context.Context, req *entity.DeleteImportProfileReq) error {
+	if err := s.repo.DeleteImportProfile(ctx, req.Id); err != nil {
+		if errors.Is(err, entity.ErrImportProfileNotFound) {
+			return errmsg.NewCustomErrors(404).SetMessage("Import profile not found")
+		}
+		return err
+	}
+
+	return nil
+}
+
+// SuggestImportProfile matches the header of the upload to the columns of
+// the kind. The file is only read up to its header.
+func (s *importJobService) SuggestImportProfile(ctx context.Context, req *entity.SuggestImportProfileReq, columns map[string][]importer.Column) (*entity.SuggestImportProfileResp, error) {
+	targets, ok := columns[req.Kind]
+	if !ok {
+		return nil, errmsg.NewCustomErrors(422).SetMessage("Unknown import kind")
+	}
+
+	body, err := req.File.Open()
+	if err != nil {
+		log.Warn().Err(err).Str("kind", req.Kind).Msg("service::SuggestImportProfile - Failed to open file")
+		return nil, err
+	}
+	defer body.Close()
+
+	src, err := openSource(req.File.Filename, req.Sheet, body)
+	if err != nil {
+		if importer.IsInputError(err) {
+			return nil, errmsg.NewCustomErrors(422).SetMessage(err.Error())
+		}
+		return nil, err
+	}
+	if closer, ok := src.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	header, err := src.Header()
+	if err != nil {
+		if importer.IsInputError(err) {
+			return nil, errmsg.NewCustomErrors(422).SetMessage(err.Error())
+		}
+		return nil, err
+	}
+
+	var (
+		resp = &entity.SuggestImportProfileResp{
+			Columns:     make(map[string]string),
+			Suggestions: importer.Suggest(header, targets),
+			Unmatched:   make([]string, 0),
+			Targets:     targets,
+		}
+		matched = make(map[string]bool, len(targets))
+	)
+
+	for _, suggestion := range resp.Suggestions {
+		if suggestion.Column == "" {
+			continue
+		}
+		matched[suggestion.Column] = true
+		// a column already named like its target needs no mapping
+		if suggestion.Source != suggestion.Column {
+			resp.Columns[suggestion.Source] = suggestion.Column
+		}
+	}
+
+	for _, target := range targets {
+		if target.Required && !matched[target.Name] {
+			resp.Unmatched = append(resp.Unmatched, target.Name)
+		}
+	}
+
+	return resp, nil
+}
+
+func checkProfile(profile *entity.ImportProfile, columns map[string][]importer.Column) error {
+	targets, ok := columns[profile.Kind]
+	if !ok {
+		return errmsg.NewCustomErrors(422).SetMessage("Unknown import kind")
+	}
+
+	if err := profile.Profile().Check(targets); err != nil {
+		log.Warn().Err(err).Any("profile", profile).Msg("service::checkProfile - Invalid import profile")
+		return errmsg.NewCustomErrors(422).SetMessage(err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,428 @@
+package service
+
+import (
+	"codebase-app/internal/adapter"
+	"codebase-app/internal/infrastructure/config"
+	integration "codebase-app/internal/integration/filestorage"
+	"codebase-app/internal/module/import_job/entity"
+	"codebase-app/internal/module/import_job/ports"
+	"codebase-app/pkg/errmsg"
+	"codebase-app/pkg/importer"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx/types"
+	"github.com/oklog/ulid/v2"
+	"github.com/rs/zerolog/log"
+)
+
+var _ ports.ImportJobService = &importJobService{}
+
+type importJobService struct {
+	repo ports.ImportJobRepository
+}
+
+func NewImportJobService(repo ports.ImportJobRepository) *importJobService {
+	return &importJobService{
+		repo: repo,
+	}
+}
+
+// Enqueue stores the upload and publishes the job for the consumer. The job
+// is returned pending, its progress is read back with GetImportJob. A file
+// whose content was already imported is queued all the same, the job points
+// at the earlier one in DuplicateOf and carries a warning.
+func (s *importJobService) Enqueue(ctx context.Context, kind string, file *multipart.FileHeader, opts entity.ImportJobOptions) (*entity.ImportJob, error) {
+	publisher := adapter.Adapters.ExcelProductPublisher
+	if publisher == nil {
+		log.Error().Str("kind", kind).Msg("service::Enqueue - Publisher not connected")
+		return nil, errmsg.NewCustomErrors(503).SetMessage("Import queue is not available")
+	}
+
+	storage, err := integration.NewFileStorageIntegration("")
+	if err != nil {
+		log.Error().Err(err).Str("kind", kind).Msg("service::Enqueue - Failed to get import storage")
+		return nil, err
+	}
+
+	body, err := file.Open()
+	if err != nil {
+		log.Warn().Err(err).Str("kind", kind).Msg("service::Enqueue - Failed to open file")
+		return nil, err
+	}
+	defer body.Close()
+
+	checksum, err := fileChecksum(body)
+	if err != nil {
+		log.Warn().Err(err).Str("kind", kind).Msg("service::Enqueue - Failed to read file")
+		return nil, err
+	}
+
+	job := &entity.ImportJob{
+		ImportJobOptions: opts,
+		Id:               ulid.Make().String(),
+		Kind:             kind,
+		Filename:         file.Filename,
+		StorageDriver:    storage.Driver(),
+		Checksum:         &checksum,
+		Status:           entity.StatusPending,
+		Errors:           entity.RowFailures{},
+		Sample:           types.JSONText("[]"),
+	}
+	job.StorageKey = "imports/" + job.Id + strings.ToLower(filepath.Ext(file.Filename))
+
+	if opts.Profile != "" {
+		profile, err := s.repo.GetImportProfileByName(ctx, kind, opts.Profile)
+		if err != nil {
+			if errors.Is(err, entity.ErrImportProfileNotFound) {
+				return nil, errmsg.NewCustomErrors(422).SetMessage("Import profile not found")
+			}
+			return nil, err
+		}
+
+		mapping, err := json.Marshal(profile.Profile())
+		if err != nil {
+			return nil, err
+		}
+
+		job.ProfileId = &profile.Id
+		job.Mapping = types.NullJSONText{JSONText: mapping, Valid: true}
+	}
+
+	previous, err := s.repo.GetLastImportJobByChecksum(ctx, kind, checksum)
+	switch {
+	case err == nil:
+		job.DuplicateOf = &previous.Id
+		job.Warnings = append(job.Warnings, "File was already imported by job "+previous.Id)
+		log.Warn().Str("kind", kind).Str("checksum", checksum).Str("duplicate_of", previous.Id).Msg("service::Enqueue - File was already imported")
+	case !errors.Is(err, entity.ErrImportJobNotFound):
+		return nil, err
+	}
+
+	if err := storage.Put(ctx, job.StorageKey, body); err != nil {
+		log.Error().Err(err).Any("job", job).Msg("service::Enqueue - Failed to store upload")
+		return nil, err
+	}
+
+	if err := s.repo.CreateImportJob(ctx, job); err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(entity.ImportJobMessage{Id: job.Id})
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := publisher.Publish(ctx, entity.Subject(kind), data); err != nil {
+		log.Error().Err(err).Any("job", job).Msg("service::Enqueue - Failed to publish import job")
+
+		// nothing will pick the job up, do not leave it pending forever
+		message := "Failed to queue the import"
+		job.Status = entity.StatusFailed
+		job.Message = &message
+		if err := s.repo.FinishImportJob(ctx, job); err != nil {
+			log.Error().Err(err).Any("job", job).Msg("service::Enqueue - Failed to fail import job")
+		}
+
+		return nil, errmsg.NewCustomErrors(503).SetMessage(message)
+	}
+
+	log.Info().Any("job", job).Msg("service::Enqueue - Import job queued")
+
+	return job, nil
+}
+
+// Process runs a queued job with the runner of its kind and records the
+// outcome. Rows rejected by the runner are reported on the job, together with
+// an annotated copy of the file; an error of the file as a whole or of the
+// database fails the job and loads nothing. A dry run goes through the same
+// load and merge and is rolled back at the end, its counts tell what the
+// import would do.
+func (s *importJobService) Process(ctx context.Context, id string, run ports.ImportRunner) error {
+	job, err := s.repo.ClaimImportJob(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	report, err := os.CreateTemp("", "import-report-*.csv")
+	if err != nil {
+		log.Error().Err(err).Str("id", job.Id).Msg("service::Process - Failed to create report file")
+		return err
+	}
+	defer func() {
+		report.Close()
+		if err := os.Remove(report.Name()); err != nil {
+			log.Warn().Err(err).Str("file", report.Name()).Msg("service::Process - Failed to remove report file")
+		}
+	}()
+
+	last := &importer.Result{Errors: make([]*importer.RowError, 0)}
+	settings := importer.Settings{
+		Report:     report,
+		SampleSize: entity.SampleSize,
+		DryRun:     job.DryRun,
+		OnConflict: job.OnConflict,
+		Batch:      job.Id,
+		Progress: func(res *importer.Result) {
+			last = res
+			if err := s.repo.UpdateImportJobProgress(ctx, job.Id, res.Rows, res.Failed); err != nil {
+				log.Warn().Err(err).Str("id", job.Id).Msg("service::Process - Failed to record progress")
+			}
+		},
+	}
+
+	res, err := s.run(ctx, job, run, settings)
+	if err != nil {
+		log.Warn().Err(err).Any("job", job).Msg("service::Process - Import job failed")
+
+		// the load is one transaction, none of the processed rows were kept
+		message := err.Error()
+		job.Status = entity.StatusFailed
+		job.Message = &message
+		job.RowsProcessed = 0
+		job.RowsFailed = last.Failed
+		job.Errors = rowFailures(last.Errors)
+	} else {
+		job.Status = entity.StatusCompleted
+		job.RowsProcessed = res.Rows
+		job.RowsFailed = res.Failed
+		job.RowsInserted = res.Inserted
+		job.RowsUpdated = res.Updated
+		job.RowsSkipped = res.Skipped
+		job.Errors = rowFailures(res.Errors)
+
+		if sample, err := json.Marshal(res.Sample); err == nil {
+			job.Sample = sample
+		}
+
+		if res.Failed > 0 {
+			job.ReportKey = s.storeReport(ctx, job, report)
+		}
+	}
+
+	if err := s.repo.FinishImportJob(ctx, job); err != nil {
+		return err
+	}
+
+	log.Info().
+		Str("id", job.Id).
+		Str("status", job.Status).
+		Bool("dry_run", job.DryRun).
+		Int("rows_processed", job.RowsProcessed).
+		Int("rows_failed", job.RowsFailed).
+		Msg("service::Process - Import job finished")
+
+	return nil
+}
+
+func (s *importJobService) RenewClaim(ctx context.Context, id string) error {
+	return s.repo.RenewImportJobClaim(ctx, id)
+}
+
+func (s *importJobService) run(ctx context.Context, job *entity.ImportJob, run ports.ImportRunner, settings importer.Settings) (*importer.Result, error) {
+	location, err := time.LoadLocation(config.Envs.Import.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	settings.Location = location
+
+	if job.Mapping.Valid {
+		settings.Profile = new(importer.Profile)
+		if err := json.Unmarshal(job.Mapping.JSONText, settings.Profile); err != nil {
+			return nil, err
+		}
+	}
+
+	storage, err := integration.NewFileStorageIntegration(job.StorageDriver)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := storage.Get(ctx, job.StorageKey)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	src, err := openSource(job.StorageKey, job.Sheet, body)
+	if err != nil {
+		return nil, err
+	}
+	if closer, ok := src.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	return run(ctx, src, settings)
+}
+
+// openSource picks the reader by the extension of the upload, anything that
+// is not an xlsx workbook is read as CSV.
+func openSource(filename, sheet string, body io.Reader) (importer.Source, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".xlsx":
+		return importer.NewXLSXSource(body, sheet)
+	default:
+		return importer.NewCSVSource(body), nil
+	}
+}
+
+// storeReport puts the annotated file next to the upload. It is best effort,
+// the errors on the job are kept either way.
+func (s *importJobService) storeReport(ctx context.Context, job *entity.ImportJob, report *os.File) *string {
+	storage, err := integration.NewFileStorageIntegration(job.StorageDriver)
+	if err != nil {
+		log.Error().Err(err).Str("id", job.Id).Msg("service::storeReport - Failed to get import storage")
+		return nil
+	}
+
+	if _, err := report.Seek(0, io.SeekStart); err != nil {
+		log.Error().Err(err).Str("id", job.Id).Msg("service::storeReport - Failed to rewind report")
+		return nil
+	}
+
+	key := "imports/" + job.Id + ".report.csv"
+	if err := storage.Put(ctx, key, report); err != nil {
+		log.Error().Err(err).Str("id", job.Id).Msg("service::storeReport - Failed to store report")
+		return nil
+	}
+
+	return &key
+}
+
+func (s *importJobService) GetImportJob(ctx context.Context, req *entity.GetImportJobReq) (*entity.ImportJob, error) {
+	job, err := s.repo.GetImportJob(ctx, req.Id)
+	if err != nil {
+		if errors.Is(err, entity.ErrImportJobNotFound) {
+			return nil, errmsg.NewCustomErrors(404).SetMessage("Import job not found")
+		}
+		return nil, err
+	}
+
+	if job.ReportKey != nil {
+		report := "/imports/" + job.Id + "/report"
+		job.Report = &report
+	}
+
+	return job, nil
+}
+
+func (s *importJobService) GetImportReport(ctx context.Context, req *entity.GetImportReportReq) (*entity.ImportReport, error) {
+	job, err := s.repo.GetImportJob(ctx, req.Id)
+	if err != nil {
+		if errors.Is(err, entity.ErrImportJobNotFound) {
+			return nil, errmsg.NewCustomErrors(404).SetMessage("Import job not found")
+		}
+		return nil, err
+	}
+
+	if job.ReportKey == nil {
+		return nil, errmsg.NewCustomErrors(404).SetMessage("Import job has no report")
+	}
+
+	storage, err := integration.NewFileStorageIntegration(job.StorageDriver)
+	if err != nil {
+		log.Error().Err(err).Str("id", job.Id).Msg("service::GetImportReport - Failed to get import storage")
+		return nil, err
+	}
+
+	body, err := storage.Get(ctx, *job.ReportKey)
+	if err != nil {
+		if errors.Is(err, integration.ErrFileNotFound) {
+			return nil, errmsg.NewCustomErrors(404).SetMessage("Import report not found")
+		}
+		log.Error().Err(err).Str("id", job.Id).Msg("service::GetImportReport - Failed to get report")
+		return nil, err
+	}
+
+	name := strings.TrimSuffix(job.Filename, filepath.Ext(job.Filename)) + "_errors.csv"
+
+	return &entity.ImportReport{Filename: name, Body: body}, nil
+}
+
+// Rollback deletes what a completed job inserted, with the rollback of its
+// kind, and marks it rolled back. Rows the job only updated are left as they
+// are.
+func (s *importJobService) Rollback(ctx context.Context, req *entity.RollbackImportJobReq, rollbacks map[string]ports.ImportRollback) (*entity.ImportJob, error) {
+	job, err := s.repo.GetImportJob(ctx, req.Id)
+	if err != nil {
+		if errors.Is(err, entity.ErrImportJobNotFound) {
+			return nil, errmsg.NewCustomErrors(404).SetMessage("Import job not found")
+		}
+		return nil, err
+	}
+
+	switch {
+	case job.Status == entity.StatusRolledBack:
+		return nil, errmsg.NewCustomErrors(409).SetMessage("Import job already rolled back")
+	case job.Status != entity.StatusCompleted:
+		return nil, errmsg.NewCustomErrors(409).SetMessage("Only completed import jobs can be rolled back")
+	case job.DryRun:
+		return nil, errmsg.NewCustomErrors(409).SetMessage("A dry run has nothing to roll back")
+	}
+
+	rollback, ok := rollbacks[job.Kind]
+	if !ok {
+		log.Error().Str("id", job.Id).Str("kind", job.Kind).Msg("service::Rollback - Unknown import kind")
+		return nil, errmsg.NewCustomErrors(422).SetMessage("Import job cannot be rolled back")
+	}
+
+	deleted, err := rollback(ctx, job.Id)
+	if err != nil {
+		if errors.Is(err, entity.ErrImportJobReferenced) {
+			log.Warn().Str("id", job.Id).Str("kind", job.Kind).Msg("service::Rollback - Import job rows are referenced")
+			return nil, errmsg.NewCustomErrors(409).SetMessage("Rows of this import are used by other data, roll those back first")
+		}
+		return nil, err
+	}
+
+	job, err = s.repo.RollbackImportJob(ctx, job.Id)
+	if err != nil {
+		if errors.Is(err, entity.ErrImportJobFinished) {
+			// a concurrent rollback got there first, the rows are gone either way
+			return nil, errmsg.NewCustomErrors(409).SetMessage("Import job already rolled back")
+		}
+		return nil, err
+	}
+
+	log.Info().Str("id", job.Id).Str("kind", job.Kind).Int64("deleted", deleted).Msg("service::Rollback - Import job rolled back")
+
+	return job, nil
+}
+
+// fileChecksum is the hex SHA-256 of the upload, which is rewound after.
+func fileChecksum(file multipart.File) (string, error) {
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func rowFailures(errs []*importer.RowError) entity.RowFailures {
+	failures := make(entity.RowFailures, 0, len(errs))
+	for _, err := range errs {
+		for _, field := range err.Fields {
+			failures = append(failures, entity.RowFailure{
+				Row:     err.Row,
+				Column:  field.Column,
+				Value:   field.Value,
+				Message: field.Message,
+			})
+		}
+	}
+	return failures
+}
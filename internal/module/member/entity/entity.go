@@ -1,19 +1,53 @@
 package entity
 
 import (
+	"codebase-app/pkg/queryspec"
 	"codebase-app/pkg/types"
 	"mime/multipart"
 )
 
 type ImportMembersReq struct {
 	File *multipart.FileHeader `form:"file" validate:"required"`
+	// DryRun checks the file and rolls the import back instead of committing it
+	DryRun bool `query:"dry_run"`
+	// Sheet of an xlsx upload, the first sheet when empty
+	Sheet string `query:"sheet"`
+	// OnConflict is what to do with rows whose id already exists
+	OnConflict string `query:"on_conflict" validate:"omitempty,oneof=fail skip update"`
+	// Profile is the name of the import profile that maps the columns
+	Profile string `query:"profile" validate:"omitempty,max=100"`
+	// UploadedBy is the authenticated user, set by the handler
+	UploadedBy *string `query:"-" json:"-"`
+}
 
-	Data []Member
+func (r *ImportMembersReq) SetDefault() {
+	if r.OnConflict == "" {
+		r.OnConflict = "fail"
+	}
 }
 
 type GetMembersReq struct {
 	Page     int `query:"page" validate:"required"`
 	Paginate int `query:"paginate" validate:"required"`
+	// Pagination is cursor to page by the key of the list instead of page
+	Pagination string `query:"pagination" validate:"omitempty,oneof=offset cursor"`
+	// Cursor is the next_cursor or prev_cursor of a previous page
+	Cursor string `query:"cursor" validate:"omitempty,max=1024"`
+	// Count adds an estimated total to cursor pages
+	Count bool `query:"count"`
+	// Spec is the filter, sort and search of MemberFields, set by the handler
+	Spec *queryspec.Spec `query:"-" json:"-"`
+}
+
+// MemberFields are the fields members can be listed by.
+var MemberFields = queryspec.Fields{
+	"id":            {Column: "m.id", Type: queryspec.Text, Sort: true, Search: true},
+	"city":          {Column: "m.city", Type: queryspec.Text, Sort: true, Search: true},
+	"email":         {Column: "m.email", Type: queryspec.Text, Search: true},
+	"join_date":     {Column: "m.join_date", Type: queryspec.Date, Sort: true},
+	"date_of_birth": {Column: "m.date_of_birth", Type: queryspec.Date, Sort: true},
+	"no_of_child":   {Column: "m.no_of_child", Type: queryspec.Int, Sort: true},
+	"tier":          {Column: "mt.tier_code", Type: queryspec.Text, Sort: true},
 }
 
 func (r *GetMembersReq) SetDefault() {
@@ -26,11 +60,66 @@ func (r *GetMembersReq) SetDefault() {
 	}
 }
 
+// CursorMode tells whether the list is paged by cursor.
+func (r *GetMembersReq) CursorMode() bool {
+	return r.Pagination == "cursor" || r.Cursor != ""
+}
+
+// MemberKeyset is the key members are paged by in cursor mode.
+var MemberKeyset = queryspec.Keyset{Columns: []string{"m.id"}}
+
 type GetMembersResp struct {
 	Items []Member   `json:"data"`
 	Meta  types.Meta `json:"meta"`
 }
 
+type SearchMembersReq struct {
+	// Q is matched loosely against the id and city
+	Q           string `query:"q" validate:"required_without_all=DateOfBirth JoinFrom JoinTo,max=100"`
+	DateOfBirth string `query:"date_of_birth" validate:"omitempty,datetime=2006-01-02"`
+	// JoinFrom and JoinTo bound the join date, both inclusive
+	JoinFrom string `query:"join_from" validate:"omitempty,datetime=2006-01-02"`
+	JoinTo   string `query:"join_to" validate:"omitempty,datetime=2006-01-02"`
+	Page     int    `query:"page" validate:"required"`
+	Paginate int    `query:"paginate" validate:"required,max=100"`
+}
+
+func (r *SearchMembersReq) SetDefault() {
+	if r.Page < 1 {
+		r.Page = 1
+	}
+
+	if r.Paginate < 1 {
+		r.Paginate = 10
+	}
+}
+
+type SearchMembersResp struct {
+	Items []MemberSearchResult `json:"data"`
+	Meta  types.Meta           `json:"meta"`
+}
+
+type MemberSearchResult struct {
+	Member
+	// Score is how closely the id or city matches Q, from 0 to 1
+	Score float64 `db:"score" json:"score"`
+}
+
+type ExportMembersReq struct {
+	Format string `query:"format" validate:"omitempty,oneof=csv xlsx"`
+	City   string `query:"city"`
+	Tier   string `query:"tier"`
+	// JoinFrom and JoinTo bound the join date, both inclusive
+	JoinFrom string `query:"join_from" validate:"omitempty,datetime=2006-01-02"`
+	JoinTo   string `query:"join_to" validate:"omitempty,datetime=2006-01-02"`
+}
+
+func (r *ExportMembersReq) SetDefault() {
+	if r.Format == "" {
+		r.Format = "csv"
+	}
+}
+
 type Member struct {
 	Id             string  `db:"id" csv:"MemberID" validate:"required" json:"id"`
 	JoinDate       string  `db:"join_date" csv:"JoinDate" validate:"required,datetime=2006-01-02" json:"join_date"`
@@ -39,6 +128,8 @@ type Member struct {
 	NoOfChild      int     `db:"no_of_child" csv:"NoOfChild" json:"no_of_child"`
 	EldestKidDOB   string  `db:"eldest_kid_dob" csv:"EldestKidDOB" validate:"datetime=2006-01-02" json:"eldest_kid_dob"`
 	YoungestKidDOB string  `db:"youngest_kid_dob" csv:"YoungestKidDOB" validate:"datetime=2006-01-02" json:"youngest_kid_dob"`
+	Email          *string `db:"email" csv:"Email,omitempty" validate:"omitempty,email" json:"email"`
+	Tier           *string `db:"tier" csv:"-" json:"tier"`
 
 	Pass string `db:"password" json:"-"`
 }
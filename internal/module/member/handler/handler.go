@@ -1,13 +1,20 @@
 package handler
 
 import (
+	"bufio"
 	"codebase-app/internal/adapter"
+	m "codebase-app/internal/middleware"
+	importJobRepository "codebase-app/internal/module/import_job/repository"
+	importJobService "codebase-app/internal/module/import_job/service"
 	"codebase-app/internal/module/member/entity"
 	"codebase-app/internal/module/member/ports"
 	"codebase-app/internal/module/member/repository"
 	"codebase-app/internal/module/member/service"
 	"codebase-app/pkg/errmsg"
+	"codebase-app/pkg/exporter"
+	"codebase-app/pkg/queryspec"
 	"codebase-app/pkg/response"
+	"context"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/rs/zerolog/log"
@@ -21,7 +28,8 @@ type memberHandler struct {
 func NewMemberHandler() *memberHandler {
 	var (
 		repo    = repository.NewMemberRepository()
-		service = service.NewMemberService(repo)
+		imports = importJobService.NewImportJobService(importJobRepository.NewImportJobRepository())
+		service = service.NewMemberService(repo, imports)
 		handler = new(memberHandler)
 	)
 	handler.service = service
@@ -31,8 +39,10 @@ func NewMemberHandler() *memberHandler {
 
 func (h *memberHandler) Register(router fiber.Router) {
 	router.Get("/", h.getMembers)
-	router.Post("/import", h.importMembers)
+	router.Post("/import", m.AuthBearer, h.importMembers)
 	router.Get("/data", h.getMembers)
+	router.Get("/search", h.searchMembers)
+	router.Get("/export", h.exportMembers)
 }
 
 func (h *memberHandler) importMembers(c *fiber.Ctx) error {
@@ -51,18 +61,30 @@ func (h *memberHandler) importMembers(c *fiber.Ctx) error {
 	}
 	req.File = file
 
+	if err := c.QueryParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::importMember - Failed to parse query")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.SetDefault()
+
+	locals := m.GetLocals(c)
+	uploadedBy := locals.GetUserId()
+	req.UploadedBy = &uploadedBy
+
 	if err := v.Validate(req); err != nil {
 		log.Warn().Err(err).Any("req", req).Msg("handler::importMember - Invalid request body")
 		code, errs := errmsg.Errors(err, req)
 		return c.Status(code).JSON(response.Error(errs))
 	}
 
-	if err := h.service.ImportMembers(ctx, req); err != nil {
+	resp, err := h.service.ImportMembers(ctx, req)
+	if err != nil {
 		code, errs := errmsg.Errors[error](err)
 		return c.Status(code).JSON(response.Error(errs))
 	}
 
-	return c.JSON(response.Success(nil, ""))
+	return c.JSON(response.Success(resp, ""))
 }
 
 func (h *memberHandler) getMembers(c *fiber.Ctx) error {
@@ -85,6 +107,14 @@ func (h *memberHandler) getMembers(c *fiber.Ctx) error {
 		return c.Status(code).JSON(response.Error(errs))
 	}
 
+	spec, err := queryspec.Parse(c.Queries(), entity.MemberFields)
+	if err != nil {
+		log.Warn().Err(err).Any("req", req).Msg("handler::getMembers - Invalid filter")
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+	req.Spec = spec
+
 	resp, err := h.service.GetMembers(ctx, req)
 	if err != nil {
 		code, errs := errmsg.Errors[error](err)
@@ -93,3 +123,75 @@ func (h *memberHandler) getMembers(c *fiber.Ctx) error {
 
 	return c.JSON(response.Success(resp, ""))
 }
+
+func (h *memberHandler) searchMembers(c *fiber.Ctx) error {
+	var (
+		req = new(entity.SearchMembersReq)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+	)
+
+	if err := c.QueryParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::searchMembers - Failed to parse query")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.SetDefault()
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("req", req).Msg("handler::searchMembers - Invalid request body")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	resp, err := h.service.SearchMembers(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.JSON(response.Success(resp, ""))
+}
+
+func (h *memberHandler) exportMembers(c *fiber.Ctx) error {
+	var (
+		req = new(entity.ExportMembersReq)
+		v   = adapter.Adapters.Validator
+	)
+
+	if err := c.QueryParser(req); err != nil {
+		log.Warn().Err(err).Any("req", req).Msg("handler::exportMembers - Invalid request query")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.SetDefault()
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("req", req).Msg("handler::exportMembers - Invalid request query")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	// the body is written after the handler returns, the export gets its own
+	// context, canceled once the stream ends
+	ctx, cancel := context.WithCancel(context.Background())
+
+	stream, err := h.service.ExportMembers(ctx, req)
+	if err != nil {
+		cancel()
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	c.Attachment("members." + req.Format)
+	c.Set(fiber.HeaderContentType, exporter.ContentType(req.Format))
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer cancel()
+		if err := stream(w); err != nil {
+			log.Error().Err(err).Any("req", req).Msg("handler::exportMembers - Failed to export members")
+		}
+	})
+
+	return nil
+}
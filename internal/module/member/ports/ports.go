@@ -1,18 +1,35 @@
 package ports
 
 import (
+	importJobEntity "codebase-app/internal/module/import_job/entity"
 	"codebase-app/internal/module/member/entity"
+	"codebase-app/pkg/exporter"
+	"codebase-app/pkg/importer"
 	"context"
 )
 
 type MemberRepository interface {
-	ImportMembers(ctx context.Context, req *entity.ImportMembersReq) error
+	ImportMembers(ctx context.Context, src importer.Source, opts importer.Options[entity.Member]) (*importer.Result, error)
+	RollbackMembersImport(ctx context.Context, id string) (int64, error)
 
 	GetMembers(ctx context.Context, req *entity.GetMembersReq) (*entity.GetMembersResp, error)
+	SearchMembers(ctx context.Context, req *entity.SearchMembersReq) (*entity.SearchMembersResp, error)
+	ExportMembers(ctx context.Context, req *entity.ExportMembersReq) (*exporter.Cursor[entity.Member], error)
 }
 
 type MemberService interface {
-	ImportMembers(ctx context.Context, req *entity.ImportMembersReq) error
+	ImportMembers(ctx context.Context, req *entity.ImportMembersReq) (*importJobEntity.ImportJob, error)
+	// RunMembersImport is the runner of queued member imports, see import_job
+	// ports.ImportRunner.
+	RunMembersImport(ctx context.Context, src importer.Source, settings importer.Settings) (*importer.Result, error)
+	// RollbackMembersImport undoes a completed member import, see import_job
+	// ports.ImportRollback.
+	RollbackMembersImport(ctx context.Context, id string) (int64, error)
 
 	GetMembers(ctx context.Context, req *entity.GetMembersReq) (*entity.GetMembersResp, error)
+	SearchMembers(ctx context.Context, req *entity.SearchMembersReq) (*entity.SearchMembersResp, error)
+
+	// ExportMembers opens the file of req.Format, in the import format. An
+	// invalid query fails here, before the stream writes anything.
+	ExportMembers(ctx context.Context, req *entity.ExportMembersReq) (exporter.Stream, error)
 }
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"codebase-app/internal/module/member/entity"
+	"codebase-app/pkg/exporter"
+	"context"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ExportMembers streams the members matching req, ordered by id.
+// Dates are formatted the way the import reads them.
+func (r *memberRepo) ExportMembers(ctx context.Context, req *entity.ExportMembersReq) (*exporter.Cursor[entity.Member], error) {
+	var (
+		conds = make([]string, 0)
+		args  = make([]any, 0)
+	)
+
+	if req.City != "" {
+		conds = append(conds, "m.city = ?")
+		args = append(args, req.City)
+	}
+
+	if req.Tier != "" {
+		conds = append(conds, "mt.tier_code = ?")
+		args = append(args, req.Tier)
+	}
+
+	if req.JoinFrom != "" {
+		conds = append(conds, "m.join_date >= ?::date")
+		args = append(args, req.JoinFrom)
+	}
+
+	if req.JoinTo != "" {
+		conds = append(conds, "m.join_date <= ?::date")
+		args = append(args, req.JoinTo)
+	}
+
+	query := `
+		SELECT
+			m.id,
+			COALESCE(to_char(m.join_date, 'YYYY-MM-DD'), '') AS join_date,
+			to_char(m.date_of_birth, 'YYYY-MM-DD') AS date_of_birth,
+			COALESCE(m.city, '') AS city,
+			COALESCE(m.no_of_child, 0) AS no_of_child,
+			COALESCE(to_char(m.eldest_kid_dob, 'YYYY-MM-DD'), '') AS eldest_kid_dob,
+			COALESCE(to_char(m.youngest_kid_dob, 'YYYY-MM-DD'), '') AS youngest_kid_dob,
+			m.email,
+			mt.tier_code AS tier
+		FROM members m
+		LEFT JOIN member_tiers mt ON mt.member_id = m.id
+	`
+
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	query += " ORDER BY m.id"
+
+	cursor, err := exporter.Open[entity.Member](ctx, r.db, r.db.Rebind(query), args)
+	if err != nil {
+		log.Error().Err(err).Msg("repo::ExportMembers - failed to export members")
+		return nil, err
+	}
+
+	return cursor, nil
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	importJobEntity "codebase-app/internal/module/import_job/entity"
+	"codebase-app/internal/module/member/entity"
+	"codebase-app/pkg/importer"
+	"context"
+
+	"github.com/rs/zerolog/log"
+)
+
+var membersTarget = importer.Target[entity.Member]{
+	Table:       "staging_members",
+	Like:        "members",
+	Key:         []string{"id"},
+	BatchColumn: "import_job_id",
+	Columns: []string{
+		"id",
+		"join_date",
+		"date_of_birth",
+		"city",
+		"no_of_child",
+		"eldest_kid_dob",
+		"youngest_kid_dob",
+		"email",
+		"password",
+	},
+	// a re-import must not reset passwords to the default
+	InsertOnly: []string{"password"},
+	Values: func(m *entity.Member) []any {
+		return []any{
+			m.Id,
+			m.JoinDate,
+			m.DateOfBirth,
+			m.City,
+			m.NoOfChild,
+			m.EldestKidDOB,
+			m.YoungestKidDOB,
+			m.Email,
+			m.Pass,
+		}
+	},
+}
+
+func (r *memberRepo) ImportMembers(ctx context.Context, src importer.Source, opts importer.Options[entity.Member]) (*importer.Result, error) {
+	res, err := importer.Import(ctx, r.db, membersTarget, src, opts)
+	if err != nil {
+		if !importer.IsInputError(err) {
+			log.Error().Err(err).Msg("repo::ImportMembers - failed to import")
+		}
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// RollbackMembersImport deletes the members an import inserted with their
+// segments, predictions and tiers. It fails with ErrImportJobReferenced while
+// any of them has transactions or points.
+func (r *memberRepo) RollbackMembersImport(ctx context.Context, id string) (int64, error) {
+	var inUse bool
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Msg("repo::RollbackMembersImport - failed to begin transaction")
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM product_transactions pt
+			JOIN members m ON m.id = pt.member_id
+			WHERE m.import_job_id = ?
+		) OR EXISTS (
+			SELECT 1 FROM points_journals pj
+			JOIN members m ON m.id = pj.member_id
+			WHERE m.import_job_id = ?
+		)
+	`
+
+	if err := tx.GetContext(ctx, &inUse, tx.Rebind(query), id, id); err != nil {
+		log.Error().Err(err).Str("import_job_id", id).Msg("repo::RollbackMembersImport - failed to check references")
+		return 0, err
+	}
+
+	if inUse {
+		return 0, importJobEntity.ErrImportJobReferenced
+	}
+
+	derived := []string{
+		"member_segments",
+		"member_predictions",
+		"prediction_logs",
+		"member_tier_histories",
+		"member_tiers",
+		"points_balances",
+	}
+
+	for _, table := range derived {
+		query := `DELETE FROM ` + table + ` WHERE member_id IN (SELECT id FROM members WHERE import_job_id = ?)`
+		if _, err := tx.ExecContext(ctx, tx.Rebind(query), id); err != nil {
+			log.Error().Err(err).Str("table", table).Str("import_job_id", id).Msg("repo::RollbackMembersImport - failed to delete member data")
+			return 0, err
+		}
+	}
+
+	result, err := tx.ExecContext(ctx, tx.Rebind(`DELETE FROM members WHERE import_job_id = ?`), id)
+	if err != nil {
+		log.Error().Err(err).Str("import_job_id", id).Msg("repo::RollbackMembersImport - failed to delete members")
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Msg("repo::RollbackMembersImport - failed to commit transaction")
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
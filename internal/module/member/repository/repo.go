@@ -4,6 +4,7 @@ import (
 	"codebase-app/internal/adapter"
 	"codebase-app/internal/module/member/entity"
 	"codebase-app/internal/module/member/ports"
+	"codebase-app/pkg/queryspec"
 	"context"
 
 	"github.com/jmoiron/sqlx"
@@ -22,39 +23,11 @@ func NewMemberRepository() *memberRepo {
 	}
 }
 
-func (r *memberRepo) ImportMembers(ctx context.Context, req *entity.ImportMembersReq) error {
-	tx, err := r.db.BeginTxx(ctx, nil)
-	if err != nil {
-		log.Error().Err(err).Msg("failed to begin transaction")
-		return err
-	}
-
-	// Prepare the insert query
-	query := `
-		INSERT INTO members (id, join_date, date_of_birth, city, no_of_child, eldest_kid_dob, youngest_kid_dob, password)
-		VALUES (:id, :join_date, :date_of_birth, :city, :no_of_child, :eldest_kid_dob, :youngest_kid_dob, :password)
-	`
-
-	// Iterate over each member in req.Data and execute the insert query
-	for _, member := range req.Data {
-		_, err := tx.NamedExecContext(ctx, query, member)
-		if err != nil {
-			log.Error().Err(err).Any("member", member).Msg("failed to insert member")
-			tx.Rollback() // Roll back transaction on error
-			return err
-		}
-	}
-
-	// Commit the transaction
-	if err := tx.Commit(); err != nil {
-		log.Error().Err(err).Msg("failed to commit transaction")
-		return err
+func (r *memberRepo) GetMembers(ctx context.Context, req *entity.GetMembersReq) (*entity.GetMembersResp, error) {
+	if req.CursorMode() {
+		return r.getMembersByCursor(ctx, req)
 	}
 
-	return nil
-}
-
-func (r *memberRepo) GetMembers(ctx context.Context, req *entity.GetMembersReq) (*entity.GetMembersResp, error) {
 	type dao struct {
 		TotalData int `db:"total_data"`
 		entity.Member
@@ -68,19 +41,26 @@ func (r *memberRepo) GetMembers(ctx context.Context, req *entity.GetMembersReq)
 	query := `
 		SELECT
 			COUNT(*) OVER() AS total_data,
-			id,
-			join_date,
-			date_of_birth,
-			city,
-			no_of_child,
-			eldest_kid_dob,
-			youngest_kid_dob,
-			password
-		FROM members
-		LIMIT ? OFFSET ?
+			m.id,
+			m.join_date,
+			m.date_of_birth,
+			m.city,
+			m.no_of_child,
+			m.eldest_kid_dob,
+			m.youngest_kid_dob,
+			m.email,
+			m.password,
+			mt.tier_code AS tier
+		FROM members m
+		LEFT JOIN member_tiers mt ON mt.member_id = m.id
+		WHERE 1 = 1
 		`
 
-	if err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), req.Paginate, (req.Page-1)*req.Paginate); err != nil {
+	where, args := req.Spec.Where()
+	query += where + req.Spec.OrderBy("m.id") + ` LIMIT ? OFFSET ?`
+	args = append(args, req.Paginate, (req.Page-1)*req.Paginate)
+
+	if err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), args...); err != nil {
 		log.Error().Err(err).Msg("failed to get members")
 		return nil, err
 	}
@@ -97,3 +77,44 @@ func (r *memberRepo) GetMembers(ctx context.Context, req *entity.GetMembersReq)
 
 	return res, nil
 }
+
+// getMembersByCursor pages members by MemberKeyset in cursor mode.
+func (r *memberRepo) getMembersByCursor(ctx context.Context, req *entity.GetMembersReq) (*entity.GetMembersResp, error) {
+	query := `
+		SELECT
+			m.id,
+			m.join_date,
+			m.date_of_birth,
+			m.city,
+			m.no_of_child,
+			m.eldest_kid_dob,
+			m.youngest_kid_dob,
+			m.email,
+			m.password,
+			mt.tier_code AS tier
+		FROM members m
+		LEFT JOIN member_tiers mt ON mt.member_id = m.id
+		WHERE 1 = 1
+	`
+
+	var (
+		res = new(entity.GetMembersResp)
+		err error
+	)
+
+	res.Items, res.Meta, err = queryspec.SelectPage(ctx, r.db, query, queryspec.PageReq{
+		Keyset:   entity.MemberKeyset,
+		Spec:     req.Spec,
+		Cursor:   req.Cursor,
+		Paginate: req.Paginate,
+		Count:    req.Count,
+	}, func(item entity.Member) []string {
+		return []string{item.Id}
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("repo::GetMembers - failed to get members")
+		return nil, err
+	}
+
+	return res, nil
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"codebase-app/internal/module/member/entity"
+	"codebase-app/pkg/queryspec"
+	"context"
+
+	"github.com/rs/zerolog/log"
+)
+
+// SearchMembers pages the members whose id or city resemble req.Q, closest
+// first, within the date filters of req. Partial ids match by substring,
+// misspelled cities by trigram similarity, both served by the trigram
+// indexes.
+func (r *memberRepo) SearchMembers(ctx context.Context, req *entity.SearchMembersReq) (*entity.SearchMembersResp, error) {
+	type dao struct {
+		TotalData int `db:"total_data"`
+		entity.MemberSearchResult
+	}
+
+	var (
+		res  = new(entity.SearchMembersResp)
+		data = make([]dao, 0)
+		args = make([]any, 0)
+	)
+	res.Items = make([]entity.MemberSearchResult, 0)
+
+	score := `0`
+	if req.Q != "" {
+		score = `GREATEST(word_similarity(?, m.id), similarity(?, COALESCE(m.city, '')))`
+		args = append(args, req.Q, req.Q)
+	}
+
+	query := `
+		SELECT
+			COUNT(*) OVER() AS total_data,
+			m.id,
+			m.join_date,
+			m.date_of_birth,
+			m.city,
+			m.no_of_child,
+			m.eldest_kid_dob,
+			m.youngest_kid_dob,
+			m.email,
+			mt.tier_code AS tier,
+			` + score + ` AS score
+		FROM members m
+		LEFT JOIN member_tiers mt ON mt.member_id = m.id
+		WHERE 1 = 1
+	`
+
+	if req.Q != "" {
+		like := "%" + queryspec.EscapeLike(req.Q) + "%"
+		query += ` AND (m.id ILIKE ? OR m.city ILIKE ? OR ? <% m.id OR m.city % ?)`
+		args = append(args, like, like, req.Q, req.Q)
+	}
+
+	if req.DateOfBirth != "" {
+		query += ` AND m.date_of_birth = ?::date`
+		args = append(args, req.DateOfBirth)
+	}
+
+	if req.JoinFrom != "" {
+		query += ` AND m.join_date >= ?::date`
+		args = append(args, req.JoinFrom)
+	}
+
+	if req.JoinTo != "" {
+		query += ` AND m.join_date <= ?::date`
+		args = append(args, req.JoinTo)
+	}
+
+	query += ` ORDER BY score DESC, m.id LIMIT ? OFFSET ?`
+	args = append(args, req.Paginate, (req.Page-1)*req.Paginate)
+
+	if err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), args...); err != nil {
+		log.Error().Err(err).Msg("repo::SearchMembers - failed to search members")
+		return nil, err
+	}
+
+	for _, d := range data {
+		res.Items = append(res.Items, d.MemberSearchResult)
+	}
+
+	if len(res.Items) > 0 {
+		res.Meta.TotalData = data[0].TotalData
+	}
+
+	res.Meta.CountTotalPage(req.Page, req.Paginate, res.Meta.TotalData)
+
+	return res, nil
+}
@@ -2,94 +2,69 @@ package service
 
 import (
 	"codebase-app/internal/adapter"
+	importJobEntity "codebase-app/internal/module/import_job/entity"
+	importJobPorts "codebase-app/internal/module/import_job/ports"
 	"codebase-app/internal/module/member/entity"
 	"codebase-app/internal/module/member/ports"
 	"codebase-app/pkg/errmsg"
+	"codebase-app/pkg/exporter"
+	"codebase-app/pkg/importer"
 	"context"
-	"os"
-	"strconv"
 
-	"github.com/gocarina/gocsv"
-	"github.com/oklog/ulid/v2"
 	"github.com/rs/zerolog/log"
 )
 
 var _ ports.MemberService = &memberService{}
 
 type memberService struct {
-	repo ports.MemberRepository
+	repo    ports.MemberRepository
+	imports importJobPorts.ImportJobService
 }
 
-func NewMemberService(repo ports.MemberRepository) *memberService {
+func NewMemberService(repo ports.MemberRepository, imports importJobPorts.ImportJobService) *memberService {
 	return &memberService{
-		repo: repo,
+		repo:    repo,
+		imports: imports,
 	}
 }
 
-func (s *memberService) ImportMembers(ctx context.Context, req *entity.ImportMembersReq) error {
-	var v = adapter.Adapters.Validator
+func (s *memberService) ImportMembers(ctx context.Context, req *entity.ImportMembersReq) (*importJobEntity.ImportJob, error) {
 	if req.File == nil {
 		log.Warn().Any("req", req).Msg("service::importMember - Missing file")
-		return errmsg.NewCustomErrors(400).SetMessage("Missing file")
-	}
-
-	// Open the uploaded file
-	file, err := req.File.Open()
-	if err != nil {
-		log.Warn().Err(err).Any("req", req).Msg("service::importMember - Failed to open file")
-		return err
-	}
-	defer file.Close()
-
-	// convert multipart.File to *os.File
-	f, err := os.Create(ulid.Make().String() + "_members.csv")
-	if err != nil {
-		log.Warn().Err(err).Any("req", req).Msg("service::importMember - Failed to create file")
-		return errmsg.NewCustomErrors(400).SetMessage("Failed to create file")
-	}
-
-	// insert the file content to the new file
-	if _, err := file.Seek(0, 0); err != nil {
-		log.Warn().Err(err).Any("req", req).Msg("service::importMember - Failed to seek file")
-		return errmsg.NewCustomErrors(400).SetMessage("Failed to seek file")
-	}
-
-	if _, err := f.ReadFrom(file); err != nil {
-		log.Warn().Err(err).Any("req", req).Msg("service::importMember - Failed to read file")
-		return errmsg.NewCustomErrors(400).SetMessage("Failed to read file")
-	}
-
-	if _, err := f.Seek(0, 0); err != nil {
-		log.Warn().Err(err).Any("req", req).Msg("service::importMember - Failed to seek file")
-		return errmsg.NewCustomErrors(400).SetMessage("Failed to seek file")
-	}
-	defer os.Remove(f.Name())
-
-	// Read the file content
-	members := make([]entity.Member, 0)
-
-	if err := gocsv.UnmarshalFile(f, &members); err != nil {
-		log.Warn().Err(err).Any("req", req).Msg("service::importMember - Failed to unmarshal file")
-		return errmsg.NewCustomErrors(400).SetMessage("Failed to read CSV file")
+		return nil, errmsg.NewCustomErrors(400).SetMessage("Missing file")
 	}
 
-	req.Data = members
-
-	for i, member := range members {
-		if err := v.Validate(member); err != nil {
-			log.Warn().Err(err).Any("req", req).Msg("service::importMember - Failed to validate member")
-			return errmsg.NewCustomErrors(400).SetMessage("Invalid member data at row " + strconv.Itoa(i+2))
-		}
+	return s.imports.Enqueue(ctx, importJobEntity.KindMembers, req.File, importJobEntity.ImportJobOptions{
+		DryRun:     req.DryRun,
+		Sheet:      req.Sheet,
+		OnConflict: req.OnConflict,
+		UploadedBy: req.UploadedBy,
+		Profile:    req.Profile,
+	})
+}
 
-		// Set default password if not provided
-		if member.Pass == "" {
-			members[i].Pass = generatePassword()
-		}
-	}
+func (s *memberService) RunMembersImport(ctx context.Context, src importer.Source, settings importer.Settings) (*importer.Result, error) {
+	var v = adapter.Adapters.Validator
 
-	// Call the repository to import members
-	return s.repo.ImportMembers(ctx, req)
+	return s.repo.ImportMembers(ctx, src, importer.Options[entity.Member]{
+		Settings: settings,
+		Validate: func(member *entity.Member) error {
+			if err := importer.Validate(v, member); err != nil {
+				return err
+			}
+
+			// Set default password if not provided
+			if member.Pass == "" {
+				member.Pass = generatePassword()
+			}
+
+			return nil
+		},
+	})
+}
 
+func (s *memberService) RollbackMembersImport(ctx context.Context, id string) (int64, error) {
+	return s.repo.RollbackMembersImport(ctx, id)
 }
 
 // Helper function to generate a password
@@ -101,3 +76,16 @@ func generatePassword() string {
 func (s *memberService) GetMembers(ctx context.Context, req *entity.GetMembersReq) (*entity.GetMembersResp, error) {
 	return s.repo.GetMembers(ctx, req)
 }
+
+func (s *memberService) SearchMembers(ctx context.Context, req *entity.SearchMembersReq) (*entity.SearchMembersResp, error) {
+	return s.repo.SearchMembers(ctx, req)
+}
+
+func (s *memberService) ExportMembers(ctx context.Context, req *entity.ExportMembersReq) (exporter.Stream, error) {
+	cursor, err := s.repo.ExportMembers(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return exporter.NewStream(cursor, req.Format), nil
+}
@@ -0,0 +1,86 @@
+package entity
+
+import (
+	"codebase-app/pkg/types"
+	"errors"
+	"time"
+)
+
+// Journal types.
+const (
+	JournalEarn    = "earn"
+	JournalRedeem  = "redeem"
+	JournalExpire  = "expire"
+	JournalReverse = "reverse"
+)
+
+// Ledger accounts. Only the member account belongs to a member, the others
+// are system accounts that balance it.
+const (
+	AccountMember   = "member"
+	AccountIssued   = "issued"
+	AccountRedeemed = "redeemed"
+	AccountExpired  = "expired"
+)
+
+var ErrInsufficientPoints = errors.New("insufficient points")
+
+type GetMemberPointsReq struct {
+	MemberId string `params:"id" validate:"required"`
+}
+
+type GetMemberPointsResp struct {
+	MemberId       string     `db:"member_id" json:"member_id"`
+	Balance        int        `db:"balance" json:"balance"`
+	LifetimeEarned int        `db:"lifetime_earned" json:"lifetime_earned"`
+	UpdatedAt      *time.Time `db:"updated_at" json:"updated_at"`
+}
+
+type GetMemberPointsHistoryReq struct {
+	MemberId string `params:"id" validate:"required"`
+	Type     string `query:"type" validate:"omitempty,oneof=earn redeem expire reverse"`
+	Page     int    `query:"page" validate:"required,numeric"`
+	Paginate int    `query:"paginate" validate:"required,numeric"`
+}
+
+func (r *GetMemberPointsHistoryReq) SetDefault() {
+	if r.Page < 1 {
+		r.Page = 1
+	}
+
+	if r.Paginate < 1 {
+		r.Paginate = 10
+	}
+}
+
+type GetMemberPointsHistoryResp struct {
+	Items []Journal  `json:"items"`
+	Meta  types.Meta `json:"meta"`
+}
+
+type Journal struct {
+	Id            int64     `db:"id" json:"id"`
+	Type          string    `db:"type" json:"type"`
+	TransactionId *string   `db:"transaction_id" json:"transaction_id"`
+	Points        int       `db:"points" json:"points"`
+	BalanceAfter  int       `db:"balance_after" json:"balance_after"`
+	Description   *string   `db:"description" json:"description"`
+	CreatedAt     time.Time `db:"created_at" json:"created_at"`
+}
+
+type RedeemPointsReq struct {
+	MemberId    string  `params:"id" validate:"required"`
+	Points      int     `json:"points" validate:"required,min=1"`
+	Description *string `json:"description" validate:"omitempty,max=255"`
+}
+
+type RedeemPointsResp struct {
+	JournalId int64 `json:"journal_id"`
+	Points    int   `json:"points"`
+	Balance   int   `json:"balance"`
+}
+
+type ExpirePointsResult struct {
+	Members int `json:"members"`
+	Points  int `json:"points"`
+}
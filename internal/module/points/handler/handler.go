@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"codebase-app/internal/adapter"
+	"codebase-app/internal/module/points/entity"
+	"codebase-app/internal/module/points/ports"
+	"codebase-app/internal/module/points/repository"
+	"codebase-app/internal/module/points/service"
+	"codebase-app/pkg/errmsg"
+	"codebase-app/pkg/response"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+type pointsHandler struct {
+	service ports.PointsService
+}
+
+func NewPointsHandler() *pointsHandler {
+	var (
+		repo    = repository.NewPointsRepository()
+		service = service.NewPointsService(repo)
+		handler = new(pointsHandler)
+	)
+	handler.service = service
+
+	return handler
+}
+
+func (h *pointsHandler) Register(router fiber.Router) {
+	router.Get("/members/:id/points", h.getMemberPoints)
+	router.Get("/members/:id/points/history", h.getMemberPointsHistory)
+	router.Post("/members/:id/points/redeem", h.redeemPoints)
+}
+
+func (h *pointsHandler) getMemberPoints(c *fiber.Ctx) error {
+	var (
+		req = new(entity.GetMemberPointsReq)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+	)
+
+	if err := c.ParamsParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::getMemberPoints - Failed to parse params")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("req", req).Msg("handler::getMemberPoints - Invalid request params")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	resp, err := h.service.GetMemberPoints(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.JSON(response.Success(resp, ""))
+}
+
+func (h *pointsHandler) getMemberPointsHistory(c *fiber.Ctx) error {
+	var (
+		req = new(entity.GetMemberPointsHistoryReq)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+	)
+
+	if err := c.ParamsParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::getMemberPointsHistory - Failed to parse params")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	if err := c.QueryParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::getMemberPointsHistory - Failed to parse query")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.SetDefault()
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("req", req).Msg("handler::getMemberPointsHistory - Invalid request query")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	resp, err := h.service.GetMemberPointsHistory(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.JSON(response.Success(resp, ""))
+}
+
+func (h *pointsHandler) redeemPoints(c *fiber.Ctx) error {
+	var (
+		req = new(entity.RedeemPointsReq)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+	)
+
+	if err := c.BodyParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::redeemPoints - Failed to parse request body")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	if err := c.ParamsParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::redeemPoints - Failed to parse params")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("req", req).Msg("handler::redeemPoints - Invalid request body")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	resp, err := h.service.RedeemPoints(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.JSON(response.Success(resp, ""))
+}
@@ -0,0 +1,25 @@
+package ports
+
+import (
+	"codebase-app/internal/module/points/entity"
+	"context"
+	"time"
+)
+
+type PointsRepository interface {
+	IsMemberExist(ctx context.Context, memberId string) (bool, error)
+	GetMemberPoints(ctx context.Context, memberId string) (*entity.GetMemberPointsResp, error)
+	GetMemberPointsHistory(ctx context.Context, req *entity.GetMemberPointsHistoryReq) (*entity.GetMemberPointsHistoryResp, error)
+
+	Redeem(ctx context.Context, req *entity.RedeemPointsReq) (*entity.RedeemPointsResp, error)
+	GetMembersWithExpiredPoints(ctx context.Context, before time.Time) ([]string, error)
+	ExpireMemberPoints(ctx context.Context, memberId string, before time.Time) (int, error)
+}
+
+type PointsService interface {
+	GetMemberPoints(ctx context.Context, req *entity.GetMemberPointsReq) (*entity.GetMemberPointsResp, error)
+	GetMemberPointsHistory(ctx context.Context, req *entity.GetMemberPointsHistoryReq) (*entity.GetMemberPointsHistoryResp, error)
+
+	RedeemPoints(ctx context.Context, req *entity.RedeemPointsReq) (*entity.RedeemPointsResp, error)
+	ExpirePoints(ctx context.Context) (*entity.ExpirePointsResult, error)
+}
@@ -0,0 +1,568 @@
+package repository
+
+import (
+	"codebase-app/internal/module/points/entity"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+)
+
+// Every change to a member balance goes through lockBalance first, so
+// concurrent redemptions, expiries and reversals of one member queue up on the
+// balance row instead of spending the same points twice.
+
+// EarnTransactionPoints posts the earn journal of each transaction, worth the
+// grammage point times qty, and moves the member balances with it. It runs on
+// the caller's database transaction so the points commit with the purchase.
+// Transactions that already earned or were voided are skipped, which keeps
+// re-imports from awarding twice.
+func EarnTransactionPoints(ctx context.Context, db sqlx.ExtContext, transactionIds []string) (int64, error) {
+	if len(transactionIds) == 0 {
+		return 0, nil
+	}
+
+	result, err := db.ExecContext(ctx, db.Rebind(earnQuery("pt.id = ANY(?)")), pq.Array(transactionIds))
+	if err != nil {
+		log.Error().Err(err).Int("transactions", len(transactionIds)).Msg("repo::EarnTransactionPoints - failed to earn points")
+		return 0, err
+	}
+
+	if err := settleDebts(ctx, db, "pt.id = ANY(?)", pq.Array(transactionIds)); err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// EarnStagedTransactionPoints is EarnTransactionPoints for every transaction
+// id in a staging table, used by bulk imports where the ids are too many to
+// pass as a parameter.
+func EarnStagedTransactionPoints(ctx context.Context, db sqlx.ExtContext, staging string) (int64, error) {
+	filter := fmt.Sprintf("pt.id IN (SELECT id FROM %s)", pq.QuoteIdentifier(staging))
+
+	result, err := db.ExecContext(ctx, earnQuery(filter))
+	if err != nil {
+		log.Error().Err(err).Str("staging", staging).Msg("repo::EarnStagedTransactionPoints - failed to earn points")
+		return 0, err
+	}
+
+	if err := settleDebts(ctx, db, filter); err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func earnQuery(filter string) string {
+	return `
+		WITH earned AS (
+			INSERT INTO points_journals (
+				member_id,
+				type,
+				transaction_id,
+				points,
+				remaining,
+				created_at
+			)
+			SELECT
+				pt.member_id,
+				'earn',
+				pt.id,
+				pg.point * pt.qty,
+				pg.point * pt.qty,
+				COALESCE(pt.created_at, NOW())
+			FROM product_transactions pt
+			JOIN product_grammages pg ON pg.id = pt.product_grammage_id
+			WHERE
+				` + filter + `
+				AND pt.voided_at IS NULL
+				AND pg.point > 0
+				AND pt.qty > 0
+			ON CONFLICT (transaction_id, type) WHERE transaction_id IS NOT NULL DO NOTHING
+			RETURNING id, member_id, points, created_at
+		), entries AS (
+			INSERT INTO points_ledger (journal_id, account, member_id, amount, created_at)
+			SELECT id, 'member', member_id, points, created_at FROM earned
+			UNION ALL
+			SELECT id, 'issued', NULL, -points, created_at FROM earned
+		)
+		INSERT INTO points_balances (member_id, balance, updated_at)
+		SELECT member_id, SUM(points), NOW()
+		FROM earned
+		GROUP BY member_id
+		ON CONFLICT (member_id) DO UPDATE SET
+			balance = points_balances.balance + EXCLUDED.balance,
+			updated_at = EXCLUDED.updated_at
+	`
+}
+
+// ReverseTransactionPoints takes back the points a voided transaction earned.
+// The unspent part of its own lot goes first, then the oldest other lots. If
+// the member already spent more than is left, the balance goes negative and
+// the shortfall stays on the reverse journal as debt, a negative remaining
+// that the next earnings pay off before they can be spent. It returns the
+// points taken back.
+func ReverseTransactionPoints(ctx context.Context, db sqlx.ExtContext, transactionId string) (int, error) {
+	points, err := ReverseTransactionsPoints(ctx, db, "pt.id = ?", transactionId)
+	return int(points), err
+}
+
+// ReverseTransactionsPoints is ReverseTransactionPoints for every transaction
+// matching filter, a condition on product_transactions pt. The journals are
+// posted and the lots drained with a fixed number of statements whatever the
+// number of transactions, so a whole import batch is reversed at once.
+// Transactions reversed before are skipped.
+func ReverseTransactionsPoints(ctx context.Context, db sqlx.ExtContext, filter string, args ...any) (int64, error) {
+	var (
+		memberIds = make([]string, 0)
+		reversals = make([]reversal, 0)
+		lots      = make([]memberLot, 0)
+	)
+
+	// member order keeps the balance locks in the same order as other batches
+	query := `
+		SELECT member_id
+		FROM points_balances
+		WHERE member_id IN (
+			SELECT j.member_id
+			FROM points_journals j
+			JOIN product_transactions pt ON pt.id = j.transaction_id
+			WHERE j.type = 'earn' AND ` + filter + `
+		)
+		ORDER BY member_id
+		FOR UPDATE
+	`
+
+	if err := sqlx.SelectContext(ctx, db, &memberIds, db.Rebind(query), args...); err != nil {
+		log.Error().Err(err).Msg("repo::ReverseTransactionsPoints - failed to lock balances")
+		return 0, err
+	}
+
+	if len(memberIds) == 0 {
+		return 0, nil
+	}
+
+	query = `
+		WITH earns AS (
+			SELECT j.id, j.member_id, j.transaction_id, j.points
+			FROM points_journals j
+			JOIN product_transactions pt ON pt.id = j.transaction_id
+			WHERE j.type = 'earn' AND ` + filter + `
+		), reversed AS (
+			INSERT INTO points_journals (member_id, type, transaction_id, points)
+			SELECT member_id, 'reverse', transaction_id, -points FROM earns
+			ON CONFLICT (transaction_id, type) WHERE transaction_id IS NOT NULL DO NOTHING
+			RETURNING id, member_id, transaction_id, points
+		), entries AS (
+			INSERT INTO points_ledger (journal_id, account, member_id, amount)
+			SELECT id, 'member', member_id, points FROM reversed
+			UNION ALL
+			SELECT id, 'issued', NULL, -points FROM reversed
+		), balances AS (
+			UPDATE points_balances b
+			SET balance = b.balance + r.points, updated_at = NOW()
+			FROM (SELECT member_id, SUM(points) AS points FROM reversed GROUP BY member_id) r
+			WHERE b.member_id = r.member_id
+		)
+		SELECT r.id, r.member_id, e.id AS earn_id, -r.points AS points
+		FROM reversed r
+		JOIN earns e ON e.transaction_id = r.transaction_id
+		ORDER BY r.member_id, r.id
+	`
+
+	if err := sqlx.SelectContext(ctx, db, &reversals, db.Rebind(query), args...); err != nil {
+		log.Error().Err(err).Msg("repo::ReverseTransactionsPoints - failed to post reverse journals")
+		return 0, err
+	}
+
+	if len(reversals) == 0 {
+		return 0, nil
+	}
+
+	query = `
+		SELECT id, member_id, remaining
+		FROM points_journals
+		WHERE member_id = ANY(?) AND type = 'earn' AND remaining > 0
+		ORDER BY member_id, created_at, id
+		FOR UPDATE
+	`
+
+	if err := sqlx.SelectContext(ctx, db, &lots, db.Rebind(query), pq.Array(memberIds)); err != nil {
+		log.Error().Err(err).Msg("repo::ReverseTransactionsPoints - failed to get open lots")
+		return 0, err
+	}
+
+	byMember := make(map[string][]lot, len(memberIds))
+	for _, l := range lots {
+		byMember[l.MemberId] = append(byMember[l.MemberId], l.lot)
+	}
+
+	var (
+		consumptions, debts = allocateReversals(reversals, byMember)
+		journalIds          = make([]int64, 0, len(consumptions))
+		lotIds              = make([]int64, 0, len(consumptions))
+		points              = make([]int64, 0, len(consumptions))
+		debtIds             = make([]int64, 0, len(debts))
+		debtPoints          = make([]int64, 0, len(debts))
+		total               int64
+	)
+
+	for _, c := range consumptions {
+		journalIds = append(journalIds, c.journalId)
+		lotIds = append(lotIds, c.lotId)
+		points = append(points, int64(c.points))
+	}
+
+	for _, r := range reversals {
+		total += int64(r.Points)
+		if debt := debts[r.Id]; debt > 0 {
+			debtIds = append(debtIds, r.Id)
+			debtPoints = append(debtPoints, int64(-debt))
+		}
+	}
+
+	query = `
+		UPDATE points_journals j
+		SET remaining = j.remaining - t.points
+		FROM (
+			SELECT id, SUM(points) AS points
+			FROM unnest(?::BIGINT[], ?::BIGINT[]) AS c(id, points)
+			GROUP BY id
+		) t
+		WHERE j.id = t.id
+	`
+
+	if _, err := db.ExecContext(ctx, db.Rebind(query), pq.Array(lotIds), pq.Array(points)); err != nil {
+		log.Error().Err(err).Msg("repo::ReverseTransactionsPoints - failed to update lots")
+		return 0, err
+	}
+
+	query = `
+		INSERT INTO points_consumptions (journal_id, earn_journal_id, points)
+		SELECT * FROM unnest(?::BIGINT[], ?::BIGINT[], ?::BIGINT[])
+	`
+
+	if _, err := db.ExecContext(ctx, db.Rebind(query), pq.Array(journalIds), pq.Array(lotIds), pq.Array(points)); err != nil {
+		log.Error().Err(err).Msg("repo::ReverseTransactionsPoints - failed to record consumptions")
+		return 0, err
+	}
+
+	if len(debtIds) > 0 {
+		query = `
+			UPDATE points_journals j
+			SET remaining = t.remaining
+			FROM unnest(?::BIGINT[], ?::BIGINT[]) AS t(id, remaining)
+			WHERE j.id = t.id
+		`
+
+		if _, err := db.ExecContext(ctx, db.Rebind(query), pq.Array(debtIds), pq.Array(debtPoints)); err != nil {
+			log.Error().Err(err).Msg("repo::ReverseTransactionsPoints - failed to record debts")
+			return 0, err
+		}
+	}
+
+	return total, nil
+}
+
+type reversal struct {
+	Id       int64  `db:"id"`
+	MemberId string `db:"member_id"`
+	EarnId   int64  `db:"earn_id"`
+	Points   int    `db:"points"`
+}
+
+type memberLot struct {
+	lot
+	MemberId string `db:"member_id"`
+}
+
+type consumption struct {
+	journalId int64
+	lotId     int64
+	points    int
+}
+
+// allocateReversals takes back the points of each reversal from the open lots
+// of its member, lowering their remaining: the reversed lot first, then the
+// oldest others. It returns where the points came from and, by reverse
+// journal, the debt the lots could not cover.
+func allocateReversals(reversals []reversal, lots map[string][]lot) ([]consumption, map[int64]int) {
+	var (
+		consumptions = make([]consumption, 0, len(reversals))
+		debts        = make(map[int64]int)
+	)
+
+	for _, r := range reversals {
+		var (
+			memberLots = lots[r.MemberId]
+			short      = r.Points
+			takes      []take
+		)
+
+		for i := range memberLots {
+			if memberLots[i].Id == r.EarnId {
+				takes, short = allocate(memberLots[i:i+1], short)
+				break
+			}
+		}
+
+		rest, short := allocate(memberLots, short)
+		for _, t := range append(takes, rest...) {
+			consumptions = append(consumptions, consumption{journalId: r.Id, lotId: t.lotId, points: t.points})
+		}
+
+		if short > 0 {
+			debts[r.Id] = short
+		}
+	}
+
+	return consumptions, debts
+}
+
+// DetachTransactionPoints unlinks the journals of the transactions matching
+// filter, a condition on product_transactions pt, that are about to be
+// deleted, so a later import of the same ids earns again. The journals stay
+// in the ledger with the transaction id in their description; the points
+// must have been reversed first.
+func DetachTransactionPoints(ctx context.Context, db sqlx.ExtContext, filter string, args ...any) error {
+	query := `
+		UPDATE points_journals
+		SET
+			description = COALESCE(description || ' ', '') || '(transaction ' || transaction_id || ' deleted)',
+			transaction_id = NULL
+		WHERE transaction_id IN (SELECT pt.id FROM product_transactions pt WHERE ` + filter + `)
+	`
+
+	if _, err := db.ExecContext(ctx, db.Rebind(query), args...); err != nil {
+		log.Error().Err(err).Msg("repo::DetachTransactionPoints - failed to detach journals")
+		return err
+	}
+
+	return nil
+}
+
+// lockBalance locks the balance row of a member, creating it when missing,
+// and returns the current balance.
+func lockBalance(ctx context.Context, db sqlx.ExtContext, memberId string) (int, error) {
+	var balance int
+
+	query := `INSERT INTO points_balances (member_id) VALUES (?) ON CONFLICT (member_id) DO NOTHING`
+	if _, err := db.ExecContext(ctx, db.Rebind(query), memberId); err != nil {
+		log.Error().Err(err).Str("member_id", memberId).Msg("repo::lockBalance - failed to create balance")
+		return 0, err
+	}
+
+	query = `SELECT balance FROM points_balances WHERE member_id = ? FOR UPDATE`
+	if err := sqlx.GetContext(ctx, db, &balance, db.Rebind(query), memberId); err != nil {
+		log.Error().Err(err).Str("member_id", memberId).Msg("repo::lockBalance - failed to lock balance")
+		return 0, err
+	}
+
+	return balance, nil
+}
+
+// postJournal writes a journal with its two ledger entries and moves the
+// member balance by points. The contra account takes the opposite amount.
+// It returns 0 when the transaction already has a journal of this type.
+func postJournal(ctx context.Context, db sqlx.ExtContext, journalType, memberId string, transactionId *string, points int, description *string, contraAccount string) (int64, error) {
+	var journalId int64
+
+	query := `
+		INSERT INTO points_journals (member_id, type, transaction_id, points, description)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (transaction_id, type) WHERE transaction_id IS NOT NULL DO NOTHING
+		RETURNING id
+	`
+
+	err := sqlx.GetContext(ctx, db, &journalId, db.Rebind(query), memberId, journalType, transactionId, points, description)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		log.Error().Err(err).Str("member_id", memberId).Str("type", journalType).Msg("repo::postJournal - failed to create journal")
+		return 0, err
+	}
+
+	query = `
+		INSERT INTO points_ledger (journal_id, account, member_id, amount)
+		VALUES (?, ?, ?, ?), (?, ?, NULL, ?)
+	`
+
+	_, err = db.ExecContext(ctx, db.Rebind(query),
+		journalId, entity.AccountMember, memberId, points,
+		journalId, contraAccount, -points,
+	)
+	if err != nil {
+		log.Error().Err(err).Int64("journal_id", journalId).Msg("repo::postJournal - failed to create ledger entries")
+		return 0, err
+	}
+
+	query = `UPDATE points_balances SET balance = balance + ?, updated_at = NOW() WHERE member_id = ?`
+	if _, err := db.ExecContext(ctx, db.Rebind(query), points, memberId); err != nil {
+		log.Error().Err(err).Str("member_id", memberId).Msg("repo::postJournal - failed to update balance")
+		return 0, err
+	}
+
+	return journalId, nil
+}
+
+type lot struct {
+	Id        int64 `db:"id"`
+	Remaining int   `db:"remaining"`
+}
+
+// openLots locks the unspent earned lots of a member, oldest first. When
+// before is set only lots earned before it are returned.
+func openLots(ctx context.Context, db sqlx.ExtContext, memberId string, before *time.Time) ([]lot, error) {
+	var (
+		lots = make([]lot, 0)
+		args = []any{memberId}
+	)
+
+	query := `
+		SELECT id, remaining
+		FROM points_journals
+		WHERE
+			member_id = ?
+			AND type = 'earn'
+			AND remaining > 0
+	`
+
+	if before != nil {
+		query += ` AND created_at < ?`
+		args = append(args, *before)
+	}
+
+	query += ` ORDER BY created_at, id FOR UPDATE`
+
+	if err := sqlx.SelectContext(ctx, db, &lots, db.Rebind(query), args...); err != nil {
+		log.Error().Err(err).Str("member_id", memberId).Msg("repo::openLots - failed to get open lots")
+		return nil, err
+	}
+
+	return lots, nil
+}
+
+// consumeLots takes up to points from the lots in order and records where
+// they came from. The remaining of lots is updated in place. It returns the
+// points actually taken.
+func consumeLots(ctx context.Context, db sqlx.ExtContext, journalId int64, lots []lot, points int) (int, error) {
+	takes, short := allocate(lots, points)
+
+	for _, t := range takes {
+		query := `UPDATE points_journals SET remaining = remaining - ? WHERE id = ?`
+		if _, err := db.ExecContext(ctx, db.Rebind(query), t.points, t.lotId); err != nil {
+			log.Error().Err(err).Int64("lot_id", t.lotId).Msg("repo::consumeLots - failed to update lot")
+			return 0, err
+		}
+
+		query = `INSERT INTO points_consumptions (journal_id, earn_journal_id, points) VALUES (?, ?, ?)`
+		if _, err := db.ExecContext(ctx, db.Rebind(query), journalId, t.lotId, t.points); err != nil {
+			log.Error().Err(err).Int64("lot_id", t.lotId).Msg("repo::consumeLots - failed to record consumption")
+			return 0, err
+		}
+	}
+
+	return points - short, nil
+}
+
+type take struct {
+	lotId  int64
+	points int
+}
+
+// allocate takes up to points from the lots in order, lowering their
+// remaining. It returns what was taken from each lot and the part of points
+// the lots could not cover.
+func allocate(lots []lot, points int) ([]take, int) {
+	takes := make([]take, 0)
+
+	for i := range lots {
+		if points == 0 {
+			break
+		}
+		if lots[i].Remaining <= 0 {
+			continue
+		}
+
+		n := min(lots[i].Remaining, points)
+		lots[i].Remaining -= n
+		points -= n
+
+		takes = append(takes, take{lotId: lots[i].Id, points: n})
+	}
+
+	return takes, points
+}
+
+// settleDebts pays the debts of the members of the transactions matching
+// filter, oldest first, from their open lots. It runs right after the earn,
+// so the new lots cover what reversals could not take back before anything
+// else spends them. The balance already carries the debt and does not move.
+func settleDebts(ctx context.Context, db sqlx.ExtContext, filter string, args ...any) error {
+	memberIds := make([]string, 0)
+
+	query := `
+		SELECT DISTINCT d.member_id
+		FROM points_journals d
+		JOIN product_transactions pt ON pt.member_id = d.member_id
+		WHERE d.remaining < 0 AND ` + filter + `
+		ORDER BY d.member_id
+	`
+
+	if err := sqlx.SelectContext(ctx, db, &memberIds, db.Rebind(query), args...); err != nil {
+		log.Error().Err(err).Msg("repo::settleDebts - failed to get members in debt")
+		return err
+	}
+
+	for _, memberId := range memberIds {
+		if _, err := lockBalance(ctx, db, memberId); err != nil {
+			return err
+		}
+
+		debts := make([]lot, 0)
+
+		query := `
+			SELECT id, remaining
+			FROM points_journals
+			WHERE member_id = ? AND remaining < 0
+			ORDER BY created_at, id
+			FOR UPDATE
+		`
+
+		if err := sqlx.SelectContext(ctx, db, &debts, db.Rebind(query), memberId); err != nil {
+			log.Error().Err(err).Str("member_id", memberId).Msg("repo::settleDebts - failed to get debts")
+			return err
+		}
+
+		lots, err := openLots(ctx, db, memberId, nil)
+		if err != nil {
+			return err
+		}
+
+		for _, debt := range debts {
+			paid, err := consumeLots(ctx, db, debt.Id, lots, -debt.Remaining)
+			if err != nil {
+				return err
+			}
+
+			if paid == 0 {
+				break
+			}
+
+			query := `UPDATE points_journals SET remaining = remaining + ? WHERE id = ?`
+			if _, err := db.ExecContext(ctx, db.Rebind(query), paid, debt.Id); err != nil {
+				log.Error().Err(err).Int64("journal_id", debt.Id).Msg("repo::settleDebts - failed to update debt")
+				return err
+			}
+		}
+	}
+
+	return nil
+}
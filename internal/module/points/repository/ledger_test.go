@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func remaining(lots []lot) int {
+	sum := 0
+	for _, l := range lots {
+		sum += l.Remaining
+	}
+	return sum
+}
+
+func TestAllocate(t *testing.T) {
+	lots := []lot{{Id: 1, Remaining: 0}, {Id: 2, Remaining: 30}, {Id: 3, Remaining: 50}}
+
+	takes, short := allocate(lots, 60)
+	assert.Equal(t, []take{{lotId: 2, points: 30}, {lotId: 3, points: 30}}, takes)
+	assert.Zero(t, short)
+	assert.Equal(t, 20, lots[2].Remaining)
+
+	takes, short = allocate(lots, 50)
+	assert.Equal(t, []take{{lotId: 3, points: 20}}, takes)
+	assert.Equal(t, 30, short)
+}
+
+// The lots must always add up to the balance plus the debts, or expiry takes
+// points the member no longer has.
+func TestReverseAfterPartialRedemption(t *testing.T) {
+	var (
+		voided  = lot{Id: 1, Remaining: 100}
+		other   = lot{Id: 2, Remaining: 50}
+		lots    = []lot{voided, other}
+		balance = 150
+	)
+
+	// redeem 120: the voided lot is emptied, 30 is left of the other
+	_, short := allocate(lots, 120)
+	assert.Zero(t, short)
+	balance -= 120
+
+	// void the transaction of the first lot: only 30 can be taken back
+	_, short = allocate(lots, 100)
+	balance -= 100
+	debt := -short
+
+	assert.Equal(t, -70, balance)
+	assert.Equal(t, -70, debt)
+	assert.Zero(t, remaining(lots))
+	assert.Equal(t, balance, remaining(lots)+debt)
+
+	// the next earn of 50 pays off the debt before it can be spent
+	lots = append(lots, lot{Id: 3, Remaining: 50})
+	balance += 50
+
+	_, short = allocate(lots, -debt)
+	debt = -short
+
+	assert.Equal(t, -20, debt)
+	assert.Zero(t, remaining(lots))
+	assert.Equal(t, balance, remaining(lots)+debt)
+
+	// another 50 settles it and leaves 30 to spend
+	lots = append(lots, lot{Id: 4, Remaining: 50})
+	balance += 50
+
+	_, short = allocate(lots, -debt)
+	debt = -short
+
+	assert.Zero(t, debt)
+	assert.Equal(t, 30, remaining(lots))
+	assert.Equal(t, balance, remaining(lots)+debt)
+}
+
+func TestAllocateReversals(t *testing.T) {
+	lots := map[string][]lot{
+		"a": {{Id: 1, Remaining: 10}, {Id: 2, Remaining: 40}},
+		"b": {{Id: 3, Remaining: 5}},
+	}
+	reversals := []reversal{
+		{Id: 10, MemberId: "a", EarnId: 2, Points: 50},
+		{Id: 11, MemberId: "b", EarnId: 4, Points: 20},
+	}
+
+	consumptions, debts := allocateReversals(reversals, lots)
+
+	// the reversed lot goes first, then the oldest others
+	assert.Equal(t, []consumption{
+		{journalId: 10, lotId: 2, points: 40},
+		{journalId: 10, lotId: 1, points: 10},
+		{journalId: 11, lotId: 3, points: 5},
+	}, consumptions)
+	assert.Equal(t, map[int64]int{11: 15}, debts)
+	assert.Zero(t, remaining(lots["a"])+remaining(lots["b"]))
+}
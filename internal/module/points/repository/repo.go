@@ -0,0 +1,239 @@
+package repository
+
+import (
+	"codebase-app/internal/adapter"
+	"codebase-app/internal/module/points/entity"
+	"codebase-app/internal/module/points/ports"
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+var _ ports.PointsRepository = &pointsRepo{}
+
+type pointsRepo struct {
+	db *sqlx.DB
+}
+
+func NewPointsRepository() *pointsRepo {
+	return &pointsRepo{
+		db: adapter.Adapters.Postgres,
+	}
+}
+
+func (r *pointsRepo) IsMemberExist(ctx context.Context, memberId string) (bool, error) {
+	var exist bool
+
+	query := `SELECT EXISTS(SELECT 1 FROM members WHERE id = ?)`
+
+	if err := r.db.GetContext(ctx, &exist, r.db.Rebind(query), memberId); err != nil {
+		log.Error().Err(err).Str("member_id", memberId).Msg("repo::IsMemberExist - failed to check member")
+		return false, err
+	}
+
+	return exist, nil
+}
+
+func (r *pointsRepo) GetMemberPoints(ctx context.Context, memberId string) (*entity.GetMemberPointsResp, error) {
+	res := new(entity.GetMemberPointsResp)
+
+	query := `
+		SELECT
+			m.id AS member_id,
+			COALESCE(pb.balance, 0) AS balance,
+			COALESCE((
+				SELECT SUM(pj.points)
+				FROM points_journals pj
+				WHERE pj.member_id = m.id AND pj.type = 'earn'
+			), 0) AS lifetime_earned,
+			pb.updated_at
+		FROM members m
+		LEFT JOIN points_balances pb ON pb.member_id = m.id
+		WHERE m.id = ?
+	`
+
+	if err := r.db.GetContext(ctx, res, r.db.Rebind(query), memberId); err != nil {
+		log.Error().Err(err).Str("member_id", memberId).Msg("repo::GetMemberPoints - failed to get member points")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (r *pointsRepo) GetMemberPointsHistory(ctx context.Context, req *entity.GetMemberPointsHistoryReq) (*entity.GetMemberPointsHistoryResp, error) {
+	type dao struct {
+		TotalData int `db:"total_data"`
+		entity.Journal
+	}
+
+	var (
+		data = make([]dao, 0)
+		res  = new(entity.GetMemberPointsHistoryResp)
+		args = []any{req.MemberId}
+	)
+	res.Items = make([]entity.Journal, 0)
+
+	// the running balance is taken over every journal before filtering
+	query := `
+		SELECT
+			COUNT(*) OVER() AS total_data,
+			j.id,
+			j.type,
+			j.transaction_id,
+			j.points,
+			j.balance_after,
+			j.description,
+			j.created_at
+		FROM (
+			SELECT
+				pj.id,
+				pj.type,
+				pj.transaction_id,
+				pj.points,
+				SUM(pj.points) OVER (ORDER BY pj.created_at, pj.id) AS balance_after,
+				pj.description,
+				pj.created_at
+			FROM points_journals pj
+			WHERE pj.member_id = ?
+		) AS j
+		WHERE 1 = 1
+	`
+
+	if req.Type != "" {
+		query += ` AND j.type = ?`
+		args = append(args, req.Type)
+	}
+
+	query += ` ORDER BY j.created_at DESC, j.id DESC LIMIT ? OFFSET ?`
+	args = append(args, req.Paginate, (req.Page-1)*req.Paginate)
+
+	if err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), args...); err != nil {
+		log.Error().Err(err).Any("req", req).Msg("repo::GetMemberPointsHistory - failed to get points history")
+		return nil, err
+	}
+
+	for _, d := range data {
+		res.Items = append(res.Items, d.Journal)
+	}
+
+	if len(res.Items) > 0 {
+		res.Meta.TotalData = data[0].TotalData
+	}
+
+	res.Meta.CountTotalPage(req.Page, req.Paginate, res.Meta.TotalData)
+
+	return res, nil
+}
+
+// Redeem spends points of a member, oldest lots first. The balance is checked
+// under the balance row lock, so concurrent redemptions cannot overdraw it.
+func (r *pointsRepo) Redeem(ctx context.Context, req *entity.RedeemPointsReq) (*entity.RedeemPointsResp, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Msg("repo::Redeem - failed to begin transaction")
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	balance, err := lockBalance(ctx, tx, req.MemberId)
+	if err != nil {
+		return nil, err
+	}
+
+	if balance < req.Points {
+		return nil, entity.ErrInsufficientPoints
+	}
+
+	journalId, err := postJournal(ctx, tx, entity.JournalRedeem, req.MemberId, nil, -req.Points, req.Description, entity.AccountRedeemed)
+	if err != nil {
+		return nil, err
+	}
+
+	lots, err := openLots(ctx, tx, req.MemberId, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := consumeLots(ctx, tx, journalId, lots, req.Points); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Msg("repo::Redeem - failed to commit transaction")
+		return nil, err
+	}
+
+	return &entity.RedeemPointsResp{
+		JournalId: journalId,
+		Points:    req.Points,
+		Balance:   balance - req.Points,
+	}, nil
+}
+
+func (r *pointsRepo) GetMembersWithExpiredPoints(ctx context.Context, before time.Time) ([]string, error) {
+	memberIds := make([]string, 0)
+
+	query := `
+		SELECT DISTINCT member_id
+		FROM points_journals
+		WHERE
+			type = 'earn'
+			AND remaining > 0
+			AND created_at < ?
+		ORDER BY member_id
+	`
+
+	if err := r.db.SelectContext(ctx, &memberIds, r.db.Rebind(query), before); err != nil {
+		log.Error().Err(err).Msg("repo::GetMembersWithExpiredPoints - failed to get members")
+		return nil, err
+	}
+
+	return memberIds, nil
+}
+
+// ExpireMemberPoints drops what is left of the lots a member earned before
+// the given time and returns the points expired.
+func (r *pointsRepo) ExpireMemberPoints(ctx context.Context, memberId string, before time.Time) (int, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Msg("repo::ExpireMemberPoints - failed to begin transaction")
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if _, err := lockBalance(ctx, tx, memberId); err != nil {
+		return 0, err
+	}
+
+	lots, err := openLots(ctx, tx, memberId, &before)
+	if err != nil {
+		return 0, err
+	}
+
+	points := 0
+	for _, l := range lots {
+		points += l.Remaining
+	}
+
+	if points == 0 {
+		return 0, nil
+	}
+
+	journalId, err := postJournal(ctx, tx, entity.JournalExpire, memberId, nil, -points, nil, entity.AccountExpired)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := consumeLots(ctx, tx, journalId, lots, points); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Msg("repo::ExpireMemberPoints - failed to commit transaction")
+		return 0, err
+	}
+
+	return points, nil
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"codebase-app/internal/infrastructure/config"
+	"codebase-app/internal/module/points/entity"
+	"codebase-app/internal/module/points/ports"
+	"codebase-app/pkg/errmsg"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+var _ ports.PointsService = &pointsService{}
+
+type pointsService struct {
+	repo ports.PointsRepository
+}
+
+func NewPointsService(repo ports.PointsRepository) *pointsService {
+	return &pointsService{
+		repo: repo,
+	}
+}
+
+func (s *pointsService) GetMemberPoints(ctx context.Context, req *entity.GetMemberPointsReq) (*entity.GetMemberPointsResp, error) {
+	if err := s.checkMember(ctx, req.MemberId); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetMemberPoints(ctx, req.MemberId)
+}
+
+func (s *pointsService) GetMemberPointsHistory(ctx context.Context, req *entity.GetMemberPointsHistoryReq) (*entity.GetMemberPointsHistoryResp, error) {
+	if err := s.checkMember(ctx, req.MemberId); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetMemberPointsHistory(ctx, req)
+}
+
+func (s *pointsService) RedeemPoints(ctx context.Context, req *entity.RedeemPointsReq) (*entity.RedeemPointsResp, error) {
+	if err := s.checkMember(ctx, req.MemberId); err != nil {
+		return nil, err
+	}
+
+	res, err := s.repo.Redeem(ctx, req)
+	if err != nil {
+		if errors.Is(err, entity.ErrInsufficientPoints) {
+			log.Warn().Any("req", req).Msg("service::RedeemPoints - Insufficient points")
+			return nil, errmsg.NewCustomErrors(422).SetMessage("Insufficient points")
+		}
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// ExpirePoints expires the unspent points earned more than the configured
+// number of days ago, one member per database transaction.
+func (s *pointsService) ExpirePoints(ctx context.Context) (*entity.ExpirePointsResult, error) {
+	var (
+		res    = new(entity.ExpirePointsResult)
+		before = time.Now().UTC().AddDate(0, 0, -config.Envs.Points.ExpiryDays)
+	)
+
+	memberIds, err := s.repo.GetMembersWithExpiredPoints(ctx, before)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, memberId := range memberIds {
+		points, err := s.repo.ExpireMemberPoints(ctx, memberId, before)
+		if err != nil {
+			return nil, err
+		}
+
+		if points > 0 {
+			res.Members++
+			res.Points += points
+		}
+	}
+
+	log.Info().Any("result", res).Time("before", before).Msg("service::ExpirePoints - Points expired")
+
+	return res, nil
+}
+
+func (s *pointsService) checkMember(ctx context.Context, memberId string) error {
+	exist, err := s.repo.IsMemberExist(ctx, memberId)
+	if err != nil {
+		return err
+	}
+
+	if !exist {
+		log.Warn().Str("member_id", memberId).Msg("service::checkMember - Member not found")
+		return errmsg.NewCustomErrors(404).SetMessage("Member not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,211 @@
+package entity
+
+import (
+	"codebase-app/pkg/types"
+	"time"
+)
+
+type GetMemberPredictionsReq struct {
+	MemberId string `params:"id" validate:"required"`
+	Limit    int    `query:"limit" validate:"omitempty,min=1,max=20"`
+}
+
+func (r *GetMemberPredictionsReq) SetDefault() {
+	if r.Limit < 1 {
+		r.Limit = 3
+	}
+}
+
+type GetMemberPredictionsResp struct {
+	MemberId     string       `json:"member_id"`
+	ModelVersion *string      `json:"model_version"`
+	HasHistory   bool         `json:"has_history"`
+	Items        []Prediction `json:"items"`
+}
+
+type Prediction struct {
+	ProductId         int64   `json:"product_id"`
+	ProductName       *string `json:"product_name"`
+	ProductGrammageId int64   `json:"product_grammage_id"`
+	GrammageName      *string `json:"grammage_name"`
+	Probability       float64 `json:"probability"`
+	PredictedDate     *string `json:"predicted_date"`
+}
+
+type TrainReq struct {
+	StorageDriver string
+}
+
+// Transaction is the subset of a product transaction the model learns from.
+type Transaction struct {
+	MemberId          string    `db:"member_id"`
+	ProductId         int64     `db:"product_id"`
+	ProductGrammageId int64     `db:"product_grammage_id"`
+	CreatedAt         time.Time `db:"created_at"`
+}
+
+type TransactionFilter struct {
+	TrainingOnly bool
+	From         *time.Time // inclusive
+	Before       *time.Time // exclusive
+}
+
+type ItemName struct {
+	Id   int64   `db:"id"`
+	Name *string `db:"name"`
+}
+
+type EvaluateReq struct {
+	Cutoff        time.Time
+	HorizonDays   int
+	K             int
+	ParamsVersion string // empty means the latest trained model
+}
+
+// Evaluation is a backtest report: a model is refitted on everything before
+// Cutoff and scored on what members bought in the following HorizonDays.
+// It rates the params of a version, not the artifact served under it.
+type Evaluation struct {
+	Id string `db:"id" json:"id"`
+	// ParamsVersion is the model whose params were refitted, nil for the
+	// default params
+	ParamsVersion    *string   `db:"params_version" json:"params_version"`
+	Cutoff           time.Time `db:"cutoff" json:"cutoff"`
+	HorizonDays      int       `db:"horizon_days" json:"horizon_days"`
+	K                int       `db:"k" json:"k"`
+	TrainingRows     int       `db:"training_rows" json:"training_rows"`
+	MembersEvaluated int       `db:"members_evaluated" json:"members_evaluated"`
+	PrecisionAtK     float64   `db:"precision_at_k" json:"precision_at_k"`
+	RecallAtK        float64   `db:"recall_at_k" json:"recall_at_k"`
+	DateMAEDays      *float64  `db:"date_mae_days" json:"date_mae_days"`
+	DateSamples      int       `db:"date_samples" json:"date_samples"`
+	CreatedAt        time.Time `db:"created_at" json:"created_at"`
+}
+
+type GetEvaluationsReq struct {
+	ParamsVersion string `query:"params_version"`
+	Page          int    `query:"page" validate:"required,numeric"`
+	Paginate      int    `query:"paginate" validate:"required,numeric"`
+}
+
+func (r *GetEvaluationsReq) SetDefault() {
+	if r.Page < 1 {
+		r.Page = 1
+	}
+
+	if r.Paginate < 1 {
+		r.Paginate = 10
+	}
+}
+
+type GetEvaluationsResp struct {
+	Items []Evaluation `json:"items"`
+	Meta  types.Meta   `json:"meta"`
+}
+
+type PredictionLog struct {
+	Id                string    `db:"id"`
+	ModelVersion      *string   `db:"model_version"`
+	MemberId          string    `db:"member_id"`
+	Rank              int       `db:"rank"`
+	ProductId         int64     `db:"product_id"`
+	ProductGrammageId int64     `db:"product_grammage_id"`
+	Probability       float64   `db:"probability"`
+	PredictedDate     *string   `db:"predicted_date"`
+	CreatedAt         time.Time `db:"created_at"`
+}
+
+type ComputeAccuracyReq struct {
+	WindowDays  int
+	HorizonDays int
+}
+
+// Accuracy is the live hit rate of one model version over served predictions
+// created between WindowStart and WindowEnd.
+type Accuracy struct {
+	Id           string    `db:"id" json:"id"`
+	ModelVersion *string   `db:"model_version" json:"model_version"`
+	WindowStart  time.Time `db:"window_start" json:"window_start"`
+	WindowEnd    time.Time `db:"window_end" json:"window_end"`
+	Predictions  int       `db:"predictions" json:"predictions"`
+	Hits         int       `db:"hits" json:"hits"`
+	HitRate      float64   `db:"hit_rate" json:"hit_rate"`
+	DateMAEDays  *float64  `db:"date_mae_days" json:"date_mae_days"`
+	DateSamples  int       `db:"date_samples" json:"date_samples"`
+	ComputedAt   time.Time `db:"computed_at" json:"computed_at"`
+}
+
+type GetAccuraciesReq struct {
+	ModelVersion string `query:"model_version"`
+	Page         int    `query:"page" validate:"required,numeric"`
+	Paginate     int    `query:"paginate" validate:"required,numeric"`
+}
+
+func (r *GetAccuraciesReq) SetDefault() {
+	if r.Page < 1 {
+		r.Page = 1
+	}
+
+	if r.Paginate < 1 {
+		r.Paginate = 10
+	}
+}
+
+type GetAccuraciesResp struct {
+	Items []Accuracy `json:"items"`
+	Meta  types.Meta `json:"meta"`
+}
+
+type PredictBatchReq struct {
+	K         int
+	BatchSize int
+}
+
+type PredictBatchResult struct {
+	ModelVersion *string   `json:"model_version"`
+	Members      int       `json:"members"`
+	Predictions  int       `json:"predictions"`
+	Removed      int64     `json:"removed"`
+	GeneratedAt  time.Time `json:"generated_at"`
+}
+
+// MemberPrediction is one materialized row of the nightly batch, ranked per member.
+type MemberPrediction struct {
+	MemberId          string    `db:"member_id" json:"member_id"`
+	City              *string   `db:"city" json:"city"`
+	Rank              int       `db:"rank" json:"rank"`
+	ProductId         int64     `db:"product_id" json:"product_id"`
+	ProductName       *string   `db:"product_name" json:"product_name"`
+	Category          *string   `db:"category" json:"category"`
+	ProductGrammageId int64     `db:"product_grammage_id" json:"product_grammage_id"`
+	GrammageName      *string   `db:"grammage_name" json:"grammage_name"`
+	Probability       float64   `db:"probability" json:"probability"`
+	PredictedDate     *string   `db:"predicted_date" json:"predicted_date"`
+	ModelVersion      *string   `db:"model_version" json:"model_version"`
+	GeneratedAt       time.Time `db:"generated_at" json:"generated_at"`
+}
+
+type GetPredictionsReq struct {
+	PredictedFrom string `query:"predicted_from" validate:"omitempty,datetime=2006-01-02"`
+	PredictedTo   string `query:"predicted_to" validate:"omitempty,datetime=2006-01-02"`
+	Category      string `query:"category"`
+	City          string `query:"city"`
+	ProductId     int64  `query:"product_id" validate:"omitempty,min=1"`
+	Page          int    `query:"page" validate:"required,numeric"`
+	Paginate      int    `query:"paginate" validate:"required,numeric"`
+}
+
+func (r *GetPredictionsReq) SetDefault() {
+	if r.Page < 1 {
+		r.Page = 1
+	}
+
+	if r.Paginate < 1 {
+		r.Paginate = 10
+	}
+}
+
+type GetPredictionsResp struct {
+	Items []MemberPrediction `json:"items"`
+	Meta  types.Meta         `json:"meta"`
+}
@@ -0,0 +1,66 @@
+package entity
+
+import "time"
+
+// Model is a fitted next-purchase model. It is plain data so it can be
+// serialized and shared between the trainer and the server.
+type Model struct {
+	Version             string                    `json:"version"`
+	Params              ModelParams               `json:"params"`
+	TrainedAt           time.Time                 `json:"trained_at"`
+	TrainingRows        int                       `json:"training_rows"`
+	DefaultIntervalDays float64                   `json:"default_interval_days"`
+	Popular             []ItemProfile             `json:"popular"`
+	Members             map[string]*MemberProfile `json:"members"`
+}
+
+type ModelParams struct {
+	HalfLifeDays      float64 `json:"half_life_days"`
+	MaxItemsPerMember int     `json:"max_items_per_member"`
+	MaxPopularItems   int     `json:"max_popular_items"`
+}
+
+func DefaultModelParams() ModelParams {
+	return ModelParams{
+		HalfLifeDays:      90,
+		MaxItemsPerMember: 10,
+		MaxPopularItems:   20,
+	}
+}
+
+// MemberProfile holds what the model learned about a single member.
+type MemberProfile struct {
+	LastPurchaseAt time.Time     `json:"last_purchase_at"`
+	IntervalDays   float64       `json:"interval_days"`
+	Purchases      int           `json:"purchases"`
+	Items          []ItemProfile `json:"items"`
+}
+
+// ItemProfile scores a product and grammage pair, ordered by Score descending.
+type ItemProfile struct {
+	ProductId         int64     `json:"product_id"`
+	ProductGrammageId int64     `json:"product_grammage_id"`
+	Score             float64   `json:"score"`
+	Purchases         int       `json:"purchases"`
+	LastPurchaseAt    time.Time `json:"last_purchase_at"`
+	IntervalDays      float64   `json:"interval_days"`
+}
+
+// ScoredItem is a single model output before it is decorated for the API.
+type ScoredItem struct {
+	ProductId         int64
+	ProductGrammageId int64
+	Probability       float64
+	PredictedAt       *time.Time
+}
+
+// ModelMeta is a row of the models table pointing at a stored artifact.
+type ModelMeta struct {
+	Version       string    `db:"version" json:"version"`
+	ArtifactKey   string    `db:"artifact_key" json:"artifact_key"`
+	StorageDriver string    `db:"storage_driver" json:"storage_driver"`
+	Params        []byte    `db:"params" json:"-"`
+	TrainingRows  int       `db:"training_rows" json:"training_rows"`
+	MemberCount   int       `db:"member_count" json:"member_count"`
+	TrainedAt     time.Time `db:"trained_at" json:"trained_at"`
+}
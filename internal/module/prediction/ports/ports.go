@@ -0,0 +1,46 @@
+package ports
+
+import (
+	"codebase-app/internal/module/prediction/entity"
+	"context"
+	"time"
+)
+
+type PredictionRepository interface {
+	IsMemberExist(ctx context.Context, memberId string) (bool, error)
+	StreamTransactions(ctx context.Context, filter entity.TransactionFilter, fn func(entity.Transaction) error) error
+	GetProductNames(ctx context.Context, ids []int64) (map[int64]*string, error)
+	GetGrammageNames(ctx context.Context, ids []int64) (map[int64]*string, error)
+
+	CreateModel(ctx context.Context, meta *entity.ModelMeta) error
+	GetLatestModel(ctx context.Context) (*entity.ModelMeta, error)
+	GetModel(ctx context.Context, version string) (*entity.ModelMeta, error)
+
+	CreateEvaluation(ctx context.Context, evaluation *entity.Evaluation) error
+	GetEvaluations(ctx context.Context, req *entity.GetEvaluationsReq) (*entity.GetEvaluationsResp, error)
+
+	CreatePredictionLogs(ctx context.Context, logs []entity.PredictionLog) error
+	ComputeAccuracies(ctx context.Context, windowStart, windowEnd time.Time) ([]entity.Accuracy, error)
+	CreateAccuracies(ctx context.Context, accuracies []entity.Accuracy) error
+	GetAccuracies(ctx context.Context, req *entity.GetAccuraciesReq) (*entity.GetAccuraciesResp, error)
+
+	GetMemberIds(ctx context.Context, after string, limit int) ([]string, error)
+	UpsertMemberPredictions(ctx context.Context, predictions []entity.MemberPrediction) error
+	DeleteMemberPredictionsBefore(ctx context.Context, generatedAt time.Time) (int64, error)
+	GetPredictions(ctx context.Context, req *entity.GetPredictionsReq) (*entity.GetPredictionsResp, error)
+}
+
+type PredictionService interface {
+	GetMemberPredictions(ctx context.Context, req *entity.GetMemberPredictionsReq) (*entity.GetMemberPredictionsResp, error)
+
+	Train(ctx context.Context, req *entity.TrainReq) (*entity.ModelMeta, error)
+
+	Evaluate(ctx context.Context, req *entity.EvaluateReq) (*entity.Evaluation, error)
+	GetEvaluations(ctx context.Context, req *entity.GetEvaluationsReq) (*entity.GetEvaluationsResp, error)
+
+	ComputeAccuracy(ctx context.Context, req *entity.ComputeAccuracyReq) ([]entity.Accuracy, error)
+	GetAccuracies(ctx context.Context, req *entity.GetAccuraciesReq) (*entity.GetAccuraciesResp, error)
+
+	PredictBatch(ctx context.Context, req *entity.PredictBatchReq) (*entity.PredictBatchResult, error)
+	GetPredictions(ctx context.Context, req *entity.GetPredictionsReq) (*entity.GetPredictionsResp, error)
+}
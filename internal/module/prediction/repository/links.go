@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"codebase-app/internal/infrastructure/config"
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+// Purchases are linked to the predictions they fulfil on the caller's
+// database transaction, so the link commits with the purchase.
+
+// LinkTransactionPredictions links the transactions matching filter, a
+// condition on product_transactions pt, to every open prediction of the same
+// item served to the member before the purchase and at most the prediction
+// horizon before it.
+func LinkTransactionPredictions(ctx context.Context, db sqlx.ExtContext, filter string, args ...any) error {
+	query := `
+		UPDATE prediction_logs pl
+		SET
+			transaction_id = pt.id,
+			purchased_at = pt.created_at
+		FROM product_transactions pt
+		WHERE
+			` + filter + `
+			AND pl.transaction_id IS NULL
+			AND pl.member_id = pt.member_id
+			AND pl.product_id = pt.product_id
+			AND pl.product_grammage_id = pt.product_grammage_id
+			AND pl.created_at <= pt.created_at
+			AND pt.created_at < pl.created_at + make_interval(days => ?)
+	`
+
+	args = append(args, config.Envs.Prediction.HorizonDays)
+
+	if _, err := db.ExecContext(ctx, db.Rebind(query), args...); err != nil {
+		log.Error().Err(err).Msg("repo::LinkTransactionPredictions - failed to link transactions to predictions")
+		return err
+	}
+
+	return nil
+}
+
+// UnlinkTransactionPredictions reopens the predictions fulfilled by the
+// transactions matching filter, a condition on product_transactions pt.
+func UnlinkTransactionPredictions(ctx context.Context, db sqlx.ExtContext, filter string, args ...any) error {
+	query := `
+		UPDATE prediction_logs
+		SET transaction_id = NULL, purchased_at = NULL
+		WHERE transaction_id IN (SELECT pt.id FROM product_transactions pt WHERE ` + filter + `)
+	`
+
+	if _, err := db.ExecContext(ctx, db.Rebind(query), args...); err != nil {
+		log.Error().Err(err).Msg("repo::UnlinkTransactionPredictions - failed to unlink transactions from predictions")
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,723 @@
+package repository
+
+import (
+	"codebase-app/internal/adapter"
+	"codebase-app/internal/infrastructure/config"
+	"codebase-app/internal/module/prediction/entity"
+	"codebase-app/internal/module/prediction/ports"
+	"codebase-app/pkg/queryspec"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+)
+
+var _ ports.PredictionRepository = &predictionRepo{}
+
+type predictionRepo struct {
+	db *sqlx.DB
+}
+
+func NewPredictionRepository() *predictionRepo {
+	return &predictionRepo{
+		db: adapter.Adapters.Postgres,
+	}
+}
+
+func (r *predictionRepo) IsMemberExist(ctx context.Context, memberId string) (bool, error) {
+	var exist bool
+
+	query := `SELECT EXISTS(SELECT 1 FROM members WHERE id = ?)`
+
+	if err := r.db.GetContext(ctx, &exist, r.db.Rebind(query), memberId); err != nil {
+		log.Error().Err(err).Str("member_id", memberId).Msg("repo::IsMemberExist - failed to check member")
+		return false, err
+	}
+
+	return exist, nil
+}
+
+// StreamTransactions walks transactions ordered by member and time, so the
+// caller can fold one member at a time without holding the whole table.
+func (r *predictionRepo) StreamTransactions(ctx context.Context, filter entity.TransactionFilter, fn func(entity.Transaction) error) error {
+	query := `
+		SELECT
+			pt.member_id,
+			pt.product_id,
+			pt.product_grammage_id,
+			pt.created_at
+		FROM product_transactions pt
+		WHERE
+			pt.created_at IS NOT NULL
+			AND pt.voided_at IS NULL
+			AND pt.product_id IS NOT NULL
+			AND pt.product_grammage_id IS NOT NULL
+	`
+	args := make([]any, 0)
+
+	if filter.TrainingOnly {
+		query += ` AND pt.is_training_data = TRUE`
+	}
+
+	if filter.From != nil {
+		query += ` AND pt.created_at >= ?`
+		args = append(args, *filter.From)
+	}
+
+	if filter.Before != nil {
+		query += ` AND pt.created_at < ?`
+		args = append(args, *filter.Before)
+	}
+
+	query += ` ORDER BY pt.member_id, pt.created_at`
+
+	rows, err := r.db.QueryxContext(ctx, r.db.Rebind(query), args...)
+	if err != nil {
+		log.Error().Err(err).Any("filter", filter).Msg("repo::StreamTransactions - failed to query transactions")
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tx entity.Transaction
+		if err := rows.StructScan(&tx); err != nil {
+			log.Error().Err(err).Msg("repo::StreamTransactions - failed to scan transaction")
+			return err
+		}
+
+		if err := fn(tx); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Error().Err(err).Msg("repo::StreamTransactions - failed to iterate transactions")
+		return err
+	}
+
+	return nil
+}
+
+func (r *predictionRepo) GetProductNames(ctx context.Context, ids []int64) (map[int64]*string, error) {
+	return r.getNames(ctx, "products", ids)
+}
+
+func (r *predictionRepo) GetGrammageNames(ctx context.Context, ids []int64) (map[int64]*string, error) {
+	return r.getNames(ctx, "product_grammages", ids)
+}
+
+func (r *predictionRepo) getNames(ctx context.Context, table string, ids []int64) (map[int64]*string, error) {
+	var (
+		data = make([]entity.ItemName, 0)
+		res  = make(map[int64]*string, len(ids))
+	)
+
+	if len(ids) == 0 {
+		return res, nil
+	}
+
+	query, args, err := sqlx.In(`SELECT id, name FROM `+table+` WHERE id IN (?)`, ids)
+	if err != nil {
+		log.Error().Err(err).Str("table", table).Msg("repo::getNames - failed to build query")
+		return nil, err
+	}
+
+	if err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), args...); err != nil {
+		log.Error().Err(err).Str("table", table).Msg("repo::getNames - failed to get names")
+		return nil, err
+	}
+
+	for _, d := range data {
+		res[d.Id] = d.Name
+	}
+
+	return res, nil
+}
+
+func (r *predictionRepo) CreateModel(ctx context.Context, meta *entity.ModelMeta) error {
+	query := `
+		INSERT INTO models (
+			version,
+			artifact_key,
+			storage_driver,
+			params,
+			training_rows,
+			member_count,
+			trained_at
+		) VALUES (
+			:version,
+			:artifact_key,
+			:storage_driver,
+			:params,
+			:training_rows,
+			:member_count,
+			:trained_at
+		)
+	`
+
+	if _, err := r.db.NamedExecContext(ctx, query, meta); err != nil {
+		log.Error().Err(err).Str("version", meta.Version).Msg("repo::CreateModel - failed to create model")
+		return err
+	}
+
+	return nil
+}
+
+func (r *predictionRepo) GetModel(ctx context.Context, version string) (*entity.ModelMeta, error) {
+	var meta entity.ModelMeta
+
+	query := `
+		SELECT
+			version,
+			artifact_key,
+			storage_driver,
+			params,
+			training_rows,
+			member_count,
+			trained_at
+		FROM models
+		WHERE version = ?
+	`
+
+	if err := r.db.GetContext(ctx, &meta, r.db.Rebind(query), version); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		log.Error().Err(err).Str("version", version).Msg("repo::GetModel - failed to get model")
+		return nil, err
+	}
+
+	return &meta, nil
+}
+
+// GetLatestModel returns the newest trained model, or nil when nothing has been trained yet.
+func (r *predictionRepo) GetLatestModel(ctx context.Context) (*entity.ModelMeta, error) {
+	var meta entity.ModelMeta
+
+	query := `
+		SELECT
+			version,
+			artifact_key,
+			storage_driver,
+			params,
+			training_rows,
+			member_count,
+			trained_at
+		FROM models
+		ORDER BY trained_at DESC
+		LIMIT 1
+	`
+
+	if err := r.db.GetContext(ctx, &meta, query); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		log.Error().Err(err).Msg("repo::GetLatestModel - failed to get latest model")
+		return nil, err
+	}
+
+	return &meta, nil
+}
+
+func (r *predictionRepo) CreateEvaluation(ctx context.Context, evaluation *entity.Evaluation) error {
+	query := `
+		INSERT INTO model_evaluations (
+			id,
+			params_version,
+			cutoff,
+			horizon_days,
+			k,
+			training_rows,
+			members_evaluated,
+			precision_at_k,
+			recall_at_k,
+			date_mae_days,
+			date_samples,
+			created_at
+		) VALUES (
+			:id,
+			:params_version,
+			:cutoff,
+			:horizon_days,
+			:k,
+			:training_rows,
+			:members_evaluated,
+			:precision_at_k,
+			:recall_at_k,
+			:date_mae_days,
+			:date_samples,
+			:created_at
+		)
+	`
+
+	if _, err := r.db.NamedExecContext(ctx, query, evaluation); err != nil {
+		log.Error().Err(err).Any("evaluation", evaluation).Msg("repo::CreateEvaluation - failed to create evaluation")
+		return err
+	}
+
+	return nil
+}
+
+func (r *predictionRepo) GetEvaluations(ctx context.Context, req *entity.GetEvaluationsReq) (*entity.GetEvaluationsResp, error) {
+	type dao struct {
+		TotalData int `db:"total_data"`
+		entity.Evaluation
+	}
+
+	var (
+		data = make([]dao, 0)
+		res  = new(entity.GetEvaluationsResp)
+		args = make([]any, 0)
+	)
+	res.Items = make([]entity.Evaluation, 0)
+
+	query := `
+		SELECT
+			COUNT(*) OVER() AS total_data,
+			me.id,
+			me.params_version,
+			me.cutoff,
+			me.horizon_days,
+			me.k,
+			me.training_rows,
+			me.members_evaluated,
+			me.precision_at_k,
+			me.recall_at_k,
+			me.date_mae_days,
+			me.date_samples,
+			me.created_at
+		FROM model_evaluations me
+		WHERE 1 = 1
+	`
+
+	if req.ParamsVersion != "" {
+		query += ` AND me.params_version = ?`
+		args = append(args, req.ParamsVersion)
+	}
+
+	query += ` ORDER BY me.created_at DESC LIMIT ? OFFSET ?`
+	args = append(args, req.Paginate, (req.Page-1)*req.Paginate)
+
+	if err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), args...); err != nil {
+		log.Error().Err(err).Msg("repo::GetEvaluations - failed to get evaluations")
+		return nil, err
+	}
+
+	for _, d := range data {
+		res.Items = append(res.Items, d.Evaluation)
+	}
+
+	if len(res.Items) > 0 {
+		res.Meta.TotalData = data[0].TotalData
+	}
+
+	res.Meta.CountTotalPage(req.Page, req.Paginate, res.Meta.TotalData)
+
+	return res, nil
+}
+
+// CreatePredictionLogs logs the predictions served to a member. A prediction
+// still open for the member under the same model version, neither fulfilled
+// nor past the horizon, is skipped, so repeated requests are counted once.
+func (r *predictionRepo) CreatePredictionLogs(ctx context.Context, logs []entity.PredictionLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+
+	var (
+		ids            = make([]string, 0, len(logs))
+		modelVersions  = make([]string, 0, len(logs))
+		memberIds      = make([]string, 0, len(logs))
+		ranks          = make([]int64, 0, len(logs))
+		productIds     = make([]int64, 0, len(logs))
+		grammageIds    = make([]int64, 0, len(logs))
+		probabilities  = make([]float64, 0, len(logs))
+		predictedDates = make([]string, 0, len(logs))
+		createdAts     = make([]time.Time, 0, len(logs))
+	)
+
+	// NULLs travel as empty strings, the arrays cannot hold them
+	for _, l := range logs {
+		ids = append(ids, l.Id)
+		modelVersions = append(modelVersions, stringOrEmpty(l.ModelVersion))
+		memberIds = append(memberIds, l.MemberId)
+		ranks = append(ranks, int64(l.Rank))
+		productIds = append(productIds, l.ProductId)
+		grammageIds = append(grammageIds, l.ProductGrammageId)
+		probabilities = append(probabilities, l.Probability)
+		predictedDates = append(predictedDates, stringOrEmpty(l.PredictedDate))
+		createdAts = append(createdAts, l.CreatedAt)
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Msg("repo::CreatePredictionLogs - failed to begin transaction")
+		return err
+	}
+	defer tx.Rollback()
+
+	// concurrent requests of a member would both see the prediction missing
+	query := `
+		SELECT pg_advisory_xact_lock(hashtext('prediction_logs:' || m.member_id))
+		FROM (SELECT DISTINCT unnest(?::TEXT[]) AS member_id ORDER BY 1) m
+	`
+
+	if _, err := tx.ExecContext(ctx, tx.Rebind(query), pq.Array(memberIds)); err != nil {
+		log.Error().Err(err).Msg("repo::CreatePredictionLogs - failed to lock members")
+		return err
+	}
+
+	query = `
+		INSERT INTO prediction_logs (
+			id,
+			model_version,
+			member_id,
+			rank,
+			product_id,
+			product_grammage_id,
+			probability,
+			predicted_date,
+			created_at
+		)
+		SELECT
+			l.id,
+			NULLIF(l.model_version, ''),
+			l.member_id,
+			l.rank,
+			l.product_id,
+			l.product_grammage_id,
+			l.probability,
+			NULLIF(l.predicted_date, '')::DATE,
+			l.created_at
+		FROM unnest(
+			?::TEXT[], ?::TEXT[], ?::TEXT[], ?::INT[], ?::BIGINT[], ?::BIGINT[], ?::DOUBLE PRECISION[], ?::TEXT[], ?::TIMESTAMPTZ[]
+		) AS l(id, model_version, member_id, rank, product_id, product_grammage_id, probability, predicted_date, created_at)
+		WHERE NOT EXISTS (
+			SELECT 1
+			FROM prediction_logs pl
+			WHERE
+				pl.transaction_id IS NULL
+				AND pl.member_id = l.member_id
+				AND pl.product_id = l.product_id
+				AND pl.product_grammage_id = l.product_grammage_id
+				AND pl.model_version IS NOT DISTINCT FROM NULLIF(l.model_version, '')
+				AND pl.created_at > l.created_at - make_interval(days => ?)
+		)
+	`
+
+	args := []any{
+		pq.Array(ids),
+		pq.Array(modelVersions),
+		pq.Array(memberIds),
+		pq.Array(ranks),
+		pq.Array(productIds),
+		pq.Array(grammageIds),
+		pq.Array(probabilities),
+		pq.Array(predictedDates),
+		pq.Array(createdAts),
+		config.Envs.Prediction.HorizonDays,
+	}
+
+	if _, err := tx.ExecContext(ctx, tx.Rebind(query), args...); err != nil {
+		log.Error().Err(err).Any("logs", logs).Msg("repo::CreatePredictionLogs - failed to create prediction logs")
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Msg("repo::CreatePredictionLogs - failed to commit transaction")
+		return err
+	}
+
+	return nil
+}
+
+func stringOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// ComputeAccuracies aggregates the prediction logs created inside the window per model version.
+// A logged prediction is a hit once a live transaction was linked to it.
+func (r *predictionRepo) ComputeAccuracies(ctx context.Context, windowStart, windowEnd time.Time) ([]entity.Accuracy, error) {
+	data := make([]entity.Accuracy, 0)
+
+	query := `
+		SELECT
+			pl.model_version,
+			COUNT(*) AS predictions,
+			COUNT(pl.transaction_id) AS hits,
+			COUNT(pl.transaction_id)::DOUBLE PRECISION / COUNT(*) AS hit_rate,
+			AVG(ABS(pl.purchased_at::DATE - pl.predicted_date))
+				FILTER (WHERE pl.transaction_id IS NOT NULL AND pl.predicted_date IS NOT NULL)::DOUBLE PRECISION AS date_mae_days,
+			COUNT(*) FILTER (WHERE pl.transaction_id IS NOT NULL AND pl.predicted_date IS NOT NULL) AS date_samples
+		FROM prediction_logs pl
+		WHERE
+			pl.created_at >= ?
+			AND pl.created_at < ?
+		GROUP BY pl.model_version
+	`
+
+	if err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), windowStart, windowEnd); err != nil {
+		log.Error().Err(err).Msg("repo::ComputeAccuracies - failed to compute accuracies")
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func (r *predictionRepo) CreateAccuracies(ctx context.Context, accuracies []entity.Accuracy) error {
+	if len(accuracies) == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO prediction_accuracies (
+			id,
+			model_version,
+			window_start,
+			window_end,
+			predictions,
+			hits,
+			hit_rate,
+			date_mae_days,
+			date_samples,
+			computed_at
+		) VALUES (
+			:id,
+			:model_version,
+			:window_start,
+			:window_end,
+			:predictions,
+			:hits,
+			:hit_rate,
+			:date_mae_days,
+			:date_samples,
+			:computed_at
+		)
+	`
+
+	if _, err := r.db.NamedExecContext(ctx, query, accuracies); err != nil {
+		log.Error().Err(err).Msg("repo::CreateAccuracies - failed to create accuracies")
+		return err
+	}
+
+	return nil
+}
+
+func (r *predictionRepo) GetAccuracies(ctx context.Context, req *entity.GetAccuraciesReq) (*entity.GetAccuraciesResp, error) {
+	type dao struct {
+		TotalData int `db:"total_data"`
+		entity.Accuracy
+	}
+
+	var (
+		data = make([]dao, 0)
+		res  = new(entity.GetAccuraciesResp)
+		args = make([]any, 0)
+	)
+	res.Items = make([]entity.Accuracy, 0)
+
+	query := `
+		SELECT
+			COUNT(*) OVER() AS total_data,
+			pa.id,
+			pa.model_version,
+			pa.window_start,
+			pa.window_end,
+			pa.predictions,
+			pa.hits,
+			pa.hit_rate,
+			pa.date_mae_days,
+			pa.date_samples,
+			pa.computed_at
+		FROM prediction_accuracies pa
+		WHERE 1 = 1
+	`
+
+	if req.ModelVersion != "" {
+		query += ` AND pa.model_version = ?`
+		args = append(args, req.ModelVersion)
+	}
+
+	query += ` ORDER BY pa.computed_at DESC LIMIT ? OFFSET ?`
+	args = append(args, req.Paginate, (req.Page-1)*req.Paginate)
+
+	if err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), args...); err != nil {
+		log.Error().Err(err).Msg("repo::GetAccuracies - failed to get accuracies")
+		return nil, err
+	}
+
+	for _, d := range data {
+		res.Items = append(res.Items, d.Accuracy)
+	}
+
+	if len(res.Items) > 0 {
+		res.Meta.TotalData = data[0].TotalData
+	}
+
+	res.Meta.CountTotalPage(req.Page, req.Paginate, res.Meta.TotalData)
+
+	return res, nil
+}
+
+// GetMemberIds pages through members by id, so the batch never skips or
+// repeats a member when rows are added while it runs.
+func (r *predictionRepo) GetMemberIds(ctx context.Context, after string, limit int) ([]string, error) {
+	ids := make([]string, 0, limit)
+
+	query := `SELECT id FROM members WHERE id > ? ORDER BY id LIMIT ?`
+
+	if err := r.db.SelectContext(ctx, &ids, r.db.Rebind(query), after, limit); err != nil {
+		log.Error().Err(err).Str("after", after).Msg("repo::GetMemberIds - failed to get member ids")
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// upsertChunkSize keeps a single upsert well under the 65535 bind parameters postgres accepts.
+const upsertChunkSize = 1000
+
+func (r *predictionRepo) UpsertMemberPredictions(ctx context.Context, predictions []entity.MemberPrediction) error {
+	query := `
+		INSERT INTO member_predictions (
+			member_id,
+			rank,
+			product_id,
+			product_grammage_id,
+			probability,
+			predicted_date,
+			model_version,
+			generated_at
+		) VALUES (
+			:member_id,
+			:rank,
+			:product_id,
+			:product_grammage_id,
+			:probability,
+			:predicted_date,
+			:model_version,
+			:generated_at
+		)
+		ON CONFLICT (member_id, rank) DO UPDATE SET
+			product_id = EXCLUDED.product_id,
+			product_grammage_id = EXCLUDED.product_grammage_id,
+			probability = EXCLUDED.probability,
+			predicted_date = EXCLUDED.predicted_date,
+			model_version = EXCLUDED.model_version,
+			generated_at = EXCLUDED.generated_at
+	`
+
+	for start := 0; start < len(predictions); start += upsertChunkSize {
+		end := min(start+upsertChunkSize, len(predictions))
+
+		if _, err := r.db.NamedExecContext(ctx, query, predictions[start:end]); err != nil {
+			log.Error().Err(err).Int("rows", end-start).Msg("repo::UpsertMemberPredictions - failed to upsert member predictions")
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeleteMemberPredictionsBefore removes rows the latest batch did not rewrite,
+// such as ranks a member no longer fills or members that were deleted.
+func (r *predictionRepo) DeleteMemberPredictionsBefore(ctx context.Context, generatedAt time.Time) (int64, error) {
+	query := `DELETE FROM member_predictions WHERE generated_at < ?`
+
+	result, err := r.db.ExecContext(ctx, r.db.Rebind(query), generatedAt)
+	if err != nil {
+		log.Error().Err(err).Time("generated_at", generatedAt).Msg("repo::DeleteMemberPredictionsBefore - failed to delete member predictions")
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func (r *predictionRepo) GetPredictions(ctx context.Context, req *entity.GetPredictionsReq) (*entity.GetPredictionsResp, error) {
+	type dao struct {
+		TotalData int `db:"total_data"`
+		entity.MemberPrediction
+	}
+
+	var (
+		data = make([]dao, 0)
+		res  = new(entity.GetPredictionsResp)
+		args = make([]any, 0)
+	)
+	res.Items = make([]entity.MemberPrediction, 0)
+
+	query := `
+		SELECT
+			COUNT(*) OVER() AS total_data,
+			mp.member_id,
+			m.city,
+			mp.rank,
+			mp.product_id,
+			p.name AS product_name,
+			p.category,
+			mp.product_grammage_id,
+			pg.name AS grammage_name,
+			mp.probability,
+			TO_CHAR(mp.predicted_date, 'YYYY-MM-DD') AS predicted_date,
+			mp.model_version,
+			mp.generated_at
+		FROM member_predictions mp
+		JOIN members m ON m.id = mp.member_id
+		LEFT JOIN products p ON p.id = mp.product_id
+		LEFT JOIN product_grammages pg ON pg.id = mp.product_grammage_id
+		WHERE 1 = 1
+	`
+
+	if req.PredictedFrom != "" {
+		query += ` AND mp.predicted_date >= ?`
+		args = append(args, req.PredictedFrom)
+	}
+
+	if req.PredictedTo != "" {
+		query += ` AND mp.predicted_date <= ?`
+		args = append(args, req.PredictedTo)
+	}
+
+	if req.Category != "" {
+		query += ` AND p.category = ?`
+		args = append(args, req.Category)
+	}
+
+	if req.City != "" {
+		// the city matches whole, ignoring case
+		query += ` AND m.city ILIKE ?`
+		args = append(args, queryspec.EscapeLike(req.City))
+	}
+
+	if req.ProductId != 0 {
+		query += ` AND mp.product_id = ?`
+		args = append(args, req.ProductId)
+	}
+
+	query += ` ORDER BY mp.predicted_date ASC NULLS LAST, mp.member_id, mp.rank LIMIT ? OFFSET ?`
+	args = append(args, req.Paginate, (req.Page-1)*req.Paginate)
+
+	if err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), args...); err != nil {
+		log.Error().Err(err).Any("req", req).Msg("repo::GetPredictions - failed to get predictions")
+		return nil, err
+	}
+
+	for _, d := range data {
+		res.Items = append(res.Items, d.MemberPrediction)
+	}
+
+	if len(res.Items) > 0 {
+		res.Meta.TotalData = data[0].TotalData
+	}
+
+	res.Meta.CountTotalPage(req.Page, req.Paginate, res.Meta.TotalData)
+
+	return res, nil
+}
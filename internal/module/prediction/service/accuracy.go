@@ -0,0 +1,67 @@
+package service
+
+import (
+	"codebase-app/internal/module/prediction/entity"
+	"context"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/rs/zerolog/log"
+)
+
+func predictionLogs(memberId string, modelVersion *string, items []entity.Prediction) []entity.PredictionLog {
+	var (
+		now  = time.Now().UTC()
+		logs = make([]entity.PredictionLog, 0, len(items))
+	)
+
+	for i, item := range items {
+		logs = append(logs, entity.PredictionLog{
+			Id:                ulid.Make().String(),
+			ModelVersion:      modelVersion,
+			MemberId:          memberId,
+			Rank:              i + 1,
+			ProductId:         item.ProductId,
+			ProductGrammageId: item.ProductGrammageId,
+			Probability:       item.Probability,
+			PredictedDate:     item.PredictedDate,
+			CreatedAt:         now,
+		})
+	}
+
+	return logs
+}
+
+// ComputeAccuracy scores the predictions served in a rolling window that
+// ends HorizonDays ago, so every prediction had time to be purchased.
+func (s *predictionService) ComputeAccuracy(ctx context.Context, req *entity.ComputeAccuracyReq) ([]entity.Accuracy, error) {
+	var (
+		now         = time.Now().UTC()
+		windowEnd   = now.AddDate(0, 0, -req.HorizonDays)
+		windowStart = windowEnd.AddDate(0, 0, -req.WindowDays)
+	)
+
+	accuracies, err := s.repo.ComputeAccuracies(ctx, windowStart, windowEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range accuracies {
+		accuracies[i].Id = ulid.Make().String()
+		accuracies[i].WindowStart = windowStart
+		accuracies[i].WindowEnd = windowEnd
+		accuracies[i].ComputedAt = now
+	}
+
+	if err := s.repo.CreateAccuracies(ctx, accuracies); err != nil {
+		return nil, err
+	}
+
+	log.Info().Int("versions", len(accuracies)).Time("window_start", windowStart).Time("window_end", windowEnd).Msg("service::ComputeAccuracy - Accuracy computed")
+
+	return accuracies, nil
+}
+
+func (s *predictionService) GetAccuracies(ctx context.Context, req *entity.GetAccuraciesReq) (*entity.GetAccuraciesResp, error) {
+	return s.repo.GetAccuracies(ctx, req)
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"codebase-app/internal/module/prediction/entity"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+)
+
+const artifactPrefix = "models/next_purchase/"
+
+func artifactKey(version string) string {
+	return artifactPrefix + version + ".json.gz"
+}
+
+// encodeModel writes the model as gzipped JSON.
+func encodeModel(w io.Writer, model *entity.Model) error {
+	zw := gzip.NewWriter(w)
+
+	if err := json.NewEncoder(zw).Encode(model); err != nil {
+		zw.Close()
+		return err
+	}
+
+	return zw.Close()
+}
+
+func decodeModel(r io.Reader) (*entity.Model, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	model := new(entity.Model)
+	if err := json.NewDecoder(zr).Decode(model); err != nil {
+		return nil, err
+	}
+
+	return model, nil
+}
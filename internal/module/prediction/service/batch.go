@@ -0,0 +1,85 @@
+package service
+
+import (
+	"codebase-app/internal/module/prediction/entity"
+	"context"
+	"math"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// PredictBatch scores every member with the current model and upserts the
+// results into member_predictions. Rows left over from earlier runs are
+// removed once every member has been written.
+func (s *predictionService) PredictBatch(ctx context.Context, req *entity.PredictBatchReq) (*entity.PredictBatchResult, error) {
+	model, err := s.getModel(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &entity.PredictBatchResult{
+		GeneratedAt: time.Now().UTC(),
+	}
+
+	if model.Version != "" {
+		res.ModelVersion = &model.Version
+	}
+
+	after := ""
+	for {
+		memberIds, err := s.repo.GetMemberIds(ctx, after, req.BatchSize)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(memberIds) == 0 {
+			break
+		}
+
+		predictions := make([]entity.MemberPrediction, 0, len(memberIds)*req.K)
+		for _, memberId := range memberIds {
+			for i, item := range predict(model, memberId, req.K) {
+				prediction := entity.MemberPrediction{
+					MemberId:          memberId,
+					Rank:              i + 1,
+					ProductId:         item.ProductId,
+					ProductGrammageId: item.ProductGrammageId,
+					Probability:       math.Round(item.Probability*10000) / 10000,
+					ModelVersion:      res.ModelVersion,
+					GeneratedAt:       res.GeneratedAt,
+				}
+
+				if item.PredictedAt != nil {
+					date := item.PredictedAt.Format(time.DateOnly)
+					prediction.PredictedDate = &date
+				}
+
+				predictions = append(predictions, prediction)
+			}
+		}
+
+		if err := s.repo.UpsertMemberPredictions(ctx, predictions); err != nil {
+			return nil, err
+		}
+
+		res.Members += len(memberIds)
+		res.Predictions += len(predictions)
+		after = memberIds[len(memberIds)-1]
+
+		log.Debug().Int("members", res.Members).Msg("service::PredictBatch - Batch written")
+	}
+
+	res.Removed, err = s.repo.DeleteMemberPredictionsBefore(ctx, res.GeneratedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().Any("result", res).Msg("service::PredictBatch - Predictions materialized")
+
+	return res, nil
+}
+
+func (s *predictionService) GetPredictions(ctx context.Context, req *entity.GetPredictionsReq) (*entity.GetPredictionsResp, error) {
+	return s.repo.GetPredictions(ctx, req)
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"codebase-app/internal/module/prediction/entity"
+	"codebase-app/pkg/errmsg"
+	"context"
+	"encoding/json"
+	"math"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/rs/zerolog/log"
+)
+
+// outcome is what a member actually bought inside the holdout window,
+// keyed by item with the first purchase time of each.
+type outcome map[itemKey]time.Time
+
+type holdoutScore struct {
+	members      int
+	precisionSum float64
+	recallSum    float64
+	dateErrorSum float64
+	dateSamples  int
+}
+
+// scoreHoldout compares the top k predictions of every member with what
+// they bought. Precision and recall are averaged per member; the date error
+// only counts predicted items that were actually bought and had a date.
+func scoreHoldout(model *entity.Model, outcomes map[string]outcome, k int) holdoutScore {
+	var score holdoutScore
+
+	for memberId, bought := range outcomes {
+		if len(bought) == 0 {
+			continue
+		}
+
+		hits := 0
+		for _, item := range predict(model, memberId, k) {
+			firstAt, ok := bought[itemKey{item.ProductId, item.ProductGrammageId}]
+			if !ok {
+				continue
+			}
+			hits++
+
+			if item.PredictedAt != nil {
+				score.dateErrorSum += math.Abs(firstAt.Sub(*item.PredictedAt).Hours() / 24)
+				score.dateSamples++
+			}
+		}
+
+		score.members++
+		score.precisionSum += float64(hits) / float64(k)
+		score.recallSum += float64(hits) / float64(len(bought))
+	}
+
+	return score
+}
+
+// Evaluate backtests the params of a trained model, or the default params
+// before any training, by refitting them on the rows before the cutoff.
+func (s *predictionService) Evaluate(ctx context.Context, req *entity.EvaluateReq) (*entity.Evaluation, error) {
+	var (
+		params = entity.DefaultModelParams()
+		meta   *entity.ModelMeta
+		err    error
+	)
+
+	if req.ParamsVersion != "" {
+		meta, err = s.repo.GetModel(ctx, req.ParamsVersion)
+	} else {
+		meta, err = s.repo.GetLatestModel(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if meta == nil && req.ParamsVersion != "" {
+		log.Warn().Any("req", req).Msg("service::Evaluate - Model not found")
+		return nil, errmsg.NewCustomErrors(404).SetMessage("Model not found")
+	}
+
+	if meta != nil && len(meta.Params) > 0 {
+		if err := json.Unmarshal(meta.Params, &params); err != nil {
+			log.Error().Err(err).Any("model", meta).Msg("service::Evaluate - Failed to decode model params")
+			return nil, err
+		}
+	}
+
+	cutoff := req.Cutoff.UTC()
+	horizonEnd := cutoff.AddDate(0, 0, req.HorizonDays)
+
+	// the model only sees what happened before the cutoff, whatever its origin
+	model, err := s.fit(ctx, entity.TransactionFilter{Before: &cutoff}, params)
+	if err != nil {
+		return nil, err
+	}
+
+	outcomes := make(map[string]outcome)
+	err = s.repo.StreamTransactions(ctx, entity.TransactionFilter{From: &cutoff, Before: &horizonEnd}, func(tx entity.Transaction) error {
+		bought, ok := outcomes[tx.MemberId]
+		if !ok {
+			bought = make(outcome)
+			outcomes[tx.MemberId] = bought
+		}
+
+		key := itemKey{tx.ProductId, tx.ProductGrammageId}
+		if _, seen := bought[key]; !seen {
+			bought[key] = tx.CreatedAt
+		}
+
+		return nil
+	})
+	if err != nil {
+		log.Error().Err(err).Any("req", req).Msg("service::Evaluate - Failed to read holdout transactions")
+		return nil, err
+	}
+
+	score := scoreHoldout(model, outcomes, req.K)
+
+	evaluation := &entity.Evaluation{
+		Id:               ulid.Make().String(),
+		Cutoff:           cutoff,
+		HorizonDays:      req.HorizonDays,
+		K:                req.K,
+		TrainingRows:     model.TrainingRows,
+		MembersEvaluated: score.members,
+		DateSamples:      score.dateSamples,
+		CreatedAt:        time.Now().UTC(),
+	}
+
+	// the refit is not the artifact of the version, only its params are
+	if meta != nil {
+		evaluation.ParamsVersion = &meta.Version
+	}
+
+	if score.members > 0 {
+		evaluation.PrecisionAtK = score.precisionSum / float64(score.members)
+		evaluation.RecallAtK = score.recallSum / float64(score.members)
+	}
+
+	if score.dateSamples > 0 {
+		mae := score.dateErrorSum / float64(score.dateSamples)
+		evaluation.DateMAEDays = &mae
+	}
+
+	if err := s.repo.CreateEvaluation(ctx, evaluation); err != nil {
+		return nil, err
+	}
+
+	return evaluation, nil
+}
+
+func (s *predictionService) GetEvaluations(ctx context.Context, req *entity.GetEvaluationsReq) (*entity.GetEvaluationsResp, error) {
+	return s.repo.GetEvaluations(ctx, req)
+}
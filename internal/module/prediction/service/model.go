@@ -0,0 +1,262 @@
+package service
+
+import (
+	"codebase-app/internal/module/prediction/entity"
+	"math"
+	"sort"
+	"time"
+)
+
+const day = 24 * time.Hour
+
+type itemKey struct {
+	productId         int64
+	productGrammageId int64
+}
+
+// modelBuilder fits a model from transactions ordered by member and time.
+// Only the current member's rows are buffered; everything else is folded
+// into per-member profiles and global counters as soon as the member changes.
+type modelBuilder struct {
+	params    entity.ModelParams
+	rows      int
+	members   map[string]*entity.MemberProfile
+	popular   map[itemKey]int
+	intervals map[int]int // histogram of repurchase intervals in days
+
+	current string
+	buffer  []entity.Transaction
+}
+
+func newModelBuilder(params entity.ModelParams) *modelBuilder {
+	return &modelBuilder{
+		params:    params,
+		members:   make(map[string]*entity.MemberProfile),
+		popular:   make(map[itemKey]int),
+		intervals: make(map[int]int),
+	}
+}
+
+func (b *modelBuilder) Add(tx entity.Transaction) error {
+	if tx.MemberId != b.current {
+		b.flush()
+		b.current = tx.MemberId
+	}
+
+	b.buffer = append(b.buffer, tx)
+	b.rows++
+
+	return nil
+}
+
+func (b *modelBuilder) Build(trainedAt time.Time) *entity.Model {
+	b.flush()
+
+	model := &entity.Model{
+		Params:              b.params,
+		TrainedAt:           trainedAt,
+		TrainingRows:        b.rows,
+		DefaultIntervalDays: medianOfHistogram(b.intervals),
+		Members:             b.members,
+	}
+
+	total := 0
+	for _, count := range b.popular {
+		total += count
+	}
+
+	for key, count := range b.popular {
+		model.Popular = append(model.Popular, entity.ItemProfile{
+			ProductId:         key.productId,
+			ProductGrammageId: key.productGrammageId,
+			Score:             float64(count) / float64(total),
+			Purchases:         count,
+		})
+	}
+	sortItems(model.Popular)
+
+	if len(model.Popular) > b.params.MaxPopularItems {
+		model.Popular = model.Popular[:b.params.MaxPopularItems]
+	}
+
+	return model
+}
+
+func (b *modelBuilder) flush() {
+	if len(b.buffer) == 0 {
+		return
+	}
+
+	var (
+		txs      = b.buffer
+		lastAt   = txs[len(txs)-1].CreatedAt
+		scores   = make(map[itemKey]float64)
+		days     = make(map[itemKey][]time.Time)
+		allDays  = make([]time.Time, 0, len(txs))
+		halfLife = b.params.HalfLifeDays
+	)
+
+	for _, tx := range txs {
+		key := itemKey{tx.ProductId, tx.ProductGrammageId}
+
+		// recent purchases weigh more, halving every HalfLifeDays
+		age := lastAt.Sub(tx.CreatedAt).Hours() / 24
+		scores[key] += math.Pow(0.5, age/halfLife)
+
+		days[key] = appendDay(days[key], tx.CreatedAt)
+		allDays = appendDay(allDays, tx.CreatedAt)
+		b.popular[key]++
+	}
+
+	memberIntervals := intervalsOf(allDays)
+	for _, interval := range memberIntervals {
+		b.intervals[int(math.Round(interval))]++
+	}
+
+	profile := &entity.MemberProfile{
+		LastPurchaseAt: lastAt,
+		IntervalDays:   median(memberIntervals),
+		Purchases:      len(allDays),
+	}
+
+	total := 0.0
+	for _, score := range scores {
+		total += score
+	}
+
+	for key, score := range scores {
+		itemDays := days[key]
+		profile.Items = append(profile.Items, entity.ItemProfile{
+			ProductId:         key.productId,
+			ProductGrammageId: key.productGrammageId,
+			Score:             score / total,
+			Purchases:         len(itemDays),
+			LastPurchaseAt:    itemDays[len(itemDays)-1],
+			IntervalDays:      median(intervalsOf(itemDays)),
+		})
+	}
+	sortItems(profile.Items)
+
+	if len(profile.Items) > b.params.MaxItemsPerMember {
+		profile.Items = profile.Items[:b.params.MaxItemsPerMember]
+	}
+
+	b.members[b.current] = profile
+	b.buffer = b.buffer[:0]
+}
+
+// predict returns the k most likely next purchases of a member. Members the
+// model has never seen get the globally popular items without a date.
+func predict(model *entity.Model, memberId string, k int) []entity.ScoredItem {
+	res := make([]entity.ScoredItem, 0, k)
+
+	profile, ok := model.Members[memberId]
+	if !ok {
+		for i := 0; i < len(model.Popular) && i < k; i++ {
+			item := model.Popular[i]
+			res = append(res, entity.ScoredItem{
+				ProductId:         item.ProductId,
+				ProductGrammageId: item.ProductGrammageId,
+				Probability:       item.Score,
+			})
+		}
+		return res
+	}
+
+	for i := 0; i < len(profile.Items) && i < k; i++ {
+		item := profile.Items[i]
+		scored := entity.ScoredItem{
+			ProductId:         item.ProductId,
+			ProductGrammageId: item.ProductGrammageId,
+			Probability:       item.Score,
+		}
+
+		interval := item.IntervalDays
+		if interval == 0 {
+			interval = profile.IntervalDays
+		}
+		if interval == 0 {
+			interval = model.DefaultIntervalDays
+		}
+
+		if interval > 0 {
+			at := item.LastPurchaseAt.Add(time.Duration(interval * float64(day)))
+			scored.PredictedAt = &at
+		}
+
+		res = append(res, scored)
+	}
+
+	return res
+}
+
+// appendDay adds t truncated to the day unless the slice already ends on that day.
+func appendDay(days []time.Time, t time.Time) []time.Time {
+	d := t.UTC().Truncate(day)
+	if len(days) > 0 && days[len(days)-1].Equal(d) {
+		return days
+	}
+	return append(days, d)
+}
+
+func intervalsOf(days []time.Time) []float64 {
+	res := make([]float64, 0, len(days))
+	for i := 1; i < len(days); i++ {
+		res = append(res, days[i].Sub(days[i-1]).Hours()/24)
+	}
+	return res
+}
+
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+func medianOfHistogram(histogram map[int]int) float64 {
+	var (
+		keys  = make([]int, 0, len(histogram))
+		total = 0
+	)
+
+	for k, count := range histogram {
+		keys = append(keys, k)
+		total += count
+	}
+
+	if total == 0 {
+		return 0
+	}
+	sort.Ints(keys)
+
+	seen := 0
+	for _, k := range keys {
+		seen += histogram[k]
+		if seen*2 >= total {
+			return float64(k)
+		}
+	}
+
+	return float64(keys[len(keys)-1])
+}
+
+func sortItems(items []entity.ItemProfile) {
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Score != items[j].Score {
+			return items[i].Score > items[j].Score
+		}
+		if items[i].ProductId != items[j].ProductId {
+			return items[i].ProductId < items[j].ProductId
+		}
+		return items[i].ProductGrammageId < items[j].ProductGrammageId
+	})
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"codebase-app/internal/module/prediction/entity"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func at(date string) time.Time {
+	t, _ := time.Parse(time.DateOnly, date)
+	return t
+}
+
+func fitFixture() *entity.Model {
+	builder := newModelBuilder(entity.DefaultModelParams())
+
+	txs := []entity.Transaction{
+		{MemberId: "A", ProductId: 1, ProductGrammageId: 10, CreatedAt: at("2024-01-01")},
+		{MemberId: "A", ProductId: 1, ProductGrammageId: 10, CreatedAt: at("2024-01-31")},
+		{MemberId: "A", ProductId: 2, ProductGrammageId: 20, CreatedAt: at("2024-02-10")},
+		{MemberId: "A", ProductId: 1, ProductGrammageId: 10, CreatedAt: at("2024-03-01")},
+		{MemberId: "B", ProductId: 2, ProductGrammageId: 20, CreatedAt: at("2024-02-01")},
+	}
+	for _, tx := range txs {
+		_ = builder.Add(tx)
+	}
+
+	return builder.Build(at("2024-03-02"))
+}
+
+func TestFitModel(t *testing.T) {
+	model := fitFixture()
+
+	assert.Equal(t, 5, model.TrainingRows)
+	assert.Len(t, model.Members, 2)
+
+	a := model.Members["A"]
+	assert.Equal(t, at("2024-03-01"), a.LastPurchaseAt)
+	assert.Equal(t, 4, a.Purchases)
+	assert.Equal(t, int64(1), a.Items[0].ProductId)
+	assert.Equal(t, 2, len(a.Items))
+	assert.Equal(t, 30.0, a.Items[0].IntervalDays)
+	assert.InDelta(t, 1.0, a.Items[0].Score+a.Items[1].Score, 1e-9)
+
+	assert.Equal(t, int64(1), model.Popular[0].ProductId)
+	assert.Equal(t, 3, model.Popular[0].Purchases)
+}
+
+func TestPredict(t *testing.T) {
+	model := fitFixture()
+
+	items := predict(model, "A", 1)
+	assert.Len(t, items, 1)
+	assert.Equal(t, int64(1), items[0].ProductId)
+	assert.Equal(t, int64(10), items[0].ProductGrammageId)
+	if assert.NotNil(t, items[0].PredictedAt) {
+		assert.Equal(t, at("2024-03-31"), *items[0].PredictedAt)
+	}
+
+	// B bought once, so the repurchase interval falls back to the model default
+	items = predict(model, "B", 3)
+	assert.Len(t, items, 1)
+	if assert.NotNil(t, items[0].PredictedAt) {
+		assert.Equal(t, at("2024-02-01").Add(time.Duration(model.DefaultIntervalDays)*day), *items[0].PredictedAt)
+	}
+
+	// unknown members get the popular items without a date
+	items = predict(model, "C", 2)
+	assert.Len(t, items, 2)
+	assert.Nil(t, items[0].PredictedAt)
+}
+
+func TestScoreHoldout(t *testing.T) {
+	model := fitFixture()
+
+	outcomes := map[string]outcome{
+		"A": {
+			{1, 10}: at("2024-04-02"),
+			{3, 30}: at("2024-04-05"),
+		},
+		"B": {
+			{1, 10}: at("2024-04-01"),
+		},
+	}
+
+	score := scoreHoldout(model, outcomes, 2)
+
+	assert.Equal(t, 2, score.members)
+	// A hits 1 of 2 predictions and 1 of 2 purchases, B was predicted (2, 20) but bought (1, 10)
+	assert.InDelta(t, 0.5, score.precisionSum, 1e-9)
+	assert.InDelta(t, 0.5, score.recallSum, 1e-9)
+	assert.Equal(t, 1, score.dateSamples)
+	assert.InDelta(t, 2.0, score.dateErrorSum, 1e-9)
+}
@@ -0,0 +1,289 @@
+package service
+
+import (
+	"bytes"
+	"codebase-app/internal/infrastructure/config"
+	integration "codebase-app/internal/integration/filestorage"
+	"codebase-app/internal/module/prediction/entity"
+	"codebase-app/internal/module/prediction/ports"
+	"codebase-app/pkg/errmsg"
+	"context"
+	"encoding/json"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/rs/zerolog/log"
+)
+
+var _ ports.PredictionService = &predictionService{}
+
+type predictionService struct {
+	repo ports.PredictionRepository
+
+	mu        sync.RWMutex
+	model     *entity.Model
+	checkedAt time.Time
+	// refreshing lets one request at a time refresh the model, outside mu
+	// so the others keep serving the current model while it is fitted
+	refreshing sync.Mutex
+}
+
+func NewPredictionService(repo ports.PredictionRepository) *predictionService {
+	return &predictionService{
+		repo: repo,
+	}
+}
+
+func (s *predictionService) GetMemberPredictions(ctx context.Context, req *entity.GetMemberPredictionsReq) (*entity.GetMemberPredictionsResp, error) {
+	exist, err := s.repo.IsMemberExist(ctx, req.MemberId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exist {
+		log.Warn().Any("req", req).Msg("service::GetMemberPredictions - Member not found")
+		return nil, errmsg.NewCustomErrors(404).SetMessage("Member not found")
+	}
+
+	model, err := s.getModel(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	_, hasHistory := model.Members[req.MemberId]
+
+	var modelVersion *string
+	if model.Version != "" {
+		modelVersion = &model.Version
+	}
+
+	items, err := s.decorate(ctx, predict(model, req.MemberId, req.Limit))
+	if err != nil {
+		return nil, err
+	}
+
+	// a failed log must not fail the prediction, it only leaves a gap in the accuracy stats
+	if err := s.repo.CreatePredictionLogs(ctx, predictionLogs(req.MemberId, modelVersion, items)); err != nil {
+		log.Error().Err(err).Any("req", req).Msg("service::GetMemberPredictions - Failed to log predictions")
+	}
+
+	return &entity.GetMemberPredictionsResp{
+		MemberId:     req.MemberId,
+		ModelVersion: modelVersion,
+		HasHistory:   hasHistory,
+		Items:        items,
+	}, nil
+}
+
+// getModel returns the in-memory model. Every check interval it looks up the
+// newest trained model and hot-loads its artifact when the version changed.
+// Until a model has been trained, or while the first artifact fails to load,
+// it fits one from the training rows instead. Only the request that
+// refreshes waits for it, unless there is no model to serve yet.
+func (s *predictionService) getModel(ctx context.Context) (*entity.Model, error) {
+	checkInterval := time.Duration(config.Envs.Prediction.ModelCheckInterval) * time.Second
+
+	s.mu.RLock()
+	model, checkedAt := s.model, s.checkedAt
+	s.mu.RUnlock()
+
+	if model != nil && time.Since(checkedAt) < checkInterval {
+		return model, nil
+	}
+
+	if model == nil {
+		s.refreshing.Lock()
+	} else if !s.refreshing.TryLock() {
+		// another request is refreshing it
+		return model, nil
+	}
+	defer s.refreshing.Unlock()
+
+	// another request may have refreshed while we were waiting for the lock
+	s.mu.RLock()
+	model, checkedAt = s.model, s.checkedAt
+	s.mu.RUnlock()
+
+	if model != nil && time.Since(checkedAt) < checkInterval {
+		return model, nil
+	}
+
+	next, err := s.refresh(ctx, model)
+	if err != nil {
+		if model == nil {
+			return nil, err
+		}
+		log.Warn().Err(err).Msg("service::getModel - Failed to refresh model, serving the previous one")
+		next = model
+	}
+
+	s.mu.Lock()
+	s.model = next
+	s.checkedAt = time.Now()
+	s.mu.Unlock()
+
+	return next, nil
+}
+
+// refresh is the model to serve in place of current, which is nil until a
+// model has been served.
+func (s *predictionService) refresh(ctx context.Context, current *entity.Model) (*entity.Model, error) {
+	meta, err := s.repo.GetLatestModel(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if meta == nil {
+		refitInterval := time.Duration(config.Envs.Prediction.RefitInterval) * time.Minute
+		if current != nil && current.Version == "" && time.Since(current.TrainedAt) < refitInterval {
+			return current, nil
+		}
+
+		return s.fit(ctx, entity.TransactionFilter{TrainingOnly: true}, entity.DefaultModelParams())
+	}
+
+	if current != nil && current.Version == meta.Version {
+		return current, nil
+	}
+
+	model, err := s.loadModel(ctx, meta)
+	if err != nil && current == nil {
+		// nothing to keep serving, rank with a model fitted on the spot until
+		// the artifact loads
+		log.Warn().Err(err).Str("version", meta.Version).Msg("service::refresh - Failed to load model, fitting one instead")
+		return s.fit(ctx, entity.TransactionFilter{TrainingOnly: true}, entity.DefaultModelParams())
+	}
+
+	return model, err
+}
+
+func (s *predictionService) loadModel(ctx context.Context, meta *entity.ModelMeta) (*entity.Model, error) {
+	storage, err := integration.NewFileStorageIntegration(meta.StorageDriver)
+	if err != nil {
+		log.Error().Err(err).Any("model", meta).Msg("service::loadModel - Failed to get model storage")
+		return nil, err
+	}
+
+	body, err := storage.Get(ctx, meta.ArtifactKey)
+	if err != nil {
+		log.Error().Err(err).Any("model", meta).Msg("service::loadModel - Failed to get model artifact")
+		return nil, err
+	}
+	defer body.Close()
+
+	model, err := decodeModel(body)
+	if err != nil {
+		log.Error().Err(err).Any("model", meta).Msg("service::loadModel - Failed to decode model artifact")
+		return nil, err
+	}
+
+	log.Info().Str("version", model.Version).Msg("service::loadModel - Model loaded")
+
+	return model, nil
+}
+
+func (s *predictionService) Train(ctx context.Context, req *entity.TrainReq) (*entity.ModelMeta, error) {
+	storage, err := integration.NewFileStorageIntegration(req.StorageDriver)
+	if err != nil {
+		log.Error().Err(err).Any("req", req).Msg("service::Train - Failed to get model storage")
+		return nil, err
+	}
+
+	model, err := s.fit(ctx, entity.TransactionFilter{TrainingOnly: true}, entity.DefaultModelParams())
+	if err != nil {
+		return nil, err
+	}
+	// a second-resolution timestamp collides when two trainings start together
+	model.Version = ulid.MustNew(ulid.Timestamp(model.TrainedAt), ulid.DefaultEntropy()).String()
+
+	params, err := json.Marshal(model.Params)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := &entity.ModelMeta{
+		Version:       model.Version,
+		ArtifactKey:   artifactKey(model.Version),
+		StorageDriver: storage.Driver(),
+		Params:        params,
+		TrainingRows:  model.TrainingRows,
+		MemberCount:   len(model.Members),
+		TrainedAt:     model.TrainedAt,
+	}
+
+	var buf bytes.Buffer
+	if err := encodeModel(&buf, model); err != nil {
+		log.Error().Err(err).Any("model", meta).Msg("service::Train - Failed to encode model")
+		return nil, err
+	}
+
+	if err := storage.Put(ctx, meta.ArtifactKey, &buf); err != nil {
+		log.Error().Err(err).Any("model", meta).Msg("service::Train - Failed to store model artifact")
+		return nil, err
+	}
+
+	// the row is written last so the server never picks up a version without its artifact
+	if err := s.repo.CreateModel(ctx, meta); err != nil {
+		return nil, err
+	}
+
+	return meta, nil
+}
+
+func (s *predictionService) fit(ctx context.Context, filter entity.TransactionFilter, params entity.ModelParams) (*entity.Model, error) {
+	builder := newModelBuilder(params)
+
+	if err := s.repo.StreamTransactions(ctx, filter, builder.Add); err != nil {
+		log.Error().Err(err).Any("filter", filter).Msg("service::fit - Failed to read transactions")
+		return nil, err
+	}
+
+	model := builder.Build(time.Now().UTC())
+	log.Info().Int("rows", model.TrainingRows).Int("members", len(model.Members)).Msg("service::fit - Model fitted")
+
+	return model, nil
+}
+
+func (s *predictionService) decorate(ctx context.Context, scored []entity.ScoredItem) ([]entity.Prediction, error) {
+	var (
+		productIds  = make([]int64, 0, len(scored))
+		grammageIds = make([]int64, 0, len(scored))
+		res         = make([]entity.Prediction, 0, len(scored))
+	)
+
+	for _, item := range scored {
+		productIds = append(productIds, item.ProductId)
+		grammageIds = append(grammageIds, item.ProductGrammageId)
+	}
+
+	productNames, err := s.repo.GetProductNames(ctx, productIds)
+	if err != nil {
+		return nil, err
+	}
+
+	grammageNames, err := s.repo.GetGrammageNames(ctx, grammageIds)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range scored {
+		prediction := entity.Prediction{
+			ProductId:         item.ProductId,
+			ProductName:       productNames[item.ProductId],
+			ProductGrammageId: item.ProductGrammageId,
+			GrammageName:      grammageNames[item.ProductGrammageId],
+			Probability:       math.Round(item.Probability*10000) / 10000,
+		}
+
+		if item.PredictedAt != nil {
+			date := item.PredictedAt.Format(time.DateOnly)
+			prediction.PredictedDate = &date
+		}
+
+		res = append(res, prediction)
+	}
+
+	return res, nil
+}
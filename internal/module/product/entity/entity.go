@@ -1,11 +1,35 @@
 package entity
 
-import "mime/multipart"
+import (
+	"errors"
+	"mime/multipart"
+)
+
+var (
+	ErrTransactionNotFound      = errors.New("product transaction not found")
+	ErrTransactionAlreadyVoided = errors.New("product transaction already voided")
+	ErrProductNotFound          = errors.New("product not found")
+)
 
 type ImportProductsReq struct {
 	File *multipart.FileHeader `form:"file" validate:"required"`
+	// DryRun checks the file and rolls the import back instead of committing it
+	DryRun bool `query:"dry_run"`
+	// Sheet of an xlsx upload, the first sheet when empty
+	Sheet string `query:"sheet"`
+	// OnConflict is what to do with rows whose id already exists
+	OnConflict string `query:"on_conflict" validate:"omitempty,oneof=fail skip update"`
+	// Profile is the name of the import profile that maps the columns
+	Profile string `query:"profile" validate:"omitempty,max=100"`
+	// UploadedBy is the authenticated user, set by the handler
+	UploadedBy *string `query:"-" json:"-"`
+}
 
-	Data []*Product
+func (r *ImportProductsReq) SetDefault() {
+	// a re-uploaded file fails on ids that already exist, as it always has
+	if r.OnConflict == "" {
+		r.OnConflict = "fail"
+	}
 }
 
 type Product struct {
@@ -17,21 +41,53 @@ type Product struct {
 
 type ImportProductGrammageReq struct {
 	File *multipart.FileHeader `form:"file" validate:"required"`
+	// DryRun checks the file and rolls the import back instead of committing it
+	DryRun bool `query:"dry_run"`
+	// Sheet of an xlsx upload, the first sheet when empty
+	Sheet string `query:"sheet"`
+	// OnConflict is what to do with rows whose id already exists
+	OnConflict string `query:"on_conflict" validate:"omitempty,oneof=fail skip update"`
+	// Profile is the name of the import profile that maps the columns
+	Profile string `query:"profile" validate:"omitempty,max=100"`
+	// UploadedBy is the authenticated user, set by the handler
+	UploadedBy *string `query:"-" json:"-"`
+}
 
-	Data []*ProductGrammage
+func (r *ImportProductGrammageReq) SetDefault() {
+	if r.OnConflict == "" {
+		r.OnConflict = "fail"
+	}
 }
 
 type ProductGrammage struct {
-	Id    int      `db:"id" csv:"prodgramID" validate:"numeric" json:"id"`
-	Name  *string  `db:"name" csv:"GrammageName,omitempty" json:"name"`
-	Point *int64   `db:"point" csv:"Point,omitempty" json:"point"`
-	Price *float64 `db:"price" csv:"Price,omitempty" json:"price"`
+	Id int `db:"id" csv:"prodgramID" validate:"numeric" json:"id"`
+	// ProductId is the product the grammage belongs to, transactions of the
+	// grammage must be of it
+	ProductId *int64   `db:"product_id" csv:"productID,omitempty" json:"product_id"`
+	Name      *string  `db:"name" csv:"GrammageName,omitempty" json:"name"`
+	Point     *int64   `db:"point" csv:"Point,omitempty" json:"point"`
+	Price     *float64 `db:"price" csv:"Price,omitempty" json:"price"`
 }
 
 type ImportProductTransactionsReq struct {
 	File *multipart.FileHeader `form:"file" validate:"required"`
+	// DryRun checks the file and rolls the import back instead of committing it
+	DryRun bool `query:"dry_run"`
+	// Sheet of an xlsx upload, the first sheet when empty
+	Sheet string `query:"sheet"`
+	// OnConflict is what to do with rows whose id already exists
+	OnConflict string `query:"on_conflict" validate:"omitempty,oneof=fail skip update"`
+	// Profile is the name of the import profile that maps the columns
+	Profile string `query:"profile" validate:"omitempty,max=100"`
+	// UploadedBy is the authenticated user, set by the handler
+	UploadedBy *string `query:"-" json:"-"`
+}
 
-	Data []*ProductTransaction
+func (r *ImportProductTransactionsReq) SetDefault() {
+	// transactions have always been upserted
+	if r.OnConflict == "" {
+		r.OnConflict = "update"
+	}
 }
 
 type CreateProductTransactionReq struct {
@@ -47,11 +103,44 @@ type CreateProductTransactionReq struct {
 type ProductTransaction struct {
 	Id                string   `db:"id" csv:"TransactionID" json:"id"`
 	MemberId          string   `db:"member_id" csv:"MemberID" json:"member_id"`
-	ProductId         int64    `db:"product_id" csv:"FK_PRODUCT_ID" json:"product_id"`
-	ProductGrammageId int64    `db:"product_grammage_id" csv:"FK_PROD_GRAM_ID" json:"product_grammage_id"`
+	ProductId         *int64   `db:"product_id" csv:"FK_PRODUCT_ID" json:"product_id"`
+	ProductGrammageId *int64   `db:"product_grammage_id" csv:"FK_PROD_GRAM_ID" json:"product_grammage_id"`
 	Source            string   `db:"source" csv:"Source" json:"source"`
 	Qty               int      `db:"qty" csv:"Qty" json:"qty"`
 	PricePerUnit      *float64 `db:"price_per_unit" csv:"PricePerUnit,omitempty" json:"price_per_unit"`
 	CreatedAt         string   `db:"created_at" csv:"TransactionDatetime" json:"created_at" validate:"required,datetime=2006-01-02 15:04:05 MST"`
 	IsTrainingData    bool     `db:"is_training_data" json:"is_training_data"`
+	VoidedAt          *string  `db:"voided_at" csv:"-" json:"voided_at"`
+}
+
+type GetProductReq struct {
+	Id int64 `params:"id" validate:"required"`
+}
+
+type GetProductResp struct {
+	Product
+	Grammages []ProductGrammage `json:"grammages"`
+}
+
+// Orphan is a row whose reference is missing or inconsistent, a row of the
+// orphaned_rows view.
+type Orphan struct {
+	Table  string  `db:"table_name" csv:"table" json:"table"`
+	RowId  string  `db:"row_id" csv:"row_id" json:"row_id"`
+	Column string  `db:"column_name" csv:"column" json:"column"`
+	Value  *string `db:"value" csv:"value,omitempty" json:"value"`
+	// Reason is missing, mismatch for a grammage of another product, or
+	// unlinked for a grammage without a product
+	Reason string `db:"reason" csv:"reason" json:"reason"`
+}
+
+type VoidProductTransactionReq struct {
+	Id string `params:"id" validate:"required"`
+}
+
+type VoidProductTransactionResp struct {
+	Id             string `db:"id" json:"id"`
+	MemberId       string `db:"member_id" json:"member_id"`
+	VoidedAt       string `db:"voided_at" json:"voided_at"`
+	PointsReversed int    `json:"points_reversed"`
 }
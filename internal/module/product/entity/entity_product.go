@@ -1,10 +1,29 @@
 package entity
 
-import "codebase-app/pkg/types"
+import (
+	"codebase-app/pkg/queryspec"
+	"codebase-app/pkg/types"
+)
 
 type GetProductsReq struct {
 	Page     int `query:"page" validate:"required,numeric"`
 	Paginate int `query:"paginate" validate:"required,numeric"`
+	// Pagination is cursor to page by the key of the list instead of page
+	Pagination string `query:"pagination" validate:"omitempty,oneof=offset cursor"`
+	// Cursor is the next_cursor or prev_cursor of a previous page
+	Cursor string `query:"cursor" validate:"omitempty,max=1024"`
+	// Count adds an estimated total to cursor pages
+	Count bool `query:"count"`
+	// Spec is the filter, sort and search of ProductFields, set by the handler
+	Spec *queryspec.Spec `query:"-" json:"-"`
+}
+
+// ProductFields are the fields products can be listed by.
+var ProductFields = queryspec.Fields{
+	"id":       {Column: "p.id", Type: queryspec.Int, Sort: true},
+	"name":     {Column: "p.name", Type: queryspec.Text, Sort: true, Search: true},
+	"category": {Column: "p.category", Type: queryspec.Text, Sort: true, Search: true},
+	"level":    {Column: "p.level", Type: queryspec.Text, Sort: true},
 }
 
 func (r *GetProductsReq) SetDefault() {
@@ -17,6 +36,14 @@ func (r *GetProductsReq) SetDefault() {
 	}
 }
 
+// CursorMode tells whether the list is paged by cursor.
+func (r *GetProductsReq) CursorMode() bool {
+	return r.Pagination == "cursor" || r.Cursor != ""
+}
+
+// ProductKeyset is the key products are paged by in cursor mode.
+var ProductKeyset = queryspec.Keyset{Columns: []string{"p.id"}}
+
 type GetProductsResp struct {
 	Items []Product  `json:"items"`
 	Meta  types.Meta `json:"meta"`
@@ -25,6 +52,29 @@ type GetProductsResp struct {
 type GetProductTransactionsReq struct {
 	Page     int `query:"page" validate:"required,numeric"`
 	Paginate int `query:"paginate" validate:"required,numeric"`
+	// Pagination is cursor to page by the key of the list instead of page
+	Pagination string `query:"pagination" validate:"omitempty,oneof=offset cursor"`
+	// Cursor is the next_cursor or prev_cursor of a previous page
+	Cursor string `query:"cursor" validate:"omitempty,max=1024"`
+	// Count adds an estimated total to cursor pages
+	Count bool `query:"count"`
+	// Spec is the filter, sort and search of ProductTransactionFields, set by
+	// the handler
+	Spec *queryspec.Spec `query:"-" json:"-"`
+}
+
+// ProductTransactionFields are the fields transactions can be listed by.
+var ProductTransactionFields = queryspec.Fields{
+	"id":                  {Column: "pt.id", Type: queryspec.Text, Sort: true, Search: true},
+	"member_id":           {Column: "pt.member_id", Type: queryspec.Text, Sort: true, Search: true},
+	"product_id":          {Column: "pt.product_id", Type: queryspec.Int, Sort: true},
+	"product_grammage_id": {Column: "pt.product_grammage_id", Type: queryspec.Int},
+	"source":              {Column: "pt.source", Type: queryspec.Text, Sort: true},
+	"qty":                 {Column: "pt.qty", Type: queryspec.Int, Sort: true},
+	"price_per_unit":      {Column: "pt.price_per_unit", Type: queryspec.Number, Sort: true},
+	"created_at":          {Column: "pt.created_at", Type: queryspec.Timestamp, Sort: true},
+	"is_training_data":    {Column: "pt.is_training_data", Type: queryspec.Bool},
+	"voided_at":           {Column: "pt.voided_at", Type: queryspec.Timestamp, Sort: true},
 }
 
 func (r *GetProductTransactionsReq) SetDefault() {
@@ -37,6 +87,20 @@ func (r *GetProductTransactionsReq) SetDefault() {
 	}
 }
 
+// CursorMode tells whether the list is paged by cursor.
+func (r *GetProductTransactionsReq) CursorMode() bool {
+	return r.Pagination == "cursor" || r.Cursor != ""
+}
+
+// ProductTransactionKeyset is the key transactions are paged by in cursor
+// mode, newest first. Transactions without a created_at are only listed by
+// page.
+var ProductTransactionKeyset = queryspec.Keyset{
+	Columns:  []string{"pt.created_at", "pt.id"},
+	Desc:     true,
+	Nullable: []string{"pt.created_at"},
+}
+
 type GetProductTransactionsResp struct {
 	Items []ProductTransaction `json:"items"`
 	Meta  types.Meta           `json:"meta"`
@@ -45,6 +109,24 @@ type GetProductTransactionsResp struct {
 type GetProductGrammagesReq struct {
 	Page     int `query:"page" validate:"required,numeric"`
 	Paginate int `query:"paginate" validate:"required,numeric"`
+	// Pagination is cursor to page by the key of the list instead of page
+	Pagination string `query:"pagination" validate:"omitempty,oneof=offset cursor"`
+	// Cursor is the next_cursor or prev_cursor of a previous page
+	Cursor string `query:"cursor" validate:"omitempty,max=1024"`
+	// Count adds an estimated total to cursor pages
+	Count bool `query:"count"`
+	// Spec is the filter, sort and search of ProductGrammageFields, set by the
+	// handler
+	Spec *queryspec.Spec `query:"-" json:"-"`
+}
+
+// ProductGrammageFields are the fields grammages can be listed by.
+var ProductGrammageFields = queryspec.Fields{
+	"id":         {Column: "pg.id", Type: queryspec.Int, Sort: true},
+	"product_id": {Column: "pg.product_id", Type: queryspec.Int, Sort: true},
+	"name":       {Column: "pg.name", Type: queryspec.Text, Sort: true, Search: true},
+	"point":      {Column: "pg.point", Type: queryspec.Int, Sort: true},
+	"price":      {Column: "pg.price", Type: queryspec.Number, Sort: true},
 }
 
 func (r *GetProductGrammagesReq) SetDefault() {
@@ -57,7 +139,88 @@ func (r *GetProductGrammagesReq) SetDefault() {
 	}
 }
 
+// CursorMode tells whether the list is paged by cursor.
+func (r *GetProductGrammagesReq) CursorMode() bool {
+	return r.Pagination == "cursor" || r.Cursor != ""
+}
+
+// ProductGrammageKeyset is the key grammages are paged by in cursor mode.
+var ProductGrammageKeyset = queryspec.Keyset{Columns: []string{"pg.id"}}
+
 type GetProductGrammagesResp struct {
 	Items []ProductGrammage `json:"items"`
 	Meta  types.Meta        `json:"meta"`
 }
+
+type ExportProductsReq struct {
+	Format   string `query:"format" validate:"omitempty,oneof=csv xlsx"`
+	Category string `query:"category"`
+	Level    string `query:"level"`
+}
+
+func (r *ExportProductsReq) SetDefault() {
+	if r.Format == "" {
+		r.Format = "csv"
+	}
+}
+
+type ExportProductGrammagesReq struct {
+	Format string `query:"format" validate:"omitempty,oneof=csv xlsx"`
+	// Name matches grammage names containing it, ignoring case
+	Name string `query:"name"`
+}
+
+func (r *ExportProductGrammagesReq) SetDefault() {
+	if r.Format == "" {
+		r.Format = "csv"
+	}
+}
+
+type ExportProductTransactionsReq struct {
+	Format    string `query:"format" validate:"omitempty,oneof=csv xlsx"`
+	MemberId  string `query:"member_id"`
+	ProductId int64  `query:"product_id"`
+	Source    string `query:"source"`
+	// From and To bound the transaction date, both inclusive
+	From string `query:"from" validate:"omitempty,datetime=2006-01-02"`
+	To   string `query:"to" validate:"omitempty,datetime=2006-01-02"`
+	// IncludeVoided exports voided transactions too
+	IncludeVoided bool `query:"include_voided"`
+}
+
+func (r *ExportProductTransactionsReq) SetDefault() {
+	if r.Format == "" {
+		r.Format = "csv"
+	}
+}
+
+type SearchProductsReq struct {
+	// Q is matched by word prefix against the name, category and level
+	Q        string `query:"q" validate:"required,max=200"`
+	Page     int    `query:"page" validate:"required,numeric"`
+	Paginate int    `query:"paginate" validate:"required,numeric,max=100"`
+}
+
+func (r *SearchProductsReq) SetDefault() {
+	if r.Page < 1 {
+		r.Page = 1
+	}
+
+	if r.Paginate < 1 {
+		r.Paginate = 10
+	}
+}
+
+type SearchProductsResp struct {
+	Items []ProductSearchResult `json:"items"`
+	Meta  types.Meta            `json:"meta"`
+}
+
+type ProductSearchResult struct {
+	Product
+	Rank float64 `db:"rank" json:"rank"`
+	// Highlight is the name, category and level, HTML escaped, with the
+	// matched words wrapped in <b> tags
+	Highlight string            `db:"highlight" json:"highlight"`
+	Grammages []ProductGrammage `db:"-" json:"grammages"`
+}
@@ -1,13 +1,22 @@
 package handler
 
 import (
+	"bufio"
 	"codebase-app/internal/adapter"
+	m "codebase-app/internal/middleware"
+	importJobRepository "codebase-app/internal/module/import_job/repository"
+	importJobService "codebase-app/internal/module/import_job/service"
 	"codebase-app/internal/module/product/entity"
 	"codebase-app/internal/module/product/ports"
 	"codebase-app/internal/module/product/repository"
 	"codebase-app/internal/module/product/service"
+	tierRepository "codebase-app/internal/module/tier/repository"
+	tierService "codebase-app/internal/module/tier/service"
 	"codebase-app/pkg/errmsg"
+	"codebase-app/pkg/exporter"
+	"codebase-app/pkg/queryspec"
 	"codebase-app/pkg/response"
+	"context"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/rs/zerolog/log"
@@ -21,7 +30,9 @@ type productHandler struct {
 func NewProductHandler() *productHandler {
 	var (
 		repo    = repository.NewProductRepository()
-		service = service.NewProductService(repo)
+		tier    = tierService.NewTierService(tierRepository.NewTierRepository())
+		imports = importJobService.NewImportJobService(importJobRepository.NewImportJobRepository())
+		service = service.NewProductService(repo, tier, imports)
 		handler = new(productHandler)
 	)
 	handler.service = service
@@ -30,16 +41,24 @@ func NewProductHandler() *productHandler {
 }
 
 func (h *productHandler) Register(router fiber.Router) {
-	router.Post("/import", h.importProducts)
-	router.Post("/import-transactions", h.importProductTransactions)
-	router.Post("/import-grammage", h.importProductsGrammage)
+	router.Post("/import", m.AuthBearer, h.importProducts)
+	router.Post("/import-transactions", m.AuthBearer, h.importProductTransactions)
+	router.Post("/import-grammage", m.AuthBearer, h.importProductsGrammage)
 
 	router.Post("/transactions", h.createProductTransaction)
+	router.Post("/transactions/:id/void", h.voidProductTransaction)
 
 	router.Get("/transactions", h.getProductTransactions)
 	router.Get("/data", h.getProducts)
+	router.Get("/search", h.searchProducts)
 	router.Get("/grammages", h.getProductGrammages)
 
+	router.Get("/export", h.exportProducts)
+	router.Get("/grammages/export", h.exportProductGrammages)
+	router.Get("/transactions/export", h.exportProductTransactions)
+
+	// last, it would match the routes above
+	router.Get("/:id", h.getProduct)
 }
 
 func (h *productHandler) importProducts(c *fiber.Ctx) error {
@@ -58,18 +77,30 @@ func (h *productHandler) importProducts(c *fiber.Ctx) error {
 	}
 	req.File = file
 
+	if err := c.QueryParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::importProducts - Failed to parse query")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.SetDefault()
+
+	locals := m.GetLocals(c)
+	uploadedBy := locals.GetUserId()
+	req.UploadedBy = &uploadedBy
+
 	if err := v.Validate(req); err != nil {
 		log.Warn().Err(err).Any("req", req).Msg("handler::importProducts - Invalid request body")
 		code, errs := errmsg.Errors(err, req)
 		return c.Status(code).JSON(response.Error(errs))
 	}
 
-	if err := h.service.ImportProducts(ctx, req); err != nil {
+	resp, err := h.service.ImportProducts(ctx, req)
+	if err != nil {
 		code, errs := errmsg.Errors[error](err)
 		return c.Status(code).JSON(response.Error(errs))
 	}
 
-	return c.JSON(response.Success(nil, ""))
+	return c.JSON(response.Success(resp, ""))
 }
 
 func (h *productHandler) importProductsGrammage(c *fiber.Ctx) error {
@@ -88,18 +119,30 @@ func (h *productHandler) importProductsGrammage(c *fiber.Ctx) error {
 	}
 	req.File = file
 
+	if err := c.QueryParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::importProductsGrammage - Failed to parse query")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.SetDefault()
+
+	locals := m.GetLocals(c)
+	uploadedBy := locals.GetUserId()
+	req.UploadedBy = &uploadedBy
+
 	if err := v.Validate(req); err != nil {
 		log.Warn().Err(err).Any("req", req).Msg("handler::importProductsGrammage - Invalid request body")
 		code, errs := errmsg.Errors(err, req)
 		return c.Status(code).JSON(response.Error(errs))
 	}
 
-	if err := h.service.ImportProductGrammage(ctx, req); err != nil {
+	resp, err := h.service.ImportProductGrammage(ctx, req)
+	if err != nil {
 		code, errs := errmsg.Errors[error](err)
 		return c.Status(code).JSON(response.Error(errs))
 	}
 
-	return c.JSON(response.Success(nil, ""))
+	return c.JSON(response.Success(resp, ""))
 }
 
 func (h *productHandler) importProductTransactions(c *fiber.Ctx) error {
@@ -123,18 +166,30 @@ func (h *productHandler) importProductTransactions(c *fiber.Ctx) error {
 	}
 	req.File = file
 
+	if err := c.QueryParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::importProductTransactions - Failed to parse query")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.SetDefault()
+
+	locals := m.GetLocals(c)
+	uploadedBy := locals.GetUserId()
+	req.UploadedBy = &uploadedBy
+
 	if err := v.Validate(req); err != nil {
 		log.Warn().Err(err).Any("req", req).Msg("handler::importProductTransactions - Invalid request body")
 		code, errs := errmsg.Errors(err, req)
 		return c.Status(code).JSON(response.Error(errs))
 	}
 
-	if err := h.service.ImportProductTransactions(ctx, req); err != nil {
+	resp, err := h.service.ImportProductTransactions(ctx, req)
+	if err != nil {
 		code, errs := errmsg.Errors[error](err)
 		return c.Status(code).JSON(response.Error(errs))
 	}
 
-	return c.JSON(response.Success(nil, ""))
+	return c.JSON(response.Success(resp, ""))
 }
 
 func (h *productHandler) createProductTransaction(c *fiber.Ctx) error {
@@ -163,6 +218,60 @@ func (h *productHandler) createProductTransaction(c *fiber.Ctx) error {
 	return c.JSON(response.Success(nil, ""))
 }
 
+func (h *productHandler) voidProductTransaction(c *fiber.Ctx) error {
+	var (
+		req = new(entity.VoidProductTransactionReq)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+	)
+
+	if err := c.ParamsParser(req); err != nil {
+		log.Warn().Err(err).Any("req", req).Msg("handler::voidProductTransaction - Invalid request params")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("req", req).Msg("handler::voidProductTransaction - Invalid request params")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	resp, err := h.service.VoidProductTransaction(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.JSON(response.Success(resp, ""))
+}
+
+func (h *productHandler) getProduct(c *fiber.Ctx) error {
+	var (
+		req = new(entity.GetProductReq)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+	)
+
+	if err := c.ParamsParser(req); err != nil {
+		log.Warn().Err(err).Any("req", req).Msg("handler::getProduct - Invalid request params")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("req", req).Msg("handler::getProduct - Invalid request params")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	resp, err := h.service.GetProduct(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.JSON(response.Success(resp, ""))
+}
+
 func (h *productHandler) getProducts(c *fiber.Ctx) error {
 	var (
 		req = new(entity.GetProductsReq)
@@ -183,6 +292,14 @@ func (h *productHandler) getProducts(c *fiber.Ctx) error {
 		return c.Status(code).JSON(response.Error(errs))
 	}
 
+	spec, err := queryspec.Parse(c.Queries(), entity.ProductFields)
+	if err != nil {
+		log.Warn().Err(err).Any("req", req).Msg("handler::getProducts - Invalid filter")
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+	req.Spec = spec
+
 	data, err := h.service.GetProducts(ctx, req)
 	if err != nil {
 		code, errs := errmsg.Errors[error](err)
@@ -192,6 +309,35 @@ func (h *productHandler) getProducts(c *fiber.Ctx) error {
 	return c.JSON(response.Success(data, ""))
 }
 
+func (h *productHandler) searchProducts(c *fiber.Ctx) error {
+	var (
+		req = new(entity.SearchProductsReq)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+	)
+
+	if err := c.QueryParser(req); err != nil {
+		log.Warn().Err(err).Any("req", req).Msg("handler::searchProducts - Invalid request query")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.SetDefault()
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("req", req).Msg("handler::searchProducts - Invalid request query")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	data, err := h.service.SearchProducts(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.JSON(response.Success(data, ""))
+}
+
 func (h *productHandler) getProductTransactions(c *fiber.Ctx) error {
 	var (
 		req = new(entity.GetProductTransactionsReq)
@@ -212,6 +358,14 @@ func (h *productHandler) getProductTransactions(c *fiber.Ctx) error {
 		return c.Status(code).JSON(response.Error(errs))
 	}
 
+	spec, err := queryspec.Parse(c.Queries(), entity.ProductTransactionFields)
+	if err != nil {
+		log.Warn().Err(err).Any("req", req).Msg("handler::getProductTransactions - Invalid filter")
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+	req.Spec = spec
+
 	data, err := h.service.GetProductTransactions(ctx, req)
 	if err != nil {
 		code, errs := errmsg.Errors[error](err)
@@ -241,6 +395,14 @@ func (h *productHandler) getProductGrammages(c *fiber.Ctx) error {
 		return c.Status(code).JSON(response.Error(errs))
 	}
 
+	spec, err := queryspec.Parse(c.Queries(), entity.ProductGrammageFields)
+	if err != nil {
+		log.Warn().Err(err).Any("req", req).Msg("handler::getProductGrammages - Invalid filter")
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+	req.Spec = spec
+
 	data, err := h.service.GetProductGrammages(ctx, req)
 	if err != nil {
 		code, errs := errmsg.Errors[error](err)
@@ -249,3 +411,128 @@ func (h *productHandler) getProductGrammages(c *fiber.Ctx) error {
 
 	return c.JSON(response.Success(data, ""))
 }
+
+func (h *productHandler) exportProducts(c *fiber.Ctx) error {
+	var (
+		req = new(entity.ExportProductsReq)
+		v   = adapter.Adapters.Validator
+	)
+
+	if err := c.QueryParser(req); err != nil {
+		log.Warn().Err(err).Any("req", req).Msg("handler::exportProducts - Invalid request query")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.SetDefault()
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("req", req).Msg("handler::exportProducts - Invalid request query")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	// the body is written after the handler returns, the export gets its own
+	// context, canceled once the stream ends
+	ctx, cancel := context.WithCancel(context.Background())
+
+	stream, err := h.service.ExportProducts(ctx, req)
+	if err != nil {
+		cancel()
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	c.Attachment("products." + req.Format)
+	c.Set(fiber.HeaderContentType, exporter.ContentType(req.Format))
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer cancel()
+		if err := stream(w); err != nil {
+			log.Error().Err(err).Any("req", req).Msg("handler::exportProducts - Failed to export products")
+		}
+	})
+
+	return nil
+}
+
+func (h *productHandler) exportProductGrammages(c *fiber.Ctx) error {
+	var (
+		req = new(entity.ExportProductGrammagesReq)
+		v   = adapter.Adapters.Validator
+	)
+
+	if err := c.QueryParser(req); err != nil {
+		log.Warn().Err(err).Any("req", req).Msg("handler::exportProductGrammages - Invalid request query")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.SetDefault()
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("req", req).Msg("handler::exportProductGrammages - Invalid request query")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	stream, err := h.service.ExportProductGrammages(ctx, req)
+	if err != nil {
+		cancel()
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	c.Attachment("product_grammages." + req.Format)
+	c.Set(fiber.HeaderContentType, exporter.ContentType(req.Format))
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer cancel()
+		if err := stream(w); err != nil {
+			log.Error().Err(err).Any("req", req).Msg("handler::exportProductGrammages - Failed to export product grammages")
+		}
+	})
+
+	return nil
+}
+
+func (h *productHandler) exportProductTransactions(c *fiber.Ctx) error {
+	var (
+		req = new(entity.ExportProductTransactionsReq)
+		v   = adapter.Adapters.Validator
+	)
+
+	if err := c.QueryParser(req); err != nil {
+		log.Warn().Err(err).Any("req", req).Msg("handler::exportProductTransactions - Invalid request query")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.SetDefault()
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("req", req).Msg("handler::exportProductTransactions - Invalid request query")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	stream, err := h.service.ExportProductTransactions(ctx, req)
+	if err != nil {
+		cancel()
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	c.Attachment("product_transactions." + req.Format)
+	c.Set(fiber.HeaderContentType, exporter.ContentType(req.Format))
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer cancel()
+		if err := stream(w); err != nil {
+			log.Error().Err(err).Any("req", req).Msg("handler::exportProductTransactions - Failed to export product transactions")
+		}
+	})
+
+	return nil
+}
@@ -1,30 +1,72 @@
 package ports
 
 import (
+	importJobEntity "codebase-app/internal/module/import_job/entity"
 	"codebase-app/internal/module/product/entity"
+	"codebase-app/pkg/exporter"
+	"codebase-app/pkg/importer"
 	"context"
+	"io"
 )
 
 type ProductRepository interface {
-	ImportProducts(ctx context.Context, req *entity.ImportProductsReq) error
-	ImportProductGrammage(ctx context.Context, req *entity.ImportProductGrammageReq) error
-	ImportProductTransactions(ctx context.Context, req *entity.ImportProductTransactionsReq) error
+	ImportProducts(ctx context.Context, src importer.Source, opts importer.Options[entity.Product]) (*importer.Result, error)
+	ImportProductGrammage(ctx context.Context, src importer.Source, opts importer.Options[entity.ProductGrammage]) (*importer.Result, error)
+	ImportProductTransactions(ctx context.Context, src importer.Source, opts importer.Options[entity.ProductTransaction]) (*importer.Result, []string, error)
+	RollbackProductsImport(ctx context.Context, id string) (int64, error)
+	RollbackProductGrammagesImport(ctx context.Context, id string) (int64, error)
+	RollbackProductTransactionsImport(ctx context.Context, id string) (int64, []string, error)
 
 	CreateProductTransaction(ctx context.Context, req *entity.CreateProductTransactionReq) error
+	VoidProductTransaction(ctx context.Context, req *entity.VoidProductTransactionReq) (*entity.VoidProductTransactionResp, error)
 
 	GetProducts(ctx context.Context, req *entity.GetProductsReq) (*entity.GetProductsResp, error)
 	GetProductTransactions(ctx context.Context, req *entity.GetProductTransactionsReq) (*entity.GetProductTransactionsResp, error)
 	GetProductGrammages(ctx context.Context, req *entity.GetProductGrammagesReq) (*entity.GetProductGrammagesResp, error)
+	SearchProducts(ctx context.Context, req *entity.SearchProductsReq, tsquery string) (*entity.SearchProductsResp, error)
+	GetProduct(ctx context.Context, id int64) (*entity.Product, error)
+	GetGrammagesByProducts(ctx context.Context, ids []int) (map[int][]entity.ProductGrammage, error)
+
+	GetOrphans(ctx context.Context, each func([]entity.Orphan) error) error
+	ValidateReferences(ctx context.Context) error
+
+	ExportProducts(ctx context.Context, req *entity.ExportProductsReq) (*exporter.Cursor[entity.Product], error)
+	ExportProductGrammages(ctx context.Context, req *entity.ExportProductGrammagesReq) (*exporter.Cursor[entity.ProductGrammage], error)
+	ExportProductTransactions(ctx context.Context, req *entity.ExportProductTransactionsReq) (*exporter.Cursor[entity.ProductTransaction], error)
 }
 
 type ProductService interface {
-	ImportProducts(ctx context.Context, req *entity.ImportProductsReq) error
-	ImportProductGrammage(ctx context.Context, req *entity.ImportProductGrammageReq) error
-	ImportProductTransactions(ctx context.Context, req *entity.ImportProductTransactionsReq) error
+	ImportProducts(ctx context.Context, req *entity.ImportProductsReq) (*importJobEntity.ImportJob, error)
+	ImportProductGrammage(ctx context.Context, req *entity.ImportProductGrammageReq) (*importJobEntity.ImportJob, error)
+	ImportProductTransactions(ctx context.Context, req *entity.ImportProductTransactionsReq) (*importJobEntity.ImportJob, error)
+
+	// Runners of the queued imports, see import_job ports.ImportRunner.
+	RunProductsImport(ctx context.Context, src importer.Source, settings importer.Settings) (*importer.Result, error)
+	RunProductGrammagesImport(ctx context.Context, src importer.Source, settings importer.Settings) (*importer.Result, error)
+	RunProductTransactionsImport(ctx context.Context, src importer.Source, settings importer.Settings) (*importer.Result, error)
+
+	// Rollbacks of the completed imports, see import_job ports.ImportRollback.
+	RollbackProductsImport(ctx context.Context, id string) (int64, error)
+	RollbackProductGrammagesImport(ctx context.Context, id string) (int64, error)
+	RollbackProductTransactionsImport(ctx context.Context, id string) (int64, error)
 
 	CreateProductTransaction(ctx context.Context, req *entity.CreateProductTransactionReq) error
+	VoidProductTransaction(ctx context.Context, req *entity.VoidProductTransactionReq) (*entity.VoidProductTransactionResp, error)
 
 	GetProducts(ctx context.Context, req *entity.GetProductsReq) (*entity.GetProductsResp, error)
 	GetProductTransactions(ctx context.Context, req *entity.GetProductTransactionsReq) (*entity.GetProductTransactionsResp, error)
 	GetProductGrammages(ctx context.Context, req *entity.GetProductGrammagesReq) (*entity.GetProductGrammagesResp, error)
+	SearchProducts(ctx context.Context, req *entity.SearchProductsReq) (*entity.SearchProductsResp, error)
+	GetProduct(ctx context.Context, req *entity.GetProductReq) (*entity.GetProductResp, error)
+
+	// ReportOrphans writes the rows breaking a reference to w as CSV and
+	// returns their count by table, column and reason. With validate and no
+	// orphans left, it validates the foreign keys.
+	ReportOrphans(ctx context.Context, w io.Writer, validate bool) (map[string]int, error)
+
+	// Exports open the file of req.Format, in the import format. An invalid
+	// query fails here, before the stream writes anything.
+	ExportProducts(ctx context.Context, req *entity.ExportProductsReq) (exporter.Stream, error)
+	ExportProductGrammages(ctx context.Context, req *entity.ExportProductGrammagesReq) (exporter.Stream, error)
+	ExportProductTransactions(ctx context.Context, req *entity.ExportProductTransactionsReq) (exporter.Stream, error)
 }
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"codebase-app/internal/module/product/entity"
+	"codebase-app/pkg/exporter"
+	"codebase-app/pkg/queryspec"
+	"context"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ExportProducts streams the products matching req, ordered by id.
+func (r *productRepo) ExportProducts(ctx context.Context, req *entity.ExportProductsReq) (*exporter.Cursor[entity.Product], error) {
+	var (
+		conds = make([]string, 0)
+		args  = make([]any, 0)
+	)
+
+	if req.Category != "" {
+		conds = append(conds, "p.category = ?")
+		args = append(args, req.Category)
+	}
+
+	if req.Level != "" {
+		conds = append(conds, "p.level = ?")
+		args = append(args, req.Level)
+	}
+
+	query := `
+		SELECT
+			p.id,
+			p.name,
+			COALESCE(p.category, '') AS category,
+			COALESCE(p.level, '') AS level
+		FROM products p
+	`
+
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	query += " ORDER BY p.id"
+
+	cursor, err := exporter.Open[entity.Product](ctx, r.db, r.db.Rebind(query), args)
+	if err != nil {
+		log.Error().Err(err).Msg("repo::ExportProducts - failed to export products")
+		return nil, err
+	}
+
+	return cursor, nil
+}
+
+// ExportProductGrammages streams the grammages matching req, ordered
+// by id.
+func (r *productRepo) ExportProductGrammages(ctx context.Context, req *entity.ExportProductGrammagesReq) (*exporter.Cursor[entity.ProductGrammage], error) {
+	var (
+		conds = make([]string, 0)
+		args  = make([]any, 0)
+	)
+
+	if req.Name != "" {
+		conds = append(conds, `pg.name ILIKE '%' || ? || '%' ESCAPE '\'`)
+		args = append(args, queryspec.EscapeLike(req.Name))
+	}
+
+	query := `
+		SELECT
+			pg.id,
+			pg.product_id,
+			pg.name,
+			pg.point,
+			pg.price
+		FROM product_grammages pg
+	`
+
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	query += " ORDER BY pg.id"
+
+	cursor, err := exporter.Open[entity.ProductGrammage](ctx, r.db, r.db.Rebind(query), args)
+	if err != nil {
+		log.Error().Err(err).Msg("repo::ExportProductGrammages - failed to export product grammages")
+		return nil, err
+	}
+
+	return cursor, nil
+}
+
+// ExportProductTransactions streams the transactions matching req to each,
+// ordered by date. Dates are written in UTC the way the import reads them.
+func (r *productRepo) ExportProductTransactions(ctx context.Context, req *entity.ExportProductTransactionsReq) (*exporter.Cursor[entity.ProductTransaction], error) {
+	var (
+		conds = make([]string, 0)
+		args  = make([]any, 0)
+	)
+
+	if !req.IncludeVoided {
+		conds = append(conds, "pt.voided_at IS NULL")
+	}
+
+	if req.MemberId != "" {
+		conds = append(conds, "pt.member_id = ?")
+		args = append(args, req.MemberId)
+	}
+
+	if req.ProductId != 0 {
+		conds = append(conds, "pt.product_id = ?")
+		args = append(args, req.ProductId)
+	}
+
+	if req.Source != "" {
+		conds = append(conds, "pt.source = ?")
+		args = append(args, req.Source)
+	}
+
+	if req.From != "" {
+		conds = append(conds, "pt.created_at >= ?::date")
+		args = append(args, req.From)
+	}
+
+	if req.To != "" {
+		conds = append(conds, "pt.created_at < ?::date + 1")
+		args = append(args, req.To)
+	}
+
+	query := `
+		SELECT
+			pt.id,
+			pt.member_id,
+			pt.product_id,
+			pt.product_grammage_id,
+			COALESCE(pt.source, '') AS source,
+			COALESCE(pt.qty, 0) AS qty,
+			pt.price_per_unit,
+			COALESCE(to_char(pt.created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD HH24:MI:SS "UTC"'), '') AS created_at,
+			COALESCE(pt.is_training_data, FALSE) AS is_training_data,
+			pt.voided_at
+		FROM product_transactions pt
+	`
+
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	query += " ORDER BY pt.created_at, pt.id"
+
+	cursor, err := exporter.Open[entity.ProductTransaction](ctx, r.db, r.db.Rebind(query), args)
+	if err != nil {
+		log.Error().Err(err).Msg("repo::ExportProductTransactions - failed to export product transactions")
+		return nil, err
+	}
+
+	return cursor, nil
+}
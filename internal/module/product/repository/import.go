@@ -0,0 +1,287 @@
+package repository
+
+import (
+	importJobEntity "codebase-app/internal/module/import_job/entity"
+	pointsRepository "codebase-app/internal/module/points/repository"
+	predictionRepository "codebase-app/internal/module/prediction/repository"
+	"codebase-app/internal/module/product/entity"
+	"codebase-app/pkg/importer"
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+// Imports copy the whole file into a staging table and merge it with one
+// statement, so a file commits or fails as a whole like it did with per-row
+// inserts. Existing keys are handled by the conflict strategy of the import.
+
+var productsTarget = importer.Target[entity.Product]{
+	Table:       "staging_products",
+	Like:        "products",
+	Key:         []string{"id"},
+	BatchColumn: "import_job_id",
+	Columns:     []string{"id", "name", "category", "level"},
+	Values: func(p *entity.Product) []any {
+		return []any{p.ProductId, p.ProductName, p.ProductCategory, p.ProductLevel}
+	},
+}
+
+var productGrammagesTarget = importer.Target[entity.ProductGrammage]{
+	Table:       "staging_product_grammages",
+	Like:        "product_grammages",
+	Key:         []string{"id"},
+	BatchColumn: "import_job_id",
+	Columns:     []string{"id", "product_id", "name", "point", "price"},
+	Values: func(g *entity.ProductGrammage) []any {
+		return []any{g.Id, g.ProductId, g.Name, g.Point, g.Price}
+	},
+	// files without the productID column keep the links of the grammages
+	KeepOnNull: []string{"product_id"},
+	Checks: []importer.Check{
+		{
+			Column:  "product_id",
+			Message: "produk tidak ditemukan.",
+			Where:   `s.product_id IS NOT NULL AND NOT EXISTS (SELECT 1 FROM products p WHERE p.id = s.product_id)`,
+		},
+	},
+}
+
+var productTransactionsTarget = importer.Target[entity.ProductTransaction]{
+	Table:       "staging_product_transactions",
+	Like:        "product_transactions",
+	Key:         []string{"id"},
+	BatchColumn: "import_job_id",
+	Columns: []string{
+		"id",
+		"member_id",
+		"product_id",
+		"product_grammage_id",
+		"source",
+		"qty",
+		"price_per_unit",
+		"created_at",
+	},
+	Values: func(t *entity.ProductTransaction) []any {
+		return []any{
+			t.Id,
+			t.MemberId,
+			t.ProductId,
+			t.ProductGrammageId,
+			t.Source,
+			t.Qty,
+			t.PricePerUnit,
+			t.CreatedAt,
+		}
+	},
+	Fixed: map[string]string{"is_training_data": "TRUE"},
+	// the references of a transaction, see product_transactions_grammage_check
+	Checks: []importer.Check{
+		{
+			Column:  "member_id",
+			Message: "member tidak ditemukan.",
+			Where:   `NOT EXISTS (SELECT 1 FROM members m WHERE m.id = s.member_id)`,
+		},
+		{
+			Column:  "product_id",
+			Message: "produk tidak ditemukan.",
+			Where:   `s.product_id IS NOT NULL AND NOT EXISTS (SELECT 1 FROM products p WHERE p.id = s.product_id)`,
+		},
+		{
+			Column:  "product_grammage_id",
+			Message: "gramasi tidak ditemukan.",
+			Where:   `s.product_grammage_id IS NOT NULL AND NOT EXISTS (SELECT 1 FROM product_grammages pg WHERE pg.id = s.product_grammage_id)`,
+		},
+		{
+			Column:  "product_grammage_id",
+			Message: "gramasi bukan milik produk ini.",
+			Where: `EXISTS (
+				SELECT 1 FROM product_grammages pg
+				WHERE pg.id = s.product_grammage_id
+					AND pg.product_id IS NOT NULL
+					AND pg.product_id IS DISTINCT FROM s.product_id
+			)`,
+		},
+	},
+	// Transactions that were imported before keep the points they already earned
+	AfterMerge: func(ctx context.Context, tx *sqlx.Tx) error {
+		_, err := pointsRepository.EarnStagedTransactionPoints(ctx, tx, "staging_product_transactions")
+		return err
+	},
+}
+
+func (r *productRepo) ImportProducts(ctx context.Context, src importer.Source, opts importer.Options[entity.Product]) (*importer.Result, error) {
+	res, err := importer.Import(ctx, r.db, productsTarget, src, opts)
+	if err != nil {
+		if !importer.IsInputError(err) {
+			log.Error().Err(err).Msg("repo::ImportProducts - failed to import")
+		}
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (r *productRepo) ImportProductGrammage(ctx context.Context, src importer.Source, opts importer.Options[entity.ProductGrammage]) (*importer.Result, error) {
+	res, err := importer.Import(ctx, r.db, productGrammagesTarget, src, opts)
+	if err != nil {
+		if !importer.IsInputError(err) {
+			log.Error().Err(err).Msg("repo::ImportProductGrammage - failed to import")
+		}
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// ImportProductTransactions imports transactions and returns the members
+// whose purchases changed with them.
+func (r *productRepo) ImportProductTransactions(ctx context.Context, src importer.Source, opts importer.Options[entity.ProductTransaction]) (*importer.Result, []string, error) {
+	memberIds := make([]string, 0)
+
+	target := productTransactionsTarget
+	target.AfterMerge = func(ctx context.Context, tx *sqlx.Tx) error {
+		if err := productTransactionsTarget.AfterMerge(ctx, tx); err != nil {
+			return err
+		}
+
+		query := `SELECT DISTINCT member_id FROM staging_product_transactions ORDER BY member_id`
+		return tx.SelectContext(ctx, &memberIds, query)
+	}
+
+	res, err := importer.Import(ctx, r.db, target, src, opts)
+	if err != nil {
+		if !importer.IsInputError(err) {
+			log.Error().Err(err).Msg("repo::ImportProductTransactions - failed to import")
+		}
+		return nil, nil, err
+	}
+
+	return res, memberIds, nil
+}
+
+// RollbackProductsImport deletes the products an import inserted. It fails
+// with ErrImportJobReferenced while transactions were recorded against them
+// or grammages belong to them.
+func (r *productRepo) RollbackProductsImport(ctx context.Context, id string) (int64, error) {
+	referenced := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM products p
+			WHERE p.import_job_id = ?
+				AND (
+					EXISTS (SELECT 1 FROM product_transactions pt WHERE pt.product_id = p.id)
+					OR EXISTS (SELECT 1 FROM product_grammages pg WHERE pg.product_id = p.id)
+				)
+		)
+	`
+
+	return r.rollbackImport(ctx, "products", id, referenced)
+}
+
+// RollbackProductGrammagesImport deletes the grammages an import inserted.
+// It fails with ErrImportJobReferenced while transactions were recorded
+// against them.
+func (r *productRepo) RollbackProductGrammagesImport(ctx context.Context, id string) (int64, error) {
+	referenced := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM product_transactions pt
+			JOIN product_grammages pg ON pg.id = pt.product_grammage_id
+			WHERE pg.import_job_id = ?
+		)
+	`
+
+	return r.rollbackImport(ctx, "product_grammages", id, referenced)
+}
+
+func (r *productRepo) rollbackImport(ctx context.Context, table, id, referenced string) (int64, error) {
+	var inUse bool
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Msg("repo::rollbackImport - failed to begin transaction")
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if err := tx.GetContext(ctx, &inUse, tx.Rebind(referenced), id); err != nil {
+		log.Error().Err(err).Str("table", table).Str("import_job_id", id).Msg("repo::rollbackImport - failed to check references")
+		return 0, err
+	}
+
+	if inUse {
+		return 0, importJobEntity.ErrImportJobReferenced
+	}
+
+	result, err := tx.ExecContext(ctx, tx.Rebind(`DELETE FROM `+table+` WHERE import_job_id = ?`), id)
+	if err != nil {
+		log.Error().Err(err).Str("table", table).Str("import_job_id", id).Msg("repo::rollbackImport - failed to delete rows")
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Msg("repo::rollbackImport - failed to commit transaction")
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// RollbackProductTransactionsImport deletes the transactions an import
+// inserted like a void would: their points are reversed and their
+// predictions unlinked. The journals stay in the ledger, detached, so the
+// corrected file earns again. It returns the members whose purchases changed.
+func (r *productRepo) RollbackProductTransactionsImport(ctx context.Context, id string) (int64, []string, error) {
+	memberIds := make([]string, 0)
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Msg("repo::RollbackProductTransactionsImport - failed to begin transaction")
+		return 0, nil, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		SELECT DISTINCT member_id
+		FROM (SELECT member_id FROM product_transactions WHERE import_job_id = ? FOR UPDATE) pt
+		ORDER BY member_id
+	`
+
+	if err := tx.SelectContext(ctx, &memberIds, tx.Rebind(query), id); err != nil {
+		log.Error().Err(err).Str("import_job_id", id).Msg("repo::RollbackProductTransactionsImport - failed to lock transactions")
+		return 0, nil, err
+	}
+
+	// the whole batch is reversed at once, voided transactions were reversed
+	// already and are skipped
+	if _, err := pointsRepository.ReverseTransactionsPoints(ctx, tx, "pt.import_job_id = ?", id); err != nil {
+		return 0, nil, err
+	}
+
+	if err := pointsRepository.DetachTransactionPoints(ctx, tx, "pt.import_job_id = ?", id); err != nil {
+		return 0, nil, err
+	}
+
+	if err := predictionRepository.UnlinkTransactionPredictions(ctx, tx, "pt.import_job_id = ?", id); err != nil {
+		return 0, nil, err
+	}
+
+	result, err := tx.ExecContext(ctx, tx.Rebind(`DELETE FROM product_transactions WHERE import_job_id = ?`), id)
+	if err != nil {
+		log.Error().Err(err).Str("import_job_id", id).Msg("repo::RollbackProductTransactionsImport - failed to delete transactions")
+		return 0, nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Msg("repo::RollbackProductTransactionsImport - failed to commit transaction")
+		return 0, nil, err
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return deleted, memberIds, nil
+}
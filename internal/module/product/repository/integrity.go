@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"codebase-app/internal/module/product/entity"
+	"codebase-app/pkg/exporter"
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/rs/zerolog/log"
+)
+
+// referenceConstraints are the foreign keys added NOT VALID, checked for new
+// rows only until validated.
+var referenceConstraints = []struct{ table, name string }{
+	{"product_grammages", "product_grammages_product_id_fkey"},
+	{"product_transactions", "product_transactions_member_id_fkey"},
+	{"product_transactions", "product_transactions_product_id_fkey"},
+	{"product_transactions", "product_transactions_product_grammage_id_fkey"},
+}
+
+func (r *productRepo) GetProduct(ctx context.Context, id int64) (*entity.Product, error) {
+	var res = new(entity.Product)
+
+	query := `
+		SELECT
+			p.id,
+			p.name,
+			COALESCE(p.category, '') AS category,
+			COALESCE(p.level, '') AS level
+		FROM products p
+		WHERE p.id = ?
+	`
+
+	if err := r.db.GetContext(ctx, res, r.db.Rebind(query), id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, entity.ErrProductNotFound
+		}
+		log.Error().Err(err).Int64("id", id).Msg("repo::GetProduct - failed to get product")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// GetOrphans streams the rows of the orphaned_rows view to each.
+func (r *productRepo) GetOrphans(ctx context.Context, each func([]entity.Orphan) error) error {
+	query := `
+		SELECT
+			table_name,
+			row_id,
+			column_name,
+			value,
+			reason
+		FROM orphaned_rows
+		ORDER BY table_name, column_name, reason, row_id
+	`
+
+	if err := exporter.Query(ctx, r.db, query, nil, each); err != nil {
+		log.Error().Err(err).Msg("repo::GetOrphans - failed to get orphaned rows")
+		return err
+	}
+
+	return nil
+}
+
+// ValidateReferences validates the NOT VALID foreign keys, which fails while
+// orphaned rows remain. Validation does not block writes to the tables.
+func (r *productRepo) ValidateReferences(ctx context.Context) error {
+	for _, c := range referenceConstraints {
+		if _, err := r.db.ExecContext(ctx, `ALTER TABLE `+c.table+` VALIDATE CONSTRAINT `+c.name); err != nil {
+			log.Error().Err(err).Str("constraint", c.name).Msg("repo::ValidateReferences - failed to validate constraint")
+			return err
+		}
+	}
+
+	return nil
+}
@@ -2,10 +2,17 @@ package repository
 
 import (
 	"codebase-app/internal/adapter"
+	pointsRepository "codebase-app/internal/module/points/repository"
+	predictionRepository "codebase-app/internal/module/prediction/repository"
 	"codebase-app/internal/module/product/entity"
 	"codebase-app/internal/module/product/ports"
+	"codebase-app/pkg/queryspec"
 	"context"
+	"database/sql"
+	"errors"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
@@ -24,133 +31,18 @@ func NewProductRepository() *productRepo {
 	}
 }
 
-func (r *productRepo) ImportProducts(ctx context.Context, req *entity.ImportProductsReq) error {
-	tx, err := r.db.BeginTxx(ctx, nil)
-	if err != nil {
-		log.Error().Err(err).Msg("failed to begin transaction")
-		return err
-	}
-
-	// Prepare the insert query
-	query := `
-		INSERT INTO products (id, name, category, level)
-		VALUES (:id, :name, :category, :level)
-	`
-
-	// Iterate over each product in req.Data and execute the insert query
-	for _, product := range req.Data {
-		_, err := tx.NamedExecContext(ctx, query, product)
-		if err != nil {
-			log.Error().Err(err).Any("product", product).Msg("failed to insert product")
-			tx.Rollback() // Roll back transaction on error
-			return err
-		}
-	}
-
-	// Commit the transaction
-	if err := tx.Commit(); err != nil {
-		log.Error().Err(err).Msg("failed to commit transaction")
-		return err
-	}
-
-	return nil
-}
-
-func (r *productRepo) ImportProductGrammage(ctx context.Context, req *entity.ImportProductGrammageReq) error {
-	tx, err := r.db.BeginTxx(ctx, nil)
-	if err != nil {
-		log.Error().Err(err).Msg("failed to begin transaction")
-		return err
-	}
-
-	// Prepare the insert query
-	query := `
-		INSERT INTO product_grammages (id, name, point, price)
-		VALUES (:id, :name, :point, :price)
-	`
-
-	// Iterate over each product in req.Data and execute the insert query
-	for _, productGrammage := range req.Data {
-		_, err := tx.NamedExecContext(ctx, query, productGrammage)
-		if err != nil {
-			log.Error().Err(err).Any("productGrammage", productGrammage).Msg("failed to insert product grammage")
-			tx.Rollback() // Roll back transaction on error
-			return err
-		}
-	}
-
-	// Commit the transaction
-	if err := tx.Commit(); err != nil {
-		log.Error().Err(err).Msg("failed to commit transaction")
-		return err
-	}
+func (r *productRepo) CreateProductTransaction(ctx context.Context, req *entity.CreateProductTransactionReq) error {
+	// Implement the logic to create a product transaction
 
-	return nil
-}
+	id := uuid.New()                                     // Generate a new UUID
+	idNoDash := strings.ReplaceAll(id.String(), "-", "") // Remove dashes
 
-func (r *productRepo) ImportProductTransactions(ctx context.Context, req *entity.ImportProductTransactionsReq) error {
 	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to begin transaction")
 		return err
 	}
-
-	// Prepare the insert query
-	query := `
-		INSERT INTO product_transactions (
-			id,
-			member_id,
-			product_id,
-			product_grammage_id,
-			source,
-			qty,
-			price_per_unit,
-			created_at,
-			is_training_data
-		) VALUES (
-		 	:id,
-			:member_id,
-			:product_id,
-			:product_grammage_id,
-			:source,
-			:qty,
-			:price_per_unit,
-			:created_at,
-			TRUE
-		) ON CONFLICT (id) DO UPDATE SET
-			member_id = EXCLUDED.member_id,
-			product_id = EXCLUDED.product_id,
-			product_grammage_id = EXCLUDED.product_grammage_id,
-			source = EXCLUDED.source,
-			qty = EXCLUDED.qty,
-			price_per_unit = EXCLUDED.price_per_unit,
-			created_at = EXCLUDED.created_at
-	`
-
-	// Iterate over each product in req.Data and execute the insert query
-	for _, productTransaction := range req.Data {
-		_, err := tx.NamedExecContext(ctx, query, productTransaction)
-		if err != nil {
-			log.Error().Err(err).Any("productTransaction", productTransaction).Msg("failed to insert product transaction")
-			tx.Rollback() // Roll back transaction on error
-			return err
-		}
-	}
-
-	// Commit the transaction
-	if err := tx.Commit(); err != nil {
-		log.Error().Err(err).Msg("failed to commit transaction")
-		return err
-	}
-
-	return nil
-}
-
-func (r *productRepo) CreateProductTransaction(ctx context.Context, req *entity.CreateProductTransactionReq) error {
-	// Implement the logic to create a product transaction
-
-	id := uuid.New()                                     // Generate a new UUID
-	idNoDash := strings.ReplaceAll(id.String(), "-", "") // Remove dashes
+	defer tx.Rollback()
 
 	query := `
 		INSERT INTO product_transactions (
@@ -176,7 +68,7 @@ func (r *productRepo) CreateProductTransaction(ctx context.Context, req *entity.
 		)
 	`
 
-	_, err := r.db.NamedExecContext(ctx, query, map[string]interface{}{
+	_, err = tx.NamedExecContext(ctx, query, map[string]interface{}{
 		"id":                  idNoDash,
 		"member_id":           req.MemberId,
 		"product_id":          req.ProductId,
@@ -191,10 +83,78 @@ func (r *productRepo) CreateProductTransaction(ctx context.Context, req *entity.
 		return err
 	}
 
+	if err := predictionRepository.LinkTransactionPredictions(ctx, tx, "pt.id = ?", idNoDash); err != nil {
+		return err
+	}
+
+	if _, err := pointsRepository.EarnTransactionPoints(ctx, tx, []string{idNoDash}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Msg("failed to commit transaction")
+		return err
+	}
+
 	return nil
 }
 
+// VoidProductTransaction marks a transaction as voided, takes back the points
+// it earned and unlinks it from the predictions it fulfilled, all at once.
+func (r *productRepo) VoidProductTransaction(ctx context.Context, req *entity.VoidProductTransactionReq) (*entity.VoidProductTransactionResp, error) {
+	var (
+		res      = new(entity.VoidProductTransactionResp)
+		voidedAt *time.Time
+	)
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to begin transaction")
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := `SELECT voided_at FROM product_transactions WHERE id = ? FOR UPDATE`
+	if err := tx.GetContext(ctx, &voidedAt, tx.Rebind(query), req.Id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, entity.ErrTransactionNotFound
+		}
+		log.Error().Err(err).Any("req", req).Msg("failed to lock product transaction")
+		return nil, err
+	}
+
+	if voidedAt != nil {
+		return nil, entity.ErrTransactionAlreadyVoided
+	}
+
+	query = `UPDATE product_transactions SET voided_at = NOW() WHERE id = ? RETURNING id, member_id, voided_at`
+	if err := tx.GetContext(ctx, res, tx.Rebind(query), req.Id); err != nil {
+		log.Error().Err(err).Any("req", req).Msg("failed to void product transaction")
+		return nil, err
+	}
+
+	if err := predictionRepository.UnlinkTransactionPredictions(ctx, tx, "pt.id = ?", req.Id); err != nil {
+		return nil, err
+	}
+
+	res.PointsReversed, err = pointsRepository.ReverseTransactionPoints(ctx, tx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Msg("failed to commit transaction")
+		return nil, err
+	}
+
+	return res, nil
+}
+
 func (r *productRepo) GetProducts(ctx context.Context, req *entity.GetProductsReq) (*entity.GetProductsResp, error) {
+	if req.CursorMode() {
+		return r.getProductsByCursor(ctx, req)
+	}
+
 	type dao struct {
 		TotalData int `db:"total_data"`
 		entity.Product
@@ -214,10 +174,14 @@ func (r *productRepo) GetProducts(ctx context.Context, req *entity.GetProductsRe
 			p.category,
 			p.level
 		FROM products p
-		LIMIT ? OFFSET ?
+		WHERE 1 = 1
 	`
 
-	err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), req.Paginate, (req.Page-1)*req.Paginate)
+	where, args := req.Spec.Where()
+	query += where + req.Spec.OrderBy("p.id") + ` LIMIT ? OFFSET ?`
+	args = append(args, req.Paginate, (req.Page-1)*req.Paginate)
+
+	err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), args...)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to get products")
 		return nil, err
@@ -237,6 +201,10 @@ func (r *productRepo) GetProducts(ctx context.Context, req *entity.GetProductsRe
 }
 
 func (r *productRepo) GetProductGrammages(ctx context.Context, req *entity.GetProductGrammagesReq) (*entity.GetProductGrammagesResp, error) {
+	if req.CursorMode() {
+		return r.getProductGrammagesByCursor(ctx, req)
+	}
+
 	type dao struct {
 		TotalData int `db:"total_data"`
 		entity.ProductGrammage
@@ -251,14 +219,19 @@ func (r *productRepo) GetProductGrammages(ctx context.Context, req *entity.GetPr
 		SELECT
 			COUNT(*) OVER() AS total_data,
 			pg.id,
+			pg.product_id,
 			pg.name,
 			pg.point,
 			pg.price
 		FROM product_grammages pg
-		LIMIT ? OFFSET ?
+		WHERE 1 = 1
 	`
 
-	err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), req.Paginate, (req.Page-1)*req.Paginate)
+	where, args := req.Spec.Where()
+	query += where + req.Spec.OrderBy("pg.id") + ` LIMIT ? OFFSET ?`
+	args = append(args, req.Paginate, (req.Page-1)*req.Paginate)
+
+	err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), args...)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to get product grammages")
 		return nil, err
@@ -278,6 +251,10 @@ func (r *productRepo) GetProductGrammages(ctx context.Context, req *entity.GetPr
 }
 
 func (r *productRepo) GetProductTransactions(ctx context.Context, req *entity.GetProductTransactionsReq) (*entity.GetProductTransactionsResp, error) {
+	if req.CursorMode() {
+		return r.getProductTransactionsByCursor(ctx, req)
+	}
+
 	type dao struct {
 		TotalData int `db:"total_data"`
 		entity.ProductTransaction
@@ -299,12 +276,17 @@ func (r *productRepo) GetProductTransactions(ctx context.Context, req *entity.Ge
 			pt.qty,
 			pt.price_per_unit,
 			pt.created_at,
-			pt.is_training_data
+			pt.is_training_data,
+			pt.voided_at
 		FROM product_transactions pt
-		LIMIT ? OFFSET ?
+		WHERE 1 = 1
 	`
 
-	err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), req.Paginate, (req.Page-1)*req.Paginate)
+	where, args := req.Spec.Where()
+	query += where + req.Spec.OrderBy("pt.id") + ` LIMIT ? OFFSET ?`
+	args = append(args, req.Paginate, (req.Page-1)*req.Paginate)
+
+	err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), args...)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to get product transactions")
 		return nil, err
@@ -322,3 +304,112 @@ func (r *productRepo) GetProductTransactions(ctx context.Context, req *entity.Ge
 
 	return res, nil
 }
+
+// getProductsByCursor pages products by ProductKeyset in cursor mode.
+func (r *productRepo) getProductsByCursor(ctx context.Context, req *entity.GetProductsReq) (*entity.GetProductsResp, error) {
+	query := `
+		SELECT
+			p.id,
+			p.name,
+			p.category,
+			p.level
+		FROM products p
+		WHERE 1 = 1
+	`
+
+	var (
+		res = new(entity.GetProductsResp)
+		err error
+	)
+
+	res.Items, res.Meta, err = queryspec.SelectPage(ctx, r.db, query, queryspec.PageReq{
+		Keyset:   entity.ProductKeyset,
+		Spec:     req.Spec,
+		Cursor:   req.Cursor,
+		Paginate: req.Paginate,
+		Count:    req.Count,
+	}, func(item entity.Product) []string {
+		return []string{strconv.Itoa(item.ProductId)}
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("repo::GetProducts - failed to get products")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// getProductGrammagesByCursor pages product grammages by ProductGrammageKeyset in cursor mode.
+func (r *productRepo) getProductGrammagesByCursor(ctx context.Context, req *entity.GetProductGrammagesReq) (*entity.GetProductGrammagesResp, error) {
+	query := `
+		SELECT
+			pg.id,
+			pg.product_id,
+			pg.name,
+			pg.point,
+			pg.price
+		FROM product_grammages pg
+		WHERE 1 = 1
+	`
+
+	var (
+		res = new(entity.GetProductGrammagesResp)
+		err error
+	)
+
+	res.Items, res.Meta, err = queryspec.SelectPage(ctx, r.db, query, queryspec.PageReq{
+		Keyset:   entity.ProductGrammageKeyset,
+		Spec:     req.Spec,
+		Cursor:   req.Cursor,
+		Paginate: req.Paginate,
+		Count:    req.Count,
+	}, func(item entity.ProductGrammage) []string {
+		return []string{strconv.Itoa(item.Id)}
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("repo::GetProductGrammages - failed to get product grammages")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// getProductTransactionsByCursor pages product transactions by ProductTransactionKeyset in cursor mode.
+func (r *productRepo) getProductTransactionsByCursor(ctx context.Context, req *entity.GetProductTransactionsReq) (*entity.GetProductTransactionsResp, error) {
+	query := `
+		SELECT
+			pt.id,
+			pt.member_id,
+			pt.product_id,
+			pt.product_grammage_id,
+			pt.source,
+			pt.qty,
+			pt.price_per_unit,
+			pt.created_at,
+			pt.is_training_data,
+			pt.voided_at
+		FROM product_transactions pt
+		WHERE 1 = 1
+	`
+
+	var (
+		res = new(entity.GetProductTransactionsResp)
+		err error
+	)
+
+	res.Items, res.Meta, err = queryspec.SelectPage(ctx, r.db, query, queryspec.PageReq{
+		Keyset:   entity.ProductTransactionKeyset,
+		Spec:     req.Spec,
+		Cursor:   req.Cursor,
+		Paginate: req.Paginate,
+		Count:    req.Count,
+	}, func(item entity.ProductTransaction) []string {
+		return []string{item.CreatedAt, item.Id}
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("repo::GetProductTransactions - failed to get product transactions")
+		return nil, err
+	}
+
+	return res, nil
+}
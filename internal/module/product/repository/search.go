@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"codebase-app/internal/module/product/entity"
+	"context"
+
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+)
+
+// SearchProducts pages the products matching tsquery, a to_tsquery
+// expression, best ranked first.
+func (r *productRepo) SearchProducts(ctx context.Context, req *entity.SearchProductsReq, tsquery string) (*entity.SearchProductsResp, error) {
+	type dao struct {
+		TotalData int `db:"total_data"`
+		entity.ProductSearchResult
+	}
+
+	var (
+		data = make([]dao, 0)
+		res  = new(entity.SearchProductsResp)
+	)
+	res.Items = make([]entity.ProductSearchResult, 0)
+
+	// the headline is only built for the page, it reparses each document.
+	// The document is HTML escaped first so only the <b> tags of the
+	// headline are markup, the parser reads the escapes as entities.
+	query := `
+		WITH q AS (
+			SELECT to_tsquery('simple', ?) AS query
+		)
+		SELECT
+			r.total_data,
+			r.id,
+			r.name,
+			r.category,
+			r.level,
+			r.rank,
+			ts_headline(
+				'simple',
+				replace(replace(replace(replace(
+					concat_ws(' · ', r.name, r.category, r.level),
+					'&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'),
+				q.query,
+				'StartSel=<b>, StopSel=</b>, HighlightAll=true'
+			) AS highlight
+		FROM (
+			SELECT
+				COUNT(*) OVER() AS total_data,
+				p.id,
+				p.name,
+				COALESCE(p.category, '') AS category,
+				COALESCE(p.level, '') AS level,
+				ts_rank(p.search_vector, q.query) AS rank
+			FROM products p, q
+			WHERE p.search_vector @@ q.query
+			ORDER BY rank DESC, p.id
+			LIMIT ? OFFSET ?
+		) r, q
+		ORDER BY r.rank DESC, r.id
+	`
+
+	err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), tsquery, req.Paginate, (req.Page-1)*req.Paginate)
+	if err != nil {
+		log.Error().Err(err).Msg("repo::SearchProducts - failed to search products")
+		return nil, err
+	}
+
+	for _, d := range data {
+		res.Items = append(res.Items, d.ProductSearchResult)
+	}
+
+	if len(res.Items) > 0 {
+		res.Meta.TotalData = data[0].TotalData
+	}
+
+	res.Meta.CountTotalPage(req.Page, req.Paginate, res.Meta.TotalData)
+
+	return res, nil
+}
+
+// GetGrammagesByProducts is the grammages of each of ids, by product id.
+func (r *productRepo) GetGrammagesByProducts(ctx context.Context, ids []int) (map[int][]entity.ProductGrammage, error) {
+	var (
+		data = make([]entity.ProductGrammage, 0)
+		res  = make(map[int][]entity.ProductGrammage, len(ids))
+	)
+
+	query := `
+		SELECT
+			pg.id,
+			pg.product_id,
+			pg.name,
+			pg.point,
+			pg.price
+		FROM product_grammages pg
+		WHERE pg.product_id = ANY(?)
+		ORDER BY pg.product_id, pg.id
+	`
+
+	if err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), pq.Array(ids)); err != nil {
+		log.Error().Err(err).Msg("repo::GetGrammagesByProducts - failed to get product grammages")
+		return nil, err
+	}
+
+	for _, d := range data {
+		res[int(*d.ProductId)] = append(res[int(*d.ProductId)], d)
+	}
+
+	return res, nil
+}
@@ -2,234 +2,326 @@ package service
 
 import (
 	"codebase-app/internal/adapter"
+	importJobEntity "codebase-app/internal/module/import_job/entity"
+	importJobPorts "codebase-app/internal/module/import_job/ports"
 	"codebase-app/internal/module/product/entity"
 	"codebase-app/internal/module/product/ports"
+	tierEntity "codebase-app/internal/module/tier/entity"
+	tierPorts "codebase-app/internal/module/tier/ports"
+	"codebase-app/pkg"
 	"codebase-app/pkg/errmsg"
+	"codebase-app/pkg/exporter"
+	"codebase-app/pkg/importer"
 	"context"
-	"fmt"
-	"os"
+	"errors"
+	"io"
+	"strings"
+	"unicode"
 
-	"github.com/gocarina/gocsv"
-	"github.com/oklog/ulid/v2"
 	"github.com/rs/zerolog/log"
 )
 
 var _ ports.ProductService = &productService{}
 
 type productService struct {
-	repo ports.ProductRepository
+	repo    ports.ProductRepository
+	tier    tierPorts.TierService
+	imports importJobPorts.ImportJobService
 }
 
-func NewProductService(repo ports.ProductRepository) *productService {
+func NewProductService(repo ports.ProductRepository, tier tierPorts.TierService, imports importJobPorts.ImportJobService) *productService {
 	return &productService{
-		repo: repo,
+		repo:    repo,
+		tier:    tier,
+		imports: imports,
 	}
 }
 
-func (s *productService) ImportProducts(ctx context.Context, req *entity.ImportProductsReq) error {
-	v := adapter.Adapters.Validator
-
+func (s *productService) ImportProducts(ctx context.Context, req *entity.ImportProductsReq) (*importJobEntity.ImportJob, error) {
 	if req.File == nil {
 		log.Warn().Any("req", req).Msg("service::importProduct - Missing file")
-		return errmsg.NewCustomErrors(400).SetMessage("Missing file")
-	}
-
-	file, err := req.File.Open()
-	if err != nil {
-		log.Warn().Err(err).Any("req", req).Msg("service::importProduct - Failed to open file")
-		return err
+		return nil, errmsg.NewCustomErrors(400).SetMessage("Missing file")
 	}
-	defer file.Close()
 
-	// convert multipart.File to *os.File
-	f, err := os.Create(ulid.Make().String() + "_products.csv")
-	if err != nil {
-		log.Warn().Err(err).Any("req", req).Msg("service::importProduct - Failed to create file")
-		return errmsg.NewCustomErrors(400).SetMessage("Failed to create file")
-	}
-	defer func() {
-		f.Close()
-		err = os.Remove(f.Name())
-		if err != nil {
-			log.Warn().Err(err).Any("file", f.Name()).Msg("service::importProduct - Failed to remove file")
-		}
-	}()
+	return s.imports.Enqueue(ctx, importJobEntity.KindProducts, req.File, importJobEntity.ImportJobOptions{
+		DryRun:     req.DryRun,
+		Sheet:      req.Sheet,
+		OnConflict: req.OnConflict,
+		UploadedBy: req.UploadedBy,
+		Profile:    req.Profile,
+	})
+}
 
-	// insert the file content to the new file
-	if _, err := file.Seek(0, 0); err != nil {
-		log.Warn().Err(err).Any("req", req).Msg("service::importProduct - Failed to seek file")
-		return errmsg.NewCustomErrors(400).SetMessage("Failed to seek file")
+func (s *productService) ImportProductGrammage(ctx context.Context, req *entity.ImportProductGrammageReq) (*importJobEntity.ImportJob, error) {
+	if req.File == nil {
+		log.Warn().Any("req", req).Msg("service::importProductGrammage - Missing file")
+		return nil, errmsg.NewCustomErrors(400).SetMessage("Missing file")
 	}
 
-	if _, err := f.ReadFrom(file); err != nil {
-		log.Warn().Err(err).Any("req", req).Msg("service::importProduct - Failed to read file")
-		return errmsg.NewCustomErrors(400).SetMessage("Failed to read file")
-	}
+	return s.imports.Enqueue(ctx, importJobEntity.KindProductGrammages, req.File, importJobEntity.ImportJobOptions{
+		DryRun:     req.DryRun,
+		Sheet:      req.Sheet,
+		OnConflict: req.OnConflict,
+		UploadedBy: req.UploadedBy,
+		Profile:    req.Profile,
+	})
+}
 
-	if _, err := f.Seek(0, 0); err != nil {
-		log.Warn().Err(err).Any("req", req).Msg("service::importProduct - Failed to seek file")
-		return errmsg.NewCustomErrors(400).SetMessage("Failed to seek file")
+func (s *productService) ImportProductTransactions(ctx context.Context, req *entity.ImportProductTransactionsReq) (*importJobEntity.ImportJob, error) {
+	if req.File == nil {
+		log.Warn().Any("req", req).Msg("service::importProductTransactions - Missing file")
+		return nil, errmsg.NewCustomErrors(400).SetMessage("Missing file")
 	}
 
-	products := []*entity.Product{}
+	return s.imports.Enqueue(ctx, importJobEntity.KindProductTransactions, req.File, importJobEntity.ImportJobOptions{
+		DryRun:     req.DryRun,
+		Sheet:      req.Sheet,
+		OnConflict: req.OnConflict,
+		UploadedBy: req.UploadedBy,
+		Profile:    req.Profile,
+	})
+}
 
-	if err := gocsv.UnmarshalFile(f, &products); err != nil {
-		log.Warn().Err(err).Any("req", req).Msg("service::importProduct - Failed to import product")
-		return errmsg.NewCustomErrors(400).SetMessage("Failed to read CSV file")
-	}
+func (s *productService) RunProductsImport(ctx context.Context, src importer.Source, settings importer.Settings) (*importer.Result, error) {
+	v := adapter.Adapters.Validator
 
-	req.Data = products
+	return s.repo.ImportProducts(ctx, src, importer.Options[entity.Product]{
+		Settings: settings,
+		Validate: func(row *entity.Product) error {
+			return importer.Validate(v, row)
+		},
+	})
+}
 
-	for i, product := range products {
-		if err := v.Validate(product); err != nil {
-			log.Warn().Err(err).Any("req", req).Msg("service::importProduct - Invalid product data")
-			return errmsg.NewCustomErrors(400).SetMessage(fmt.Sprintf("Invalid product data at row %d", i+2))
-		}
-	}
+func (s *productService) RunProductGrammagesImport(ctx context.Context, src importer.Source, settings importer.Settings) (*importer.Result, error) {
+	v := adapter.Adapters.Validator
 
-	return s.repo.ImportProducts(ctx, req)
+	return s.repo.ImportProductGrammage(ctx, src, importer.Options[entity.ProductGrammage]{
+		Settings: settings,
+		Validate: func(row *entity.ProductGrammage) error {
+			return importer.Validate(v, row)
+		},
+	})
 }
 
-func (s *productService) ImportProductGrammage(ctx context.Context, req *entity.ImportProductGrammageReq) error {
+func (s *productService) RunProductTransactionsImport(ctx context.Context, src importer.Source, settings importer.Settings) (*importer.Result, error) {
 	v := adapter.Adapters.Validator
 
-	if req.File == nil {
-		log.Warn().Any("req", req).Msg("service::importProductGrammage - Missing file")
-		return errmsg.NewCustomErrors(400).SetMessage("Missing file")
-	}
-
-	file, err := req.File.Open()
+	res, memberIds, err := s.repo.ImportProductTransactions(ctx, src, importer.Options[entity.ProductTransaction]{
+		Settings: settings,
+		Validate: func(row *entity.ProductTransaction) error {
+			return importer.Validate(v, row)
+		},
+	})
 	if err != nil {
-		log.Warn().Err(err).Any("req", req).Msg("service::importProductGrammage - Failed to open file")
-		return err
+		return nil, err
 	}
-	defer file.Close()
 
-	// convert multipart.File to *os.File
-	f, err := os.Create(ulid.Make().String() + "_product_grammages.csv")
-	if err != nil {
-		log.Warn().Err(err).Any("req", req).Msg("service::importProductGrammage - Failed to create file")
-		return errmsg.NewCustomErrors(400).SetMessage("Failed to create file")
-	}
-	defer func() {
-		f.Close()
-		err = os.Remove(f.Name())
-		if err != nil {
-			log.Warn().Err(err).Any("file", f.Name()).Msg("service::importProductGrammage - Failed to remove file")
+	// a dry run is rolled back, the tiers are left as they are
+	if !settings.DryRun {
+		for _, memberId := range memberIds {
+			s.evaluateTier(ctx, memberId)
 		}
-	}()
-
-	// insert the file content to the new file
-	if _, err := file.Seek(0, 0); err != nil {
-		log.Warn().Err(err).Any("req", req).Msg("service::importProductGrammage - Failed to seek file")
-		return errmsg.NewCustomErrors(400).SetMessage("Failed to seek file")
 	}
 
-	if _, err := f.ReadFrom(file); err != nil {
-		log.Warn().Err(err).Any("req", req).Msg("service::importProductGrammage - Failed to read file")
-		return errmsg.NewCustomErrors(400).SetMessage("Failed to read file")
+	return res, nil
+}
+
+func (s *productService) RollbackProductsImport(ctx context.Context, id string) (int64, error) {
+	return s.repo.RollbackProductsImport(ctx, id)
+}
+
+func (s *productService) RollbackProductGrammagesImport(ctx context.Context, id string) (int64, error) {
+	return s.repo.RollbackProductGrammagesImport(ctx, id)
+}
+
+func (s *productService) RollbackProductTransactionsImport(ctx context.Context, id string) (int64, error) {
+	deleted, memberIds, err := s.repo.RollbackProductTransactionsImport(ctx, id)
+	if err != nil {
+		return 0, err
 	}
 
-	if _, err := f.Seek(0, 0); err != nil {
-		log.Warn().Err(err).Any("req", req).Msg("service::importProductGrammage - Failed to seek file")
-		return errmsg.NewCustomErrors(400).SetMessage("Failed to seek file")
+	for _, memberId := range memberIds {
+		s.evaluateTier(ctx, memberId)
 	}
 
-	grammages := []*entity.ProductGrammage{}
+	return deleted, nil
+}
 
-	if err := gocsv.UnmarshalFile(f, &grammages); err != nil {
-		log.Warn().Err(err).Any("req", req).Msg("service::importProductGrammage - Failed to import product grammage")
-		return errmsg.NewCustomErrors(400).SetMessage("Failed to read CSV file")
+func (s *productService) CreateProductTransaction(ctx context.Context, req *entity.CreateProductTransactionReq) error {
+	if err := s.repo.CreateProductTransaction(ctx, req); err != nil {
+		return err
 	}
 
-	req.Data = grammages
+	s.evaluateTier(ctx, req.MemberId)
 
-	for i, grammage := range grammages {
-		if err := v.Validate(grammage); err != nil {
-			log.Warn().Err(err).Any("payload", grammage).Msg("service::importProductGrammage - Invalid product grammage data")
-			return errmsg.NewCustomErrors(400).SetMessage(fmt.Sprintf("Invalid product grammage data at row %d", i+2))
+	return nil
+}
+
+func (s *productService) VoidProductTransaction(ctx context.Context, req *entity.VoidProductTransactionReq) (*entity.VoidProductTransactionResp, error) {
+	res, err := s.repo.VoidProductTransaction(ctx, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, entity.ErrTransactionNotFound):
+			log.Warn().Any("req", req).Msg("service::VoidProductTransaction - Product transaction not found")
+			return nil, errmsg.NewCustomErrors(404).SetMessage("Product transaction not found")
+		case errors.Is(err, entity.ErrTransactionAlreadyVoided):
+			log.Warn().Any("req", req).Msg("service::VoidProductTransaction - Product transaction already voided")
+			return nil, errmsg.NewCustomErrors(409).SetMessage("Product transaction already voided")
 		}
+		return nil, err
 	}
 
-	return s.repo.ImportProductGrammage(ctx, req)
+	s.evaluateTier(ctx, res.MemberId)
+
+	return res, nil
 }
 
-func (s *productService) ImportProductTransactions(ctx context.Context, req *entity.ImportProductTransactionsReq) error {
-	v := adapter.Adapters.Validator
+// evaluateTier refreshes the tier of a member after their purchases changed.
+// The transaction is already committed, so a failure is only logged and the
+// nightly evaluation catches up.
+func (s *productService) evaluateTier(ctx context.Context, memberId string) {
+	if err := s.tier.EvaluateMember(ctx, memberId, tierEntity.ReasonTransaction); err != nil {
+		log.Error().Err(err).Str("member_id", memberId).Msg("service::evaluateTier - Failed to evaluate member tier")
+	}
+}
 
-	if req.File == nil {
-		log.Warn().Any("req", req).Msg("service::importProductTransactions - Missing file")
-		return errmsg.NewCustomErrors(400).SetMessage("Missing file")
+func (s *productService) GetProducts(ctx context.Context, req *entity.GetProductsReq) (*entity.GetProductsResp, error) {
+	return s.repo.GetProducts(ctx, req)
+}
+
+func (s *productService) GetProductTransactions(ctx context.Context, req *entity.GetProductTransactionsReq) (*entity.GetProductTransactionsResp, error) {
+	return s.repo.GetProductTransactions(ctx, req)
+}
+
+func (s *productService) GetProductGrammages(ctx context.Context, req *entity.GetProductGrammagesReq) (*entity.GetProductGrammagesResp, error) {
+	return s.repo.GetProductGrammages(ctx, req)
+}
+
+// SearchProducts matches each word of the query as a prefix, so partial
+// names are found, and ranks products matching more words higher.
+// Punctuation only separates words.
+func (s *productService) SearchProducts(ctx context.Context, req *entity.SearchProductsReq) (*entity.SearchProductsResp, error) {
+	words := strings.FieldsFunc(req.Q, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	if len(words) == 0 {
+		res := &entity.SearchProductsResp{Items: make([]entity.ProductSearchResult, 0)}
+		res.Meta.CountTotalPage(req.Page, req.Paginate, 0)
+		return res, nil
 	}
 
-	file, err := req.File.Open()
+	res, err := s.repo.SearchProducts(ctx, req, pkg.FormatKeywords(strings.Join(words, " ")))
 	if err != nil {
-		log.Warn().Err(err).Any("req", req).Msg("service::importProductTransactions - Failed to open file")
-		return err
+		return nil, err
+	}
+
+	ids := make([]int, 0, len(res.Items))
+	for _, item := range res.Items {
+		ids = append(ids, item.ProductId)
 	}
-	defer file.Close()
 
-	// convert multipart.File to *os.File
-	f, err := os.Create(ulid.Make().String() + "_product_transactions.csv")
+	grammages, err := s.repo.GetGrammagesByProducts(ctx, ids)
 	if err != nil {
-		log.Warn().Err(err).Any("req", req).Msg("service::importProductTransactions - Failed to create file")
-		return errmsg.NewCustomErrors(400).SetMessage("Failed to create file")
-	}
-	defer func() {
-		f.Close()
-		err = os.Remove(f.Name())
-		if err != nil {
-			log.Warn().Err(err).Any("file", f.Name()).Msg("service::importProductTransactions - Failed to remove file")
+		return nil, err
+	}
+
+	for i := range res.Items {
+		res.Items[i].Grammages = grammages[res.Items[i].ProductId]
+		if res.Items[i].Grammages == nil {
+			res.Items[i].Grammages = make([]entity.ProductGrammage, 0)
 		}
-	}()
+	}
+
+	return res, nil
+}
 
-	// insert the file content to the new file
-	if _, err := file.Seek(0, 0); err != nil {
-		log.Warn().Err(err).Any("req", req).Msg("service::importProductTransactions - Failed to seek file")
-		return errmsg.NewCustomErrors(400).SetMessage("Failed to seek file")
+func (s *productService) GetProduct(ctx context.Context, req *entity.GetProductReq) (*entity.GetProductResp, error) {
+	product, err := s.repo.GetProduct(ctx, req.Id)
+	if err != nil {
+		if errors.Is(err, entity.ErrProductNotFound) {
+			log.Warn().Any("req", req).Msg("service::GetProduct - Product not found")
+			return nil, errmsg.NewCustomErrors(404).SetMessage("Product not found")
+		}
+		return nil, err
 	}
 
-	if _, err := f.ReadFrom(file); err != nil {
-		log.Warn().Err(err).Any("req", req).Msg("service::importProductTransactions - Failed to read file")
-		return errmsg.NewCustomErrors(400).SetMessage("Failed to read file")
+	grammages, err := s.repo.GetGrammagesByProducts(ctx, []int{product.ProductId})
+	if err != nil {
+		return nil, err
 	}
 
-	if _, err := f.Seek(0, 0); err != nil {
-		log.Warn().Err(err).Any("req", req).Msg("service::importProductTransactions - Failed to seek file")
-		return errmsg.NewCustomErrors(400).SetMessage("Failed to seek file")
+	res := &entity.GetProductResp{Product: *product, Grammages: grammages[product.ProductId]}
+	if res.Grammages == nil {
+		res.Grammages = make([]entity.ProductGrammage, 0)
 	}
 
-	transactions := []*entity.ProductTransaction{}
+	return res, nil
+}
+
+func (s *productService) ReportOrphans(ctx context.Context, w io.Writer, validate bool) (map[string]int, error) {
+	out, err := exporter.NewWriter[entity.Orphan](w, exporter.FormatCSV)
+	if err != nil {
+		return nil, err
+	}
 
-	if err := gocsv.UnmarshalFile(f, &transactions); err != nil {
-		log.Warn().Err(err).Any("req", req).Msg("service::importProductTransactions - Failed to import product transactions")
-		return errmsg.NewCustomErrors(400).SetMessage("Failed to read CSV file")
+	var (
+		counts = make(map[string]int)
+		// unlinked grammages and mismatches break no key, missing rows do
+		missing int
+	)
+
+	err = s.repo.GetOrphans(ctx, func(orphans []entity.Orphan) error {
+		for _, o := range orphans {
+			counts[o.Table+"."+o.Column+" "+o.Reason]++
+			if o.Reason == "missing" {
+				missing++
+			}
+		}
+		return out.Write(orphans)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	req.Data = transactions
+	if err := out.Close(); err != nil {
+		return nil, err
+	}
 
-	for i, transaction := range transactions {
-		if err := v.Validate(transaction); err != nil {
-			log.Warn().Err(err).Any("payload", transaction).Msg("service::importProductTransactions - Invalid product transaction data ")
-			return errmsg.NewCustomErrors(400).SetMessage(fmt.Sprintf("Invalid product transaction data at row %d", i+2))
+	if validate && missing == 0 {
+		if err := s.repo.ValidateReferences(ctx); err != nil {
+			return counts, err
 		}
+		log.Info().Msg("service::ReportOrphans - Foreign keys validated")
 	}
 
-	return s.repo.ImportProductTransactions(ctx, req)
+	return counts, nil
 }
 
-func (s *productService) CreateProductTransaction(ctx context.Context, req *entity.CreateProductTransactionReq) error {
-	return s.repo.CreateProductTransaction(ctx, req)
-}
+func (s *productService) ExportProducts(ctx context.Context, req *entity.ExportProductsReq) (exporter.Stream, error) {
+	cursor, err := s.repo.ExportProducts(ctx, req)
+	if err != nil {
+		return nil, err
+	}
 
-func (s *productService) GetProducts(ctx context.Context, req *entity.GetProductsReq) (*entity.GetProductsResp, error) {
-	return s.repo.GetProducts(ctx, req)
+	return exporter.NewStream(cursor, req.Format), nil
 }
 
-func (s *productService) GetProductTransactions(ctx context.Context, req *entity.GetProductTransactionsReq) (*entity.GetProductTransactionsResp, error) {
-	return s.repo.GetProductTransactions(ctx, req)
+func (s *productService) ExportProductGrammages(ctx context.Context, req *entity.ExportProductGrammagesReq) (exporter.Stream, error) {
+	cursor, err := s.repo.ExportProductGrammages(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return exporter.NewStream(cursor, req.Format), nil
 }
 
-func (s *productService) GetProductGrammages(ctx context.Context, req *entity.GetProductGrammagesReq) (*entity.GetProductGrammagesResp, error) {
-	return s.repo.GetProductGrammages(ctx, req)
+func (s *productService) ExportProductTransactions(ctx context.Context, req *entity.ExportProductTransactionsReq) (exporter.Stream, error) {
+	cursor, err := s.repo.ExportProductTransactions(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return exporter.NewStream(cursor, req.Format), nil
 }
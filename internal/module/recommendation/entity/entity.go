@@ -0,0 +1,101 @@
+package entity
+
+import (
+	"codebase-app/pkg/types"
+	"time"
+)
+
+const (
+	ChildYoungest = "youngest"
+	ChildEldest   = "eldest"
+)
+
+type GetMemberRecommendationsReq struct {
+	MemberId string `params:"id" validate:"required"`
+}
+
+type GetMemberRecommendationsResp struct {
+	MemberId string           `json:"member_id"`
+	Items    []Recommendation `json:"items"`
+}
+
+type GetTransitionsReq struct {
+	Days     int    `query:"days" validate:"omitempty,min=1,max=365"`
+	City     string `query:"city"`
+	Page     int    `query:"page" validate:"required,numeric"`
+	Paginate int    `query:"paginate" validate:"required,numeric"`
+}
+
+func (r *GetTransitionsReq) SetDefault() {
+	if r.Days < 1 {
+		r.Days = 30
+	}
+
+	if r.Page < 1 {
+		r.Page = 1
+	}
+
+	if r.Paginate < 1 {
+		r.Paginate = 10
+	}
+}
+
+type GetTransitionsResp struct {
+	Items []Transition `json:"items"`
+	Meta  types.Meta   `json:"meta"`
+}
+
+type Transition struct {
+	MemberId string  `json:"member_id"`
+	City     *string `json:"city"`
+	Recommendation
+}
+
+// Recommendation describes where one child stands and what to offer for the next stage.
+type Recommendation struct {
+	Child               string           `json:"child"`
+	BirthDate           string           `json:"birth_date"`
+	AgeMonths           int              `json:"age_months"`
+	CurrentLevel        *string          `json:"current_level"`
+	NextLevel           *string          `json:"next_level"`
+	TransitionDate      *string          `json:"transition_date"`
+	DaysUntilTransition *int             `json:"days_until_transition"`
+	Due                 bool             `json:"due"`
+	Product             *RecommendedItem `json:"product"`
+}
+
+type RecommendedItem struct {
+	ProductId         int64   `db:"product_id" json:"product_id"`
+	ProductName       *string `db:"product_name" json:"product_name"`
+	Category          *string `db:"category" json:"category"`
+	ProductGrammageId int64   `db:"product_grammage_id" json:"product_grammage_id"`
+	GrammageName      *string `db:"grammage_name" json:"grammage_name"`
+}
+
+type LifeStageRule struct {
+	Level        string `db:"level"`
+	MinAgeMonths int    `db:"min_age_months"`
+	MaxAgeMonths int    `db:"max_age_months"`
+	LeadDays     int    `db:"lead_days"`
+}
+
+// Child is a dated child of a member; members only record the eldest and youngest.
+type Child struct {
+	MemberId  string    `db:"member_id"`
+	City      *string   `db:"city"`
+	Child     string    `db:"child"`
+	BirthDate time.Time `db:"birth_date"`
+}
+
+// LevelItem is a product and grammage bought at a level, with how often it was bought.
+type LevelItem struct {
+	Level     string `db:"level"`
+	Purchases int    `db:"purchases"`
+	RecommendedItem
+}
+
+type MemberCategory struct {
+	MemberId  string `db:"member_id"`
+	Category  string `db:"category"`
+	Purchases int    `db:"purchases"`
+}
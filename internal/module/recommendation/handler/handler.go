@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"codebase-app/internal/adapter"
+	"codebase-app/internal/module/recommendation/entity"
+	"codebase-app/internal/module/recommendation/ports"
+	"codebase-app/internal/module/recommendation/repository"
+	"codebase-app/internal/module/recommendation/service"
+	"codebase-app/pkg/errmsg"
+	"codebase-app/pkg/response"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+type recommendationHandler struct {
+	service ports.RecommendationService
+}
+
+func NewRecommendationHandler() *recommendationHandler {
+	var (
+		repo    = repository.NewRecommendationRepository()
+		service = service.NewRecommendationService(repo)
+		handler = new(recommendationHandler)
+	)
+	handler.service = service
+
+	return handler
+}
+
+func (h *recommendationHandler) Register(router fiber.Router) {
+	router.Get("/members/:id/recommendations", h.getMemberRecommendations)
+	router.Get("/recommendations/transitions", h.getTransitions)
+}
+
+func (h *recommendationHandler) getMemberRecommendations(c *fiber.Ctx) error {
+	var (
+		req = new(entity.GetMemberRecommendationsReq)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+	)
+
+	if err := c.ParamsParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::getMemberRecommendations - Failed to parse params")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("req", req).Msg("handler::getMemberRecommendations - Invalid request params")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	resp, err := h.service.GetMemberRecommendations(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.JSON(response.Success(resp, ""))
+}
+
+func (h *recommendationHandler) getTransitions(c *fiber.Ctx) error {
+	var (
+		req = new(entity.GetTransitionsReq)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+	)
+
+	if err := c.QueryParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::getTransitions - Failed to parse query")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.SetDefault()
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("req", req).Msg("handler::getTransitions - Invalid request query")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	resp, err := h.service.GetTransitions(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.JSON(response.Success(resp, ""))
+}
@@ -0,0 +1,21 @@
+package ports
+
+import (
+	"codebase-app/internal/module/recommendation/entity"
+	"context"
+	"time"
+)
+
+type RecommendationRepository interface {
+	IsMemberExist(ctx context.Context, memberId string) (bool, error)
+	GetRules(ctx context.Context) ([]entity.LifeStageRule, error)
+	GetMemberChildren(ctx context.Context, memberId string) ([]entity.Child, error)
+	GetTransitioningChildren(ctx context.Context, from, to time.Time, req *entity.GetTransitionsReq) ([]entity.Child, int, error)
+	GetLevelItems(ctx context.Context, levels []string) ([]entity.LevelItem, error)
+	GetMemberCategories(ctx context.Context, memberIds []string) ([]entity.MemberCategory, error)
+}
+
+type RecommendationService interface {
+	GetMemberRecommendations(ctx context.Context, req *entity.GetMemberRecommendationsReq) (*entity.GetMemberRecommendationsResp, error)
+	GetTransitions(ctx context.Context, req *entity.GetTransitionsReq) (*entity.GetTransitionsResp, error)
+}
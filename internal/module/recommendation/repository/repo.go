@@ -0,0 +1,241 @@
+package repository
+
+import (
+	"codebase-app/internal/adapter"
+	"codebase-app/internal/module/recommendation/entity"
+	"codebase-app/internal/module/recommendation/ports"
+	"codebase-app/pkg/queryspec"
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+var _ ports.RecommendationRepository = &recommendationRepo{}
+
+type recommendationRepo struct {
+	db *sqlx.DB
+}
+
+func NewRecommendationRepository() *recommendationRepo {
+	return &recommendationRepo{
+		db: adapter.Adapters.Postgres,
+	}
+}
+
+// childrenQuery lists the dated children of members. Only the eldest and the
+// youngest are recorded, and they are the same child when both dates match.
+const childrenQuery = `
+	SELECT
+		m.id AS member_id,
+		m.city,
+		k.child,
+		k.birth_date
+	FROM members m
+	CROSS JOIN LATERAL (
+		VALUES ('youngest', m.youngest_kid_dob), ('eldest', m.eldest_kid_dob)
+	) AS k (child, birth_date)
+	WHERE
+		k.birth_date IS NOT NULL
+		AND COALESCE(m.no_of_child, 1) > 0
+		AND (
+			k.child = 'youngest'
+			OR m.youngest_kid_dob IS NULL
+			OR m.eldest_kid_dob <> m.youngest_kid_dob
+		)
+`
+
+func (r *recommendationRepo) IsMemberExist(ctx context.Context, memberId string) (bool, error) {
+	var exist bool
+
+	query := `SELECT EXISTS(SELECT 1 FROM members WHERE id = ?)`
+
+	if err := r.db.GetContext(ctx, &exist, r.db.Rebind(query), memberId); err != nil {
+		log.Error().Err(err).Str("member_id", memberId).Msg("repo::IsMemberExist - failed to check member")
+		return false, err
+	}
+
+	return exist, nil
+}
+
+func (r *recommendationRepo) GetRules(ctx context.Context) ([]entity.LifeStageRule, error) {
+	data := make([]entity.LifeStageRule, 0)
+
+	query := `
+		SELECT
+			level,
+			min_age_months,
+			max_age_months,
+			lead_days
+		FROM life_stage_rules
+		ORDER BY min_age_months
+	`
+
+	if err := r.db.SelectContext(ctx, &data, query); err != nil {
+		log.Error().Err(err).Msg("repo::GetRules - failed to get life stage rules")
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func (r *recommendationRepo) GetMemberChildren(ctx context.Context, memberId string) ([]entity.Child, error) {
+	data := make([]entity.Child, 0)
+
+	query := childrenQuery + ` AND m.id = ? ORDER BY k.birth_date DESC`
+
+	if err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), memberId); err != nil {
+		log.Error().Err(err).Str("member_id", memberId).Msg("repo::GetMemberChildren - failed to get children")
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// GetTransitioningChildren pages through children whose next level starts
+// between from and to, ordered by that date.
+func (r *recommendationRepo) GetTransitioningChildren(ctx context.Context, from, to time.Time, req *entity.GetTransitionsReq) ([]entity.Child, int, error) {
+	type dao struct {
+		TotalData      int       `db:"total_data"`
+		TransitionDate time.Time `db:"transition_date"`
+		entity.Child
+	}
+
+	var (
+		data  = make([]dao, 0)
+		res   = make([]entity.Child, 0)
+		total = 0
+		args  = []any{from.Format(time.DateOnly), to.Format(time.DateOnly)}
+	)
+
+	query := `
+		WITH children AS (` + childrenQuery + `)
+		SELECT
+			COUNT(*) OVER() AS total_data,
+			c.member_id,
+			c.city,
+			c.child,
+			c.birth_date,
+			t.transition_date
+		FROM children c
+		CROSS JOIN LATERAL (
+			SELECT MIN((c.birth_date + MAKE_INTERVAL(months => r.min_age_months))::DATE) AS transition_date
+			FROM life_stage_rules r
+			WHERE
+				r.min_age_months > 0
+				AND (c.birth_date + MAKE_INTERVAL(months => r.min_age_months))::DATE >= ?
+		) AS t
+		WHERE t.transition_date <= ?
+	`
+
+	if req.City != "" {
+		query += ` AND c.city ILIKE ?`
+		args = append(args, queryspec.EscapeLike(req.City))
+	}
+
+	query += ` ORDER BY t.transition_date, c.member_id, c.child LIMIT ? OFFSET ?`
+	args = append(args, req.Paginate, (req.Page-1)*req.Paginate)
+
+	if err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), args...); err != nil {
+		log.Error().Err(err).Any("req", req).Msg("repo::GetTransitioningChildren - failed to get children")
+		return nil, 0, err
+	}
+
+	for _, d := range data {
+		res = append(res, d.Child)
+	}
+
+	if len(data) > 0 {
+		total = data[0].TotalData
+	}
+
+	return res, total, nil
+}
+
+// GetLevelItems ranks the products and grammages bought at each level, keeping the top ones per level.
+func (r *recommendationRepo) GetLevelItems(ctx context.Context, levels []string) ([]entity.LevelItem, error) {
+	data := make([]entity.LevelItem, 0)
+
+	if len(levels) == 0 {
+		return data, nil
+	}
+
+	query, args, err := sqlx.In(`
+		SELECT
+			level,
+			product_id,
+			product_name,
+			category,
+			product_grammage_id,
+			grammage_name,
+			purchases
+		FROM (
+			SELECT
+				p.level,
+				pt.product_id,
+				p.name AS product_name,
+				p.category,
+				pt.product_grammage_id,
+				pg.name AS grammage_name,
+				COUNT(*) AS purchases,
+				ROW_NUMBER() OVER (PARTITION BY p.level ORDER BY COUNT(*) DESC, pt.product_id, pt.product_grammage_id) AS item_rank
+			FROM product_transactions pt
+			JOIN products p ON p.id = pt.product_id
+			LEFT JOIN product_grammages pg ON pg.id = pt.product_grammage_id
+			WHERE
+				p.level IN (?)
+				AND pt.product_grammage_id IS NOT NULL
+				AND pt.voided_at IS NULL
+			GROUP BY p.level, pt.product_id, p.name, p.category, pt.product_grammage_id, pg.name
+		) AS ranked
+		WHERE item_rank <= 20
+		ORDER BY level, item_rank
+	`, levels)
+	if err != nil {
+		log.Error().Err(err).Msg("repo::GetLevelItems - failed to build query")
+		return nil, err
+	}
+
+	if err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), args...); err != nil {
+		log.Error().Err(err).Strs("levels", levels).Msg("repo::GetLevelItems - failed to get level items")
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// GetMemberCategories returns what each member bought per category, most bought first.
+func (r *recommendationRepo) GetMemberCategories(ctx context.Context, memberIds []string) ([]entity.MemberCategory, error) {
+	data := make([]entity.MemberCategory, 0)
+
+	if len(memberIds) == 0 {
+		return data, nil
+	}
+
+	query, args, err := sqlx.In(`
+		SELECT
+			pt.member_id,
+			p.category,
+			COUNT(*) AS purchases
+		FROM product_transactions pt
+		JOIN products p ON p.id = pt.product_id
+		WHERE
+			pt.member_id IN (?)
+			AND pt.voided_at IS NULL
+			AND p.category IS NOT NULL
+		GROUP BY pt.member_id, p.category
+		ORDER BY pt.member_id, purchases DESC, p.category
+	`, memberIds)
+	if err != nil {
+		log.Error().Err(err).Msg("repo::GetMemberCategories - failed to build query")
+		return nil, err
+	}
+
+	if err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), args...); err != nil {
+		log.Error().Err(err).Msg("repo::GetMemberCategories - failed to get member categories")
+		return nil, err
+	}
+
+	return data, nil
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"codebase-app/internal/module/recommendation/entity"
+	"codebase-app/internal/module/recommendation/ports"
+	"codebase-app/pkg/errmsg"
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+var _ ports.RecommendationService = &recommendationService{}
+
+type recommendationService struct {
+	repo ports.RecommendationRepository
+}
+
+func NewRecommendationService(repo ports.RecommendationRepository) *recommendationService {
+	return &recommendationService{
+		repo: repo,
+	}
+}
+
+func (s *recommendationService) GetMemberRecommendations(ctx context.Context, req *entity.GetMemberRecommendationsReq) (*entity.GetMemberRecommendationsResp, error) {
+	exist, err := s.repo.IsMemberExist(ctx, req.MemberId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exist {
+		log.Warn().Any("req", req).Msg("service::GetMemberRecommendations - Member not found")
+		return nil, errmsg.NewCustomErrors(404).SetMessage("Member not found")
+	}
+
+	children, err := s.repo.GetMemberChildren(ctx, req.MemberId)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.recommend(ctx, children, today())
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.GetMemberRecommendationsResp{
+		MemberId: req.MemberId,
+		Items:    items,
+	}, nil
+}
+
+func (s *recommendationService) GetTransitions(ctx context.Context, req *entity.GetTransitionsReq) (*entity.GetTransitionsResp, error) {
+	var (
+		from = today()
+		to   = from.AddDate(0, 0, req.Days)
+		res  = new(entity.GetTransitionsResp)
+	)
+	res.Items = make([]entity.Transition, 0)
+
+	children, total, err := s.repo.GetTransitioningChildren(ctx, from, to, req)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.recommend(ctx, children, from)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, child := range children {
+		res.Items = append(res.Items, entity.Transition{
+			MemberId:       child.MemberId,
+			City:           child.City,
+			Recommendation: items[i],
+		})
+	}
+
+	res.Meta.TotalData = total
+	res.Meta.CountTotalPage(req.Page, req.Paginate, res.Meta.TotalData)
+
+	return res, nil
+}
+
+// recommend resolves the stage of every child and attaches the item to offer
+// for the next level. The result is in the same order as children.
+func (s *recommendationService) recommend(ctx context.Context, children []entity.Child, today time.Time) ([]entity.Recommendation, error) {
+	res := make([]entity.Recommendation, 0, len(children))
+
+	if len(children) == 0 {
+		return res, nil
+	}
+
+	rules, err := s.repo.GetRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		nextLevels = make([]*entity.LifeStageRule, 0, len(children))
+		levels     = make([]string, 0, len(rules))
+		seenLevels = make(map[string]bool)
+		memberIds  = make([]string, 0, len(children))
+		seenIds    = make(map[string]bool)
+	)
+
+	for _, child := range children {
+		rec, next := resolveStage(rules, child, today)
+		res = append(res, rec)
+		nextLevels = append(nextLevels, next)
+
+		if next != nil && !seenLevels[next.Level] {
+			seenLevels[next.Level] = true
+			levels = append(levels, next.Level)
+		}
+
+		if !seenIds[child.MemberId] {
+			seenIds[child.MemberId] = true
+			memberIds = append(memberIds, child.MemberId)
+		}
+	}
+
+	levelItems, err := s.repo.GetLevelItems(ctx, levels)
+	if err != nil {
+		return nil, err
+	}
+
+	memberCategories, err := s.repo.GetMemberCategories(ctx, memberIds)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		itemsByLevel       = make(map[string][]entity.LevelItem)
+		categoriesByMember = make(map[string][]string)
+	)
+
+	for _, item := range levelItems {
+		itemsByLevel[item.Level] = append(itemsByLevel[item.Level], item)
+	}
+
+	for _, mc := range memberCategories {
+		categoriesByMember[mc.MemberId] = append(categoriesByMember[mc.MemberId], mc.Category)
+	}
+
+	for i, child := range children {
+		if nextLevels[i] == nil {
+			continue
+		}
+		res[i].Product = pickItem(itemsByLevel[nextLevels[i].Level], categoriesByMember[child.MemberId])
+	}
+
+	return res, nil
+}
+
+func today() time.Time {
+	return time.Now().UTC().Truncate(day)
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"codebase-app/internal/module/recommendation/entity"
+	"time"
+)
+
+const day = 24 * time.Hour
+
+// addMonths moves t by whole months and clamps to the end of shorter months,
+// the way postgres adds an interval, so Jan 31 plus one month is Feb 28.
+func addMonths(t time.Time, months int) time.Time {
+	first := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, months, 0)
+	lastDay := first.AddDate(0, 1, -1).Day()
+
+	d := t.Day()
+	if d > lastDay {
+		d = lastDay
+	}
+
+	return time.Date(first.Year(), first.Month(), d, 0, 0, 0, 0, time.UTC)
+}
+
+// ageInMonths counts the whole months a child born on dob has lived at the given day.
+func ageInMonths(dob, at time.Time) int {
+	months := (at.Year()-dob.Year())*12 + int(at.Month()) - int(dob.Month())
+	if months > 0 && addMonths(dob, months).After(at) {
+		months--
+	}
+	if months < 0 {
+		return 0
+	}
+	return months
+}
+
+// resolveStage places a child in the rules, ordered by min age. The next
+// level is the first one the child has not aged into yet.
+func resolveStage(rules []entity.LifeStageRule, child entity.Child, today time.Time) (entity.Recommendation, *entity.LifeStageRule) {
+	var (
+		dob  = time.Date(child.BirthDate.Year(), child.BirthDate.Month(), child.BirthDate.Day(), 0, 0, 0, 0, time.UTC)
+		next *entity.LifeStageRule
+		rec  = entity.Recommendation{
+			Child:     child.Child,
+			BirthDate: dob.Format(time.DateOnly),
+			AgeMonths: ageInMonths(dob, today),
+		}
+	)
+
+	for i := range rules {
+		rule := rules[i]
+		start, end := addMonths(dob, rule.MinAgeMonths), addMonths(dob, rule.MaxAgeMonths)
+
+		if !start.After(today) && today.Before(end) && rec.CurrentLevel == nil {
+			rec.CurrentLevel = &rule.Level
+		}
+
+		if rule.MinAgeMonths > 0 && !start.Before(today) && next == nil {
+			next = &rule
+		}
+	}
+
+	if next == nil {
+		return rec, nil
+	}
+
+	var (
+		transitionAt = addMonths(dob, next.MinAgeMonths)
+		transition   = transitionAt.Format(time.DateOnly)
+		daysUntil    = int(transitionAt.Sub(today) / day)
+	)
+
+	rec.NextLevel = &next.Level
+	rec.TransitionDate = &transition
+	rec.DaysUntilTransition = &daysUntil
+	rec.Due = daysUntil <= next.LeadDays
+
+	return rec, next
+}
+
+// pickItem recommends the most bought item of a level, preferring the
+// categories the member buys most.
+func pickItem(items []entity.LevelItem, categories []string) *entity.RecommendedItem {
+	if len(items) == 0 {
+		return nil
+	}
+
+	for _, category := range categories {
+		for _, item := range items {
+			if item.Category != nil && *item.Category == category {
+				return &item.RecommendedItem
+			}
+		}
+	}
+
+	return &items[0].RecommendedItem
+}
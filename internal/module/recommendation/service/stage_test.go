@@ -0,0 +1,65 @@
+package service
+
+import (
+	"codebase-app/internal/module/recommendation/entity"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func at(date string) time.Time {
+	t, _ := time.Parse(time.DateOnly, date)
+	return t
+}
+
+var rules = []entity.LifeStageRule{
+	{Level: "1", MinAgeMonths: 0, MaxAgeMonths: 6, LeadDays: 30},
+	{Level: "2", MinAgeMonths: 6, MaxAgeMonths: 12, LeadDays: 30},
+	{Level: "3", MinAgeMonths: 12, MaxAgeMonths: 36, LeadDays: 30},
+}
+
+func TestAddMonths(t *testing.T) {
+	assert.Equal(t, at("2024-02-29"), addMonths(at("2024-01-31"), 1))
+	assert.Equal(t, at("2025-02-28"), addMonths(at("2024-02-29"), 12))
+	assert.Equal(t, at("2024-07-15"), addMonths(at("2024-01-15"), 6))
+}
+
+func TestAgeInMonths(t *testing.T) {
+	assert.Equal(t, 0, ageInMonths(at("2024-01-15"), at("2024-02-14")))
+	assert.Equal(t, 1, ageInMonths(at("2024-01-15"), at("2024-02-15")))
+	assert.Equal(t, 1, ageInMonths(at("2024-01-31"), at("2024-02-29")))
+	assert.Equal(t, 0, ageInMonths(at("2024-03-01"), at("2024-02-01")))
+}
+
+func TestResolveStage(t *testing.T) {
+	child := entity.Child{MemberId: "A", Child: entity.ChildYoungest, BirthDate: at("2024-01-15")}
+
+	rec, next := resolveStage(rules, child, at("2024-06-20"))
+	assert.Equal(t, 5, rec.AgeMonths)
+	assert.Equal(t, "1", *rec.CurrentLevel)
+	if assert.NotNil(t, next) {
+		assert.Equal(t, "2", next.Level)
+		assert.Equal(t, "2024-07-15", *rec.TransitionDate)
+		assert.Equal(t, 25, *rec.DaysUntilTransition)
+		assert.True(t, rec.Due)
+	}
+
+	// past the last level there is nothing to recommend
+	rec, next = resolveStage(rules, child, at("2027-06-20"))
+	assert.Nil(t, rec.CurrentLevel)
+	assert.Nil(t, next)
+	assert.Nil(t, rec.TransitionDate)
+}
+
+func TestPickItem(t *testing.T) {
+	milk, cereal := "milk", "cereal"
+	items := []entity.LevelItem{
+		{Level: "2", Purchases: 10, RecommendedItem: entity.RecommendedItem{ProductId: 1, Category: &milk}},
+		{Level: "2", Purchases: 4, RecommendedItem: entity.RecommendedItem{ProductId: 2, Category: &cereal}},
+	}
+
+	assert.Equal(t, int64(2), pickItem(items, []string{"cereal", "milk"}).ProductId)
+	assert.Equal(t, int64(1), pickItem(items, nil).ProductId)
+	assert.Nil(t, pickItem(nil, nil))
+}
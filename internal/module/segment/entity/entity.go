@@ -0,0 +1,103 @@
+package entity
+
+import (
+	"codebase-app/pkg/types"
+	"time"
+)
+
+const (
+	SegmentChampions          = "champions"
+	SegmentLoyalCustomers     = "loyal_customers"
+	SegmentPotentialLoyalists = "potential_loyalists"
+	SegmentNewCustomers       = "new_customers"
+	SegmentPromising          = "promising"
+	SegmentNeedAttention      = "need_attention"
+	SegmentAboutToSleep       = "about_to_sleep"
+	SegmentAtRisk             = "at_risk"
+	SegmentCannotLoseThem     = "cannot_lose_them"
+	SegmentHibernating        = "hibernating"
+	SegmentLost               = "lost"
+)
+
+// Segments lists every segment, from the most to the least valuable.
+var Segments = []string{
+	SegmentChampions,
+	SegmentLoyalCustomers,
+	SegmentPotentialLoyalists,
+	SegmentNewCustomers,
+	SegmentPromising,
+	SegmentNeedAttention,
+	SegmentAboutToSleep,
+	SegmentAtRisk,
+	SegmentCannotLoseThem,
+	SegmentHibernating,
+	SegmentLost,
+}
+
+type RefreshSegmentsResult struct {
+	Members    int            `json:"members"`
+	Segments   map[string]int `json:"segments"`
+	Removed    int64          `json:"removed"`
+	ComputedAt time.Time      `json:"computed_at"`
+}
+
+// MemberScore is the raw RFM of a member with its quintile scores, 5 being the best.
+type MemberScore struct {
+	MemberId       string    `db:"member_id"`
+	LastPurchaseAt time.Time `db:"last_purchase_at"`
+	RecencyDays    int       `db:"recency_days"`
+	Frequency      int       `db:"frequency"`
+	Monetary       float64   `db:"monetary"`
+	RScore         int       `db:"r_score"`
+	FScore         int       `db:"f_score"`
+	MScore         int       `db:"m_score"`
+}
+
+type MemberSegment struct {
+	MemberId       string    `db:"member_id" json:"member_id"`
+	City           *string   `db:"city" json:"city"`
+	LastPurchaseAt time.Time `db:"last_purchase_at" json:"last_purchase_at"`
+	RecencyDays    int       `db:"recency_days" json:"recency_days"`
+	Frequency      int       `db:"frequency" json:"frequency"`
+	Monetary       float64   `db:"monetary" json:"monetary"`
+	RScore         int       `db:"r_score" json:"r_score"`
+	FScore         int       `db:"f_score" json:"f_score"`
+	MScore         int       `db:"m_score" json:"m_score"`
+	Segment        string    `db:"segment" json:"segment"`
+	ComputedAt     time.Time `db:"computed_at" json:"computed_at"`
+}
+
+type SegmentSummary struct {
+	Segment        string     `db:"segment" json:"segment"`
+	Members        int        `db:"members" json:"members"`
+	AvgRecencyDays float64    `db:"avg_recency_days" json:"avg_recency_days"`
+	AvgFrequency   float64    `db:"avg_frequency" json:"avg_frequency"`
+	AvgMonetary    float64    `db:"avg_monetary" json:"avg_monetary"`
+	ComputedAt     *time.Time `db:"computed_at" json:"computed_at"`
+}
+
+type GetSegmentsResp struct {
+	Items []SegmentSummary `json:"items"`
+}
+
+type GetSegmentMembersReq struct {
+	Segment  string `params:"segment" validate:"required,oneof=champions loyal_customers potential_loyalists new_customers promising need_attention about_to_sleep at_risk cannot_lose_them hibernating lost"`
+	City     string `query:"city"`
+	Page     int    `query:"page" validate:"required,numeric"`
+	Paginate int    `query:"paginate" validate:"required,numeric"`
+}
+
+func (r *GetSegmentMembersReq) SetDefault() {
+	if r.Page < 1 {
+		r.Page = 1
+	}
+
+	if r.Paginate < 1 {
+		r.Paginate = 10
+	}
+}
+
+type GetSegmentMembersResp struct {
+	Items []MemberSegment `json:"items"`
+	Meta  types.Meta      `json:"meta"`
+}
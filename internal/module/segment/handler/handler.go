@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"codebase-app/internal/adapter"
+	"codebase-app/internal/module/segment/entity"
+	"codebase-app/internal/module/segment/ports"
+	"codebase-app/internal/module/segment/repository"
+	"codebase-app/internal/module/segment/service"
+	"codebase-app/pkg/errmsg"
+	"codebase-app/pkg/response"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+type segmentHandler struct {
+	service ports.SegmentService
+}
+
+func NewSegmentHandler() *segmentHandler {
+	var (
+		repo    = repository.NewSegmentRepository()
+		service = service.NewSegmentService(repo)
+		handler = new(segmentHandler)
+	)
+	handler.service = service
+
+	return handler
+}
+
+func (h *segmentHandler) Register(router fiber.Router) {
+	router.Get("/", h.getSegments)
+	router.Get("/:segment/members", h.getSegmentMembers)
+}
+
+func (h *segmentHandler) getSegments(c *fiber.Ctx) error {
+	resp, err := h.service.GetSegments(c.Context())
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.JSON(response.Success(resp, ""))
+}
+
+func (h *segmentHandler) getSegmentMembers(c *fiber.Ctx) error {
+	var (
+		req = new(entity.GetSegmentMembersReq)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+	)
+
+	if err := c.ParamsParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::getSegmentMembers - Failed to parse params")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	if err := c.QueryParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::getSegmentMembers - Failed to parse query")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.SetDefault()
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("req", req).Msg("handler::getSegmentMembers - Invalid request query")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	resp, err := h.service.GetSegmentMembers(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.JSON(response.Success(resp, ""))
+}
@@ -0,0 +1,21 @@
+package ports
+
+import (
+	"codebase-app/internal/module/segment/entity"
+	"context"
+	"time"
+)
+
+type SegmentRepository interface {
+	StreamMemberScores(ctx context.Context, at time.Time, fn func(entity.MemberScore) error) error
+	UpsertMemberSegments(ctx context.Context, segments []entity.MemberSegment) error
+	DeleteMemberSegmentsBefore(ctx context.Context, computedAt time.Time) (int64, error)
+	GetSegmentSummaries(ctx context.Context) ([]entity.SegmentSummary, error)
+	GetSegmentMembers(ctx context.Context, req *entity.GetSegmentMembersReq) (*entity.GetSegmentMembersResp, error)
+}
+
+type SegmentService interface {
+	RefreshSegments(ctx context.Context) (*entity.RefreshSegmentsResult, error)
+	GetSegments(ctx context.Context) (*entity.GetSegmentsResp, error)
+	GetSegmentMembers(ctx context.Context, req *entity.GetSegmentMembersReq) (*entity.GetSegmentMembersResp, error)
+}
@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"codebase-app/internal/adapter"
+	"codebase-app/internal/module/segment/entity"
+	"codebase-app/internal/module/segment/ports"
+	"codebase-app/pkg/queryspec"
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+var _ ports.SegmentRepository = &segmentRepo{}
+
+type segmentRepo struct {
+	db *sqlx.DB
+}
+
+func NewSegmentRepository() *segmentRepo {
+	return &segmentRepo{
+		db: adapter.Adapters.Postgres,
+	}
+}
+
+// StreamMemberScores computes the RFM of every member who bought something up
+// to the given time. Each measure is split into quintiles over all members,
+// the most recent, most frequent and highest spending fifth scoring 5.
+func (r *segmentRepo) StreamMemberScores(ctx context.Context, at time.Time, fn func(entity.MemberScore) error) error {
+	query := `
+		WITH stats AS (
+			SELECT
+				pt.member_id,
+				MAX(pt.created_at) AS last_purchase_at,
+				COUNT(*) AS frequency,
+				COALESCE(SUM(COALESCE(pt.qty, 0) * COALESCE(pt.price_per_unit, 0)), 0)::DOUBLE PRECISION AS monetary
+			FROM product_transactions pt
+			JOIN members m ON m.id = pt.member_id
+			WHERE
+				pt.created_at IS NOT NULL
+				AND pt.voided_at IS NULL
+				AND pt.created_at <= ?
+			GROUP BY pt.member_id
+		)
+		SELECT
+			member_id,
+			last_purchase_at,
+			GREATEST(EXTRACT(DAY FROM ?::TIMESTAMPTZ - last_purchase_at), 0)::INT AS recency_days,
+			frequency,
+			monetary,
+			NTILE(5) OVER (ORDER BY last_purchase_at) AS r_score,
+			NTILE(5) OVER (ORDER BY frequency) AS f_score,
+			NTILE(5) OVER (ORDER BY monetary) AS m_score
+		FROM stats
+	`
+
+	rows, err := r.db.QueryxContext(ctx, r.db.Rebind(query), at, at)
+	if err != nil {
+		log.Error().Err(err).Msg("repo::StreamMemberScores - failed to query member scores")
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var score entity.MemberScore
+		if err := rows.StructScan(&score); err != nil {
+			log.Error().Err(err).Msg("repo::StreamMemberScores - failed to scan member score")
+			return err
+		}
+
+		if err := fn(score); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Error().Err(err).Msg("repo::StreamMemberScores - failed to iterate member scores")
+		return err
+	}
+
+	return nil
+}
+
+func (r *segmentRepo) UpsertMemberSegments(ctx context.Context, segments []entity.MemberSegment) error {
+	if len(segments) == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO member_segments (
+			member_id,
+			last_purchase_at,
+			recency_days,
+			frequency,
+			monetary,
+			r_score,
+			f_score,
+			m_score,
+			segment,
+			computed_at
+		) VALUES (
+			:member_id,
+			:last_purchase_at,
+			:recency_days,
+			:frequency,
+			:monetary,
+			:r_score,
+			:f_score,
+			:m_score,
+			:segment,
+			:computed_at
+		)
+		ON CONFLICT (member_id) DO UPDATE SET
+			last_purchase_at = EXCLUDED.last_purchase_at,
+			recency_days = EXCLUDED.recency_days,
+			frequency = EXCLUDED.frequency,
+			monetary = EXCLUDED.monetary,
+			r_score = EXCLUDED.r_score,
+			f_score = EXCLUDED.f_score,
+			m_score = EXCLUDED.m_score,
+			segment = EXCLUDED.segment,
+			computed_at = EXCLUDED.computed_at
+	`
+
+	if _, err := r.db.NamedExecContext(ctx, query, segments); err != nil {
+		log.Error().Err(err).Int("rows", len(segments)).Msg("repo::UpsertMemberSegments - failed to upsert member segments")
+		return err
+	}
+
+	return nil
+}
+
+// DeleteMemberSegmentsBefore removes members the latest refresh did not score.
+func (r *segmentRepo) DeleteMemberSegmentsBefore(ctx context.Context, computedAt time.Time) (int64, error) {
+	query := `DELETE FROM member_segments WHERE computed_at < ?`
+
+	result, err := r.db.ExecContext(ctx, r.db.Rebind(query), computedAt)
+	if err != nil {
+		log.Error().Err(err).Time("computed_at", computedAt).Msg("repo::DeleteMemberSegmentsBefore - failed to delete member segments")
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func (r *segmentRepo) GetSegmentSummaries(ctx context.Context) ([]entity.SegmentSummary, error) {
+	data := make([]entity.SegmentSummary, 0)
+
+	query := `
+		SELECT
+			segment,
+			COUNT(*) AS members,
+			AVG(recency_days)::DOUBLE PRECISION AS avg_recency_days,
+			AVG(frequency)::DOUBLE PRECISION AS avg_frequency,
+			AVG(monetary)::DOUBLE PRECISION AS avg_monetary,
+			MAX(computed_at) AS computed_at
+		FROM member_segments
+		GROUP BY segment
+	`
+
+	if err := r.db.SelectContext(ctx, &data, query); err != nil {
+		log.Error().Err(err).Msg("repo::GetSegmentSummaries - failed to get segment summaries")
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func (r *segmentRepo) GetSegmentMembers(ctx context.Context, req *entity.GetSegmentMembersReq) (*entity.GetSegmentMembersResp, error) {
+	type dao struct {
+		TotalData int `db:"total_data"`
+		entity.MemberSegment
+	}
+
+	var (
+		data = make([]dao, 0)
+		res  = new(entity.GetSegmentMembersResp)
+		args = []any{req.Segment}
+	)
+	res.Items = make([]entity.MemberSegment, 0)
+
+	query := `
+		SELECT
+			COUNT(*) OVER() AS total_data,
+			ms.member_id,
+			m.city,
+			ms.last_purchase_at,
+			ms.recency_days,
+			ms.frequency,
+			ms.monetary,
+			ms.r_score,
+			ms.f_score,
+			ms.m_score,
+			ms.segment,
+			ms.computed_at
+		FROM member_segments ms
+		JOIN members m ON m.id = ms.member_id
+		WHERE ms.segment = ?
+	`
+
+	if req.City != "" {
+		query += ` AND m.city ILIKE ?`
+		args = append(args, queryspec.EscapeLike(req.City))
+	}
+
+	query += ` ORDER BY ms.monetary DESC, ms.member_id LIMIT ? OFFSET ?`
+	args = append(args, req.Paginate, (req.Page-1)*req.Paginate)
+
+	if err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), args...); err != nil {
+		log.Error().Err(err).Any("req", req).Msg("repo::GetSegmentMembers - failed to get segment members")
+		return nil, err
+	}
+
+	for _, d := range data {
+		res.Items = append(res.Items, d.MemberSegment)
+	}
+
+	if len(res.Items) > 0 {
+		res.Meta.TotalData = data[0].TotalData
+	}
+
+	res.Meta.CountTotalPage(req.Page, req.Paginate, res.Meta.TotalData)
+
+	return res, nil
+}
@@ -0,0 +1,34 @@
+package service
+
+import "codebase-app/internal/module/segment/entity"
+
+// segmentOf names a member from the recency score and the rounded average of
+// the frequency and monetary scores, following the usual RFM grid.
+func segmentOf(r, f, m int) string {
+	fm := (f + m + 1) / 2
+
+	switch {
+	case r == 5 && fm >= 4, r == 4 && fm == 5:
+		return entity.SegmentChampions
+	case r >= 3 && fm >= 4:
+		return entity.SegmentLoyalCustomers
+	case r >= 4 && fm >= 2:
+		return entity.SegmentPotentialLoyalists
+	case r == 5:
+		return entity.SegmentNewCustomers
+	case r == 4:
+		return entity.SegmentPromising
+	case r == 3 && fm == 3:
+		return entity.SegmentNeedAttention
+	case r == 3:
+		return entity.SegmentAboutToSleep
+	case fm == 5:
+		return entity.SegmentCannotLoseThem
+	case fm >= 3:
+		return entity.SegmentAtRisk
+	case r == 2:
+		return entity.SegmentHibernating
+	default:
+		return entity.SegmentLost
+	}
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"codebase-app/internal/module/segment/entity"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSegmentOf(t *testing.T) {
+	cases := []struct {
+		r, f, m int
+		want    string
+	}{
+		{5, 5, 5, entity.SegmentChampions},
+		{4, 5, 5, entity.SegmentChampions},
+		{3, 4, 5, entity.SegmentLoyalCustomers},
+		{5, 2, 3, entity.SegmentPotentialLoyalists},
+		{5, 1, 1, entity.SegmentNewCustomers},
+		{4, 1, 1, entity.SegmentPromising},
+		{3, 3, 3, entity.SegmentNeedAttention},
+		{3, 1, 2, entity.SegmentAboutToSleep},
+		{1, 5, 5, entity.SegmentCannotLoseThem},
+		{2, 3, 4, entity.SegmentAtRisk},
+		{2, 1, 2, entity.SegmentHibernating},
+		{1, 1, 1, entity.SegmentLost},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.want, segmentOf(c.r, c.f, c.m), "r=%d f=%d m=%d", c.r, c.f, c.m)
+	}
+}
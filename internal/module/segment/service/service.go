@@ -0,0 +1,115 @@
+package service
+
+import (
+	"codebase-app/internal/module/segment/entity"
+	"codebase-app/internal/module/segment/ports"
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+var _ ports.SegmentService = &segmentService{}
+
+// upsertBatchSize keeps a single upsert well under the bind parameter limit of postgres.
+const upsertBatchSize = 1000
+
+type segmentService struct {
+	repo ports.SegmentRepository
+}
+
+func NewSegmentService(repo ports.SegmentRepository) *segmentService {
+	return &segmentService{
+		repo: repo,
+	}
+}
+
+// RefreshSegments scores every member again and replaces the stored segments.
+func (s *segmentService) RefreshSegments(ctx context.Context) (*entity.RefreshSegmentsResult, error) {
+	var (
+		res = &entity.RefreshSegmentsResult{
+			Segments:   make(map[string]int),
+			ComputedAt: time.Now().UTC(),
+		}
+		batch = make([]entity.MemberSegment, 0, upsertBatchSize)
+	)
+
+	flush := func() error {
+		if err := s.repo.UpsertMemberSegments(ctx, batch); err != nil {
+			return err
+		}
+		batch = batch[:0]
+		return nil
+	}
+
+	err := s.repo.StreamMemberScores(ctx, res.ComputedAt, func(score entity.MemberScore) error {
+		segment := segmentOf(score.RScore, score.FScore, score.MScore)
+
+		batch = append(batch, entity.MemberSegment{
+			MemberId:       score.MemberId,
+			LastPurchaseAt: score.LastPurchaseAt,
+			RecencyDays:    score.RecencyDays,
+			Frequency:      score.Frequency,
+			Monetary:       score.Monetary,
+			RScore:         score.RScore,
+			FScore:         score.FScore,
+			MScore:         score.MScore,
+			Segment:        segment,
+			ComputedAt:     res.ComputedAt,
+		})
+		res.Members++
+		res.Segments[segment]++
+
+		if len(batch) == upsertBatchSize {
+			return flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	res.Removed, err = s.repo.DeleteMemberSegmentsBefore(ctx, res.ComputedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().Any("result", res).Msg("service::RefreshSegments - Segments refreshed")
+
+	return res, nil
+}
+
+// GetSegments returns every segment in order of value, including the empty ones.
+func (s *segmentService) GetSegments(ctx context.Context) (*entity.GetSegmentsResp, error) {
+	summaries, err := s.repo.GetSegmentSummaries(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	bySegment := make(map[string]entity.SegmentSummary, len(summaries))
+	for _, summary := range summaries {
+		bySegment[summary.Segment] = summary
+	}
+
+	res := &entity.GetSegmentsResp{
+		Items: make([]entity.SegmentSummary, 0, len(entity.Segments)),
+	}
+
+	for _, segment := range entity.Segments {
+		summary, ok := bySegment[segment]
+		if !ok {
+			summary = entity.SegmentSummary{Segment: segment}
+		}
+		res.Items = append(res.Items, summary)
+	}
+
+	return res, nil
+}
+
+func (s *segmentService) GetSegmentMembers(ctx context.Context, req *entity.GetSegmentMembersReq) (*entity.GetSegmentMembersResp, error) {
+	return s.repo.GetSegmentMembers(ctx, req)
+}
@@ -0,0 +1,65 @@
+package entity
+
+import "time"
+
+// Why a member was evaluated.
+const (
+	ReasonTransaction = "transaction"
+	ReasonNightly     = "nightly"
+)
+
+const (
+	DirectionUpgrade   = "upgrade"
+	DirectionDowngrade = "downgrade"
+)
+
+// SubjectTierChanged is the NATS subject a tier change is published on.
+const SubjectTierChanged = "member.tier_changed"
+
+// WindowMonths is how far back points and spend count toward a tier.
+const WindowMonths = 12
+
+type Tier struct {
+	Code      string  `db:"code" json:"code"`
+	Name      string  `db:"name" json:"name"`
+	Rank      int     `db:"rank" json:"rank"`
+	MinPoints int     `db:"min_points" json:"min_points"`
+	MinSpend  float64 `db:"min_spend" json:"min_spend"`
+}
+
+// MemberStats is what a member accumulated inside the window, with the tier they hold now.
+type MemberStats struct {
+	MemberId    string  `db:"member_id"`
+	Email       *string `db:"email"`
+	CurrentTier *string `db:"current_tier"`
+	Points      int     `db:"points"`
+	Spend       float64 `db:"spend"`
+}
+
+type TierChange struct {
+	MemberId string
+	FromTier *string
+	ToTier   *string
+	Points   int
+	Spend    float64
+	Reason   string
+}
+
+// TierChangedEvent is the payload published on SubjectTierChanged.
+type TierChangedEvent struct {
+	MemberId  string    `json:"member_id"`
+	Email     *string   `json:"email"`
+	FromTier  *string   `json:"from_tier"`
+	ToTier    *string   `json:"to_tier"`
+	ToName    *string   `json:"to_name"`
+	Direction string    `json:"direction"`
+	Points    int       `json:"points_12m"`
+	Spend     float64   `json:"spend_12m"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+type EvaluateTiersResult struct {
+	Members    int `json:"members"`
+	Upgrades   int `json:"upgrades"`
+	Downgrades int `json:"downgrades"`
+}
@@ -0,0 +1,19 @@
+package ports
+
+import (
+	"codebase-app/internal/module/tier/entity"
+	"context"
+	"time"
+)
+
+type TierRepository interface {
+	GetTiers(ctx context.Context) ([]entity.Tier, error)
+	GetMemberStats(ctx context.Context, memberId string, since time.Time) (*entity.MemberStats, error)
+	StreamMemberStats(ctx context.Context, since time.Time, fn func(entity.MemberStats) error) error
+	SaveMemberTier(ctx context.Context, change *entity.TierChange) (changed bool, err error)
+}
+
+type TierService interface {
+	EvaluateMember(ctx context.Context, memberId, reason string) error
+	EvaluateAll(ctx context.Context) (*entity.EvaluateTiersResult, error)
+}
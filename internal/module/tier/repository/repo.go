@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"codebase-app/internal/adapter"
+	"codebase-app/internal/module/tier/entity"
+	"codebase-app/internal/module/tier/ports"
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+var _ ports.TierRepository = &tierRepo{}
+
+type tierRepo struct {
+	db *sqlx.DB
+}
+
+func NewTierRepository() *tierRepo {
+	return &tierRepo{
+		db: adapter.Adapters.Postgres,
+	}
+}
+
+// statsQuery sums the points and spend of members over the transactions made
+// since the first bind parameter, voided ones excluded.
+const statsQuery = `
+	SELECT
+		m.id AS member_id,
+		m.email,
+		mt.tier_code AS current_tier,
+		COALESCE(SUM(COALESCE(pg.point, 0) * COALESCE(pt.qty, 0)), 0)::INT AS points,
+		COALESCE(SUM(COALESCE(pt.qty, 0) * COALESCE(pt.price_per_unit, 0)), 0)::DOUBLE PRECISION AS spend
+	FROM members m
+	LEFT JOIN member_tiers mt ON mt.member_id = m.id
+	LEFT JOIN product_transactions pt
+		ON pt.member_id = m.id
+		AND pt.created_at >= ?
+		AND pt.voided_at IS NULL
+	LEFT JOIN product_grammages pg ON pg.id = pt.product_grammage_id
+`
+
+func (r *tierRepo) GetTiers(ctx context.Context) ([]entity.Tier, error) {
+	data := make([]entity.Tier, 0)
+
+	query := `
+		SELECT
+			code,
+			name,
+			rank,
+			min_points,
+			min_spend
+		FROM tiers
+		ORDER BY rank
+	`
+
+	if err := r.db.SelectContext(ctx, &data, query); err != nil {
+		log.Error().Err(err).Msg("repo::GetTiers - failed to get tiers")
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func (r *tierRepo) GetMemberStats(ctx context.Context, memberId string, since time.Time) (*entity.MemberStats, error) {
+	stats := new(entity.MemberStats)
+
+	query := statsQuery + `
+		WHERE m.id = ?
+		GROUP BY m.id, m.email, mt.tier_code
+	`
+
+	if err := r.db.GetContext(ctx, stats, r.db.Rebind(query), since, memberId); err != nil {
+		log.Error().Err(err).Str("member_id", memberId).Msg("repo::GetMemberStats - failed to get member stats")
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+func (r *tierRepo) StreamMemberStats(ctx context.Context, since time.Time, fn func(entity.MemberStats) error) error {
+	query := statsQuery + `
+		GROUP BY m.id, m.email, mt.tier_code
+		ORDER BY m.id
+	`
+
+	rows, err := r.db.QueryxContext(ctx, r.db.Rebind(query), since)
+	if err != nil {
+		log.Error().Err(err).Msg("repo::StreamMemberStats - failed to query member stats")
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var stats entity.MemberStats
+		if err := rows.StructScan(&stats); err != nil {
+			log.Error().Err(err).Msg("repo::StreamMemberStats - failed to scan member stats")
+			return err
+		}
+
+		if err := fn(stats); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Error().Err(err).Msg("repo::StreamMemberStats - failed to iterate member stats")
+		return err
+	}
+
+	return nil
+}
+
+// SaveMemberTier stores the evaluated tier and stats of a member. The member
+// row is locked first, so concurrent evaluations record a change only once;
+// change.FromTier is set to the tier held under that lock.
+func (r *tierRepo) SaveMemberTier(ctx context.Context, change *entity.TierChange) (bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Msg("repo::SaveMemberTier - failed to begin transaction")
+		return false, err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO member_tiers (member_id) VALUES (?) ON CONFLICT (member_id) DO NOTHING`
+	if _, err := tx.ExecContext(ctx, tx.Rebind(query), change.MemberId); err != nil {
+		log.Error().Err(err).Str("member_id", change.MemberId).Msg("repo::SaveMemberTier - failed to create member tier")
+		return false, err
+	}
+
+	query = `SELECT tier_code FROM member_tiers WHERE member_id = ? FOR UPDATE`
+	if err := tx.GetContext(ctx, &change.FromTier, tx.Rebind(query), change.MemberId); err != nil {
+		log.Error().Err(err).Str("member_id", change.MemberId).Msg("repo::SaveMemberTier - failed to lock member tier")
+		return false, err
+	}
+
+	changed := !sameTier(change.FromTier, change.ToTier)
+
+	query = `
+		UPDATE member_tiers SET
+			tier_code = ?,
+			points_12m = ?,
+			spend_12m = ?,
+			evaluated_at = NOW(),
+			changed_at = CASE WHEN ? THEN NOW() ELSE changed_at END
+		WHERE member_id = ?
+	`
+	if _, err := tx.ExecContext(ctx, tx.Rebind(query), change.ToTier, change.Points, change.Spend, changed, change.MemberId); err != nil {
+		log.Error().Err(err).Str("member_id", change.MemberId).Msg("repo::SaveMemberTier - failed to update member tier")
+		return false, err
+	}
+
+	if changed {
+		query = `
+			INSERT INTO member_tier_histories (member_id, from_tier, to_tier, points_12m, spend_12m, reason)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`
+		_, err := tx.ExecContext(ctx, tx.Rebind(query), change.MemberId, change.FromTier, change.ToTier, change.Points, change.Spend, change.Reason)
+		if err != nil {
+			log.Error().Err(err).Str("member_id", change.MemberId).Msg("repo::SaveMemberTier - failed to create tier history")
+			return false, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Msg("repo::SaveMemberTier - failed to commit transaction")
+		return false, err
+	}
+
+	return changed, nil
+}
+
+func sameTier(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
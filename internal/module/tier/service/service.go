@@ -0,0 +1,142 @@
+package service
+
+import (
+	"codebase-app/internal/adapter"
+	"codebase-app/internal/module/tier/entity"
+	"codebase-app/internal/module/tier/ports"
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+var _ ports.TierService = &tierService{}
+
+type tierService struct {
+	repo ports.TierRepository
+}
+
+func NewTierService(repo ports.TierRepository) *tierService {
+	return &tierService{
+		repo: repo,
+	}
+}
+
+// EvaluateMember re-evaluates the tier of one member, e.g. right after a purchase.
+func (s *tierService) EvaluateMember(ctx context.Context, memberId, reason string) error {
+	tiers, err := s.repo.GetTiers(ctx)
+	if err != nil {
+		return err
+	}
+
+	stats, err := s.repo.GetMemberStats(ctx, memberId, windowStart())
+	if err != nil {
+		return err
+	}
+
+	_, err = s.evaluate(ctx, tiers, *stats, reason)
+	return err
+}
+
+// EvaluateAll re-evaluates every member, so tiers also drop once old
+// purchases leave the rolling window.
+func (s *tierService) EvaluateAll(ctx context.Context) (*entity.EvaluateTiersResult, error) {
+	res := new(entity.EvaluateTiersResult)
+
+	tiers, err := s.repo.GetTiers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.repo.StreamMemberStats(ctx, windowStart(), func(stats entity.MemberStats) error {
+		direction, err := s.evaluate(ctx, tiers, stats, entity.ReasonNightly)
+		if err != nil {
+			return err
+		}
+
+		res.Members++
+		switch direction {
+		case entity.DirectionUpgrade:
+			res.Upgrades++
+		case entity.DirectionDowngrade:
+			res.Downgrades++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().Any("result", res).Msg("service::EvaluateAll - Tiers evaluated")
+
+	return res, nil
+}
+
+// evaluate saves the tier a member qualifies for and announces a change. It
+// returns the direction of the change, or an empty string when there is none.
+func (s *tierService) evaluate(ctx context.Context, tiers []entity.Tier, stats entity.MemberStats, reason string) (string, error) {
+	change := &entity.TierChange{
+		MemberId: stats.MemberId,
+		Points:   stats.Points,
+		Spend:    stats.Spend,
+		Reason:   reason,
+	}
+
+	var target *entity.Tier
+	if target = qualify(tiers, stats.Points, stats.Spend); target != nil {
+		change.ToTier = &target.Code
+	}
+
+	changed, err := s.repo.SaveMemberTier(ctx, change)
+	if err != nil || !changed {
+		return "", err
+	}
+
+	direction := directionOf(tiers, change.FromTier, change.ToTier)
+
+	// the first placement of a member is recorded, not announced
+	if change.FromTier != nil {
+		event := entity.TierChangedEvent{
+			MemberId:  stats.MemberId,
+			Email:     stats.Email,
+			FromTier:  change.FromTier,
+			ToTier:    change.ToTier,
+			Direction: direction,
+			Points:    stats.Points,
+			Spend:     stats.Spend,
+			ChangedAt: time.Now().UTC(),
+		}
+		if target != nil {
+			event.ToName = &target.Name
+		}
+		s.publish(ctx, event)
+	}
+
+	return direction, nil
+}
+
+// publish is best effort: the change is already stored, a lost event only
+// means a member is not notified.
+func (s *tierService) publish(ctx context.Context, event entity.TierChangedEvent) {
+	publisher := adapter.Adapters.EmailPublisher
+	if publisher == nil {
+		log.Warn().Any("event", event).Msg("service::publish - Publisher not connected, tier change not published")
+		return
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		log.Error().Err(err).Any("event", event).Msg("service::publish - Failed to marshal tier change")
+		return
+	}
+
+	if _, err := publisher.Publish(ctx, entity.SubjectTierChanged, data); err != nil {
+		log.Error().Err(err).Any("event", event).Msg("service::publish - Failed to publish tier change")
+	}
+}
+
+func windowStart() time.Time {
+	return time.Now().UTC().AddDate(0, -entity.WindowMonths, 0)
+}
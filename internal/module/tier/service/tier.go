@@ -0,0 +1,36 @@
+package service
+
+import "codebase-app/internal/module/tier/entity"
+
+// qualify returns the highest ranked tier whose points or spend threshold is
+// reached, or nil when none is. tiers must be ordered by rank.
+func qualify(tiers []entity.Tier, points int, spend float64) *entity.Tier {
+	var res *entity.Tier
+
+	for i := range tiers {
+		if points >= tiers[i].MinPoints || spend >= tiers[i].MinSpend {
+			res = &tiers[i]
+		}
+	}
+
+	return res
+}
+
+func directionOf(tiers []entity.Tier, from, to *string) string {
+	rank := func(code *string) int {
+		if code == nil {
+			return 0
+		}
+		for _, tier := range tiers {
+			if tier.Code == *code {
+				return tier.Rank
+			}
+		}
+		return 0
+	}
+
+	if rank(to) < rank(from) {
+		return entity.DirectionDowngrade
+	}
+	return entity.DirectionUpgrade
+}
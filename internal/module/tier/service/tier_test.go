@@ -0,0 +1,31 @@
+package service
+
+import (
+	"codebase-app/internal/module/tier/entity"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var tiers = []entity.Tier{
+	{Code: "silver", Rank: 1, MinPoints: 0, MinSpend: 0},
+	{Code: "gold", Rank: 2, MinPoints: 1000, MinSpend: 5000000},
+	{Code: "platinum", Rank: 3, MinPoints: 5000, MinSpend: 20000000},
+}
+
+func TestQualify(t *testing.T) {
+	assert.Equal(t, "silver", qualify(tiers, 0, 0).Code)
+	assert.Equal(t, "gold", qualify(tiers, 1200, 0).Code)
+	// spend alone is enough
+	assert.Equal(t, "platinum", qualify(tiers, 10, 25000000).Code)
+	assert.Nil(t, qualify(tiers[1:], 10, 10))
+}
+
+func TestDirectionOf(t *testing.T) {
+	silver, gold := "silver", "gold"
+
+	assert.Equal(t, entity.DirectionUpgrade, directionOf(tiers, &silver, &gold))
+	assert.Equal(t, entity.DirectionDowngrade, directionOf(tiers, &gold, &silver))
+	assert.Equal(t, entity.DirectionUpgrade, directionOf(tiers, nil, &silver))
+	assert.Equal(t, entity.DirectionDowngrade, directionOf(tiers, &silver, nil))
+}
@@ -4,8 +4,13 @@ import (
 	// integlocalstorage "codebase-app/internal/integration/localstorage"
 	m "codebase-app/internal/middleware"
 	appLogHandler "codebase-app/internal/module/app_log/handler"
+	importJob "codebase-app/internal/module/import_job/handler"
 	member "codebase-app/internal/module/member/handler"
+	points "codebase-app/internal/module/points/handler"
+	prediction "codebase-app/internal/module/prediction/handler"
 	product "codebase-app/internal/module/product/handler"
+	recommendation "codebase-app/internal/module/recommendation/handler"
+	segment "codebase-app/internal/module/segment/handler"
 
 	"codebase-app/pkg/response"
 	"os"
@@ -23,6 +28,11 @@ func SetupRoutes(app *fiber.App) {
 	appLogHandler.NewAppLogHandler().Register(app.Group("/logs"))
 	member.NewMemberHandler().Register(app.Group("/members"))
 	product.NewProductHandler().Register(app.Group("/products"))
+	segment.NewSegmentHandler().Register(app.Group("/segments"))
+	prediction.NewPredictionHandler().Register(app)
+	recommendation.NewRecommendationHandler().Register(app)
+	points.NewPointsHandler().Register(app)
+	importJob.NewImportJobHandler().Register(app.Group("/imports"))
 
 	// fallback route
 	app.Use(func(c *fiber.Ctx) error {
@@ -0,0 +1,90 @@
+package exporter
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Cursor is a query open on a server-side cursor of a read-only transaction.
+// Close must be called unless Each ran to the end.
+type Cursor[T any] struct {
+	ctx   context.Context
+	tx    *sqlx.Tx
+	fetch string
+	first []T
+}
+
+// Open declares a cursor for query, bound with args already rebound for the
+// driver, and fetches the first batch, so an invalid query fails here and
+// not once the file has started.
+func Open[T any](ctx context.Context, db *sqlx.DB, query string, args []any) (*Cursor[T], error) {
+	tx, err := db.BeginTxx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DECLARE export_cursor NO SCROLL CURSOR FOR `+query, args...); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	c := &Cursor[T]{
+		ctx:   ctx,
+		tx:    tx,
+		fetch: fmt.Sprintf(`FETCH FORWARD %d FROM export_cursor`, FetchSize),
+		first: make([]T, 0, FetchSize),
+	}
+
+	if err := tx.SelectContext(ctx, &c.first, c.fetch); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Each hands each batch of at most FetchSize rows to each, in order, and
+// closes the cursor.
+func (c *Cursor[T]) Each(each func(rows []T) error) error {
+	defer c.Close()
+
+	rows := c.first
+	for len(rows) > 0 {
+		if err := each(rows); err != nil {
+			return err
+		}
+
+		if len(rows) < FetchSize {
+			break
+		}
+
+		rows = rows[:0]
+		if err := c.tx.SelectContext(c.ctx, &rows, c.fetch); err != nil {
+			return err
+		}
+	}
+
+	return c.tx.Commit()
+}
+
+// Close releases the cursor, it is a no-op once Each returned.
+func (c *Cursor[T]) Close() error {
+	err := c.tx.Rollback()
+	if err == sql.ErrTxDone {
+		return nil
+	}
+	return err
+}
+
+// Query runs query through a Cursor and hands each batch to each.
+func Query[T any](ctx context.Context, db *sqlx.DB, query string, args []any, each func(rows []T) error) error {
+	cursor, err := Open[T](ctx, db, query, args)
+	if err != nil {
+		return err
+	}
+
+	return cursor.Each(each)
+}
@@ -0,0 +1,234 @@
+// Package exporter streams rows out of the database as CSV or XLSX files
+// with the columns importer reads, so an export can be imported back. Rows
+// are fetched through a server-side cursor and written chunk by chunk,
+// memory stays flat whatever the size of the table.
+package exporter
+
+import (
+	"codebase-app/pkg/importer"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// FetchSize is how many rows are fetched from the cursor at once.
+const FetchSize = 1000
+
+var ErrUnknownFormat = errors.New("unknown export format")
+
+// IncompleteMarker is the last line of a CSV export that failed after it
+// started, a file ending in it is missing rows. An XLSX export that fails
+// writes nothing, the workbook only goes out complete.
+const IncompleteMarker = "# export incomplete"
+
+// ContentType is the media type of a format.
+func ContentType(format string) string {
+	if format == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv"
+}
+
+// Writer writes rows of T to a file of one format. Close must be called to
+// complete the file.
+type Writer[T any] struct {
+	sink    sink
+	encoder *importer.Encoder[T]
+	record  []string
+}
+
+type sink interface {
+	write(record []string) error
+	flush() error
+	close() error
+	abort() error
+}
+
+// NewWriter starts a file of format on w with the header of T.
+func NewWriter[T any](w io.Writer, format string) (*Writer[T], error) {
+	var (
+		encoder = importer.NewEncoder[T]()
+		s       sink
+		err     error
+	)
+
+	switch format {
+	case "", FormatCSV:
+		s = newCSVSink(w)
+	case FormatXLSX:
+		s, err = newXLSXSink(w)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.write(encoder.Header()); err != nil {
+		return nil, err
+	}
+
+	return &Writer[T]{sink: s, encoder: encoder}, nil
+}
+
+// Write appends rows and flushes them to the underlying writer where the
+// format allows, it fits the callback of Query.
+func (w *Writer[T]) Write(rows []T) error {
+	for i := range rows {
+		w.record = w.encoder.Encode(&rows[i], w.record)
+		if err := w.sink.write(w.record); err != nil {
+			return err
+		}
+	}
+	return w.sink.flush()
+}
+
+func (w *Writer[T]) Close() error {
+	return w.sink.close()
+}
+
+// Abort ends a file that cannot be completed so it is not taken for a whole
+// one, see IncompleteMarker.
+func (w *Writer[T]) Abort() error {
+	return w.sink.abort()
+}
+
+// Stream writes a file to w.
+type Stream func(w io.Writer) error
+
+// NewStream writes the rows of cursor to a file of format. A failure once
+// the file started aborts it, the cursor is closed either way.
+func NewStream[T any](cursor *Cursor[T], format string) Stream {
+	return func(w io.Writer) error {
+		out, err := NewWriter[T](w, format)
+		if err != nil {
+			cursor.Close()
+			return err
+		}
+
+		if err := cursor.Each(out.Write); err != nil {
+			out.Abort()
+			return err
+		}
+
+		return out.Close()
+	}
+}
+
+type flusher interface {
+	Flush() error
+}
+
+type csvSink struct {
+	w      io.Writer
+	writer *csv.Writer
+}
+
+func newCSVSink(w io.Writer) *csvSink {
+	return &csvSink{w: w, writer: csv.NewWriter(w)}
+}
+
+func (s *csvSink) write(record []string) error {
+	return s.writer.Write(record)
+}
+
+// flush pushes the chunk through to the client when w is buffered, e.g. the
+// stream writer of a response.
+func (s *csvSink) flush() error {
+	s.writer.Flush()
+	if err := s.writer.Error(); err != nil {
+		return err
+	}
+	if f, ok := s.w.(flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+func (s *csvSink) close() error {
+	return s.flush()
+}
+
+func (s *csvSink) abort() error {
+	s.writer.Flush()
+	if _, err := io.WriteString(s.w, IncompleteMarker+"\n"); err != nil {
+		return err
+	}
+	if f, ok := s.w.(flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// xlsxSink writes through an excelize stream writer, which keeps rows on
+// disk past a threshold. The workbook is a zip archive, it only reaches w
+// once complete.
+type xlsxSink struct {
+	w      io.Writer
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	row    int
+	cells  []any
+}
+
+func newXLSXSink(w io.Writer) (*xlsxSink, error) {
+	file := excelize.NewFile()
+
+	stream, err := file.NewStreamWriter(file.GetSheetName(0))
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &xlsxSink{w: w, file: file, stream: stream}, nil
+}
+
+func (s *xlsxSink) write(record []string) error {
+	s.row++
+
+	cell, err := excelize.CoordinatesToCellName(1, s.row)
+	if err != nil {
+		return err
+	}
+
+	// cells are written as text so ids and dates round-trip unchanged
+	s.cells = s.cells[:0]
+	for _, value := range record {
+		s.cells = append(s.cells, value)
+	}
+
+	return s.stream.SetRow(cell, s.cells)
+}
+
+func (s *xlsxSink) flush() error {
+	return nil
+}
+
+func (s *xlsxSink) abort() error {
+	return s.file.Close()
+}
+
+func (s *xlsxSink) close() error {
+	defer s.file.Close()
+
+	if err := s.stream.Flush(); err != nil {
+		return err
+	}
+
+	if _, err := s.file.WriteTo(s.w); err != nil {
+		return err
+	}
+
+	if f, ok := s.w.(flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package exporter
+
+import (
+	"bytes"
+	"codebase-app/pkg/importer"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type row struct {
+	Id     string   `csv:"ID"`
+	Qty    int      `csv:"Qty"`
+	Price  *float64 `csv:"Price,omitempty"`
+	Date   string   `csv:"Date" validate:"datetime=2006-01-02"`
+	Secret string
+}
+
+func roundTrip(t *testing.T, format string, rows []row) []row {
+	t.Helper()
+
+	buf := new(bytes.Buffer)
+	w, err := NewWriter[row](buf, format)
+	require.NoError(t, err)
+	require.NoError(t, w.Write(rows))
+	require.NoError(t, w.Close())
+
+	var src importer.Source = importer.NewCSVSource(buf)
+	if format == FormatXLSX {
+		xlsx, err := importer.NewXLSXSource(buf, "")
+		require.NoError(t, err)
+		defer xlsx.Close()
+		src = xlsx
+	}
+
+	got := make([]row, 0)
+	res, err := importer.Run(context.Background(), src, importer.Options[row]{
+		Load: func(_ context.Context, chunk []row, _ []int) error {
+			got = append(got, chunk...)
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, res.Errors)
+
+	return got
+}
+
+func TestRoundTrip(t *testing.T) {
+	price := 1.25
+	rows := []row{
+		{Id: "a", Qty: 1, Price: &price, Date: "2024-01-31", Secret: "x"},
+		{Id: "b, c", Qty: 2, Date: "2024-02-01"},
+	}
+
+	want := []row{
+		{Id: "a", Qty: 1, Price: &price, Date: "2024-01-31"},
+		{Id: "b, c", Qty: 2, Date: "2024-02-01"},
+	}
+
+	for _, format := range []string{FormatCSV, FormatXLSX} {
+		t.Run(format, func(t *testing.T) {
+			assert.Equal(t, want, roundTrip(t, format, rows))
+		})
+	}
+}
+
+func TestUnknownFormat(t *testing.T) {
+	_, err := NewWriter[row](new(bytes.Buffer), "pdf")
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+}
+
+func TestAbort(t *testing.T) {
+	buf := new(bytes.Buffer)
+	w, err := NewWriter[row](buf, FormatCSV)
+	require.NoError(t, err)
+	require.NoError(t, w.Write([]row{{Id: "a", Qty: 1, Date: "2024-01-31"}}))
+	require.NoError(t, w.Abort())
+
+	assert.Equal(t, "ID,Qty,Price,Date\na,1,,2024-01-31\n"+IncompleteMarker+"\n", buf.String())
+
+	buf.Reset()
+	w, err = NewWriter[row](buf, FormatXLSX)
+	require.NoError(t, err)
+	require.NoError(t, w.Abort())
+	assert.Zero(t, buf.Len())
+}
@@ -0,0 +1,58 @@
+package importer
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dateLayouts are the dates Decoder reads besides the layout of the field,
+// day first as spreadsheets on an Indonesian locale write them.
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	time.DateTime,
+	"2006-01-02 15:04",
+	time.DateOnly,
+	"2/1/2006 15:04:05",
+	"2/1/2006 15:04",
+	"2/1/2006",
+	"2-1-2006 15:04:05",
+	"2-1-2006 15:04",
+	"2-1-2006",
+}
+
+// excelEpoch is day 0 of Excel serial dates. Counting from the last day of
+// 1899 absorbs Excel's phantom 29 February 1900 for every later date.
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// excelDate reads a serial date, days since excelEpoch with the time of day
+// as the fraction, as a wall clock time in loc.
+func excelDate(value string, loc *time.Location) (time.Time, bool) {
+	if strings.ContainsAny(value, "eE") {
+		return time.Time{}, false
+	}
+
+	serial, err := strconv.ParseFloat(value, 64)
+	if err != nil || serial < 1 || serial >= 2958466 { // up to 9999-12-31
+		return time.Time{}, false
+	}
+
+	days := math.Floor(serial)
+	seconds := math.Round((serial - days) * 24 * 60 * 60)
+
+	t := excelEpoch.AddDate(0, 0, int(days)).Add(time.Duration(seconds) * time.Second)
+
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, loc), true
+}
+
+// formatDate writes t in layout, in UTC when the layout has a zone so the
+// zone is one the database knows.
+func formatDate(t time.Time, layout string) string {
+	if strings.Contains(layout, "MST") || strings.Contains(layout, "Z07") || strings.Contains(layout, "-07") {
+		t = t.UTC()
+	}
+	return t.Format(layout)
+}
@@ -0,0 +1,42 @@
+package importer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecoderDates(t *testing.T) {
+	jakarta := time.FixedZone("WIB", 7*60*60)
+
+	field := decoderField{target: "2006-01-02 15:04:05 MST", location: jakarta}
+
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"2024-01-31 10:00:00 UTC", "2024-01-31 10:00:00 UTC"},
+		{"2024-01-31T10:00:00+07:00", "2024-01-31 03:00:00 UTC"},
+		{"2024-01-31 10:00", "2024-01-31 03:00:00 UTC"},
+		{"31/01/2024 10:00", "2024-01-31 03:00:00 UTC"},
+		{"1/2/2024", "2024-01-31 17:00:00 UTC"},
+		{"45322.5", "2024-01-31 05:00:00 UTC"},
+	}
+
+	for _, tt := range tests {
+		date, err := field.date(tt.value)
+		if assert.NoError(t, err, tt.value) {
+			assert.Equal(t, tt.expected, date, tt.value)
+		}
+	}
+
+	_, err := field.date("31 Januari 2024")
+	assert.Error(t, err)
+
+	// a date without a zone stays on its day
+	field = decoderField{target: "2006-01-02", location: jakarta}
+	date, err := field.date("31/01/2024")
+	assert.NoError(t, err)
+	assert.Equal(t, "2024-01-31", date)
+}
@@ -0,0 +1,282 @@
+package importer
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Decoder fills structs from records using the csv tags of T, the same tags
+// gocsv reads. A column tagged omitempty may be missing from the file, as
+// may the field name column of an untagged field; every other column must be
+// present unless a Profile gives it a default.
+type Decoder[T any] struct {
+	fields []decoderField
+	byName map[string]int // validator field name to fields index
+}
+
+type decoderField struct {
+	index  []int
+	column string // the column of the file, for messages
+	name   string // the name the validator reports the field under
+	pos    int    // position in the record, -1 when the column is missing
+
+	layout   string         // date layout of the file, see Profile.Formats
+	target   string         // date layout of the field
+	location *time.Location // of dates without a zone
+	fallback string         // value of empty cells, see Profile.Defaults
+}
+
+// NewDecoder matches header to the columns of T, through profile when it is
+// not nil. Dates without a zone are read in loc, UTC when nil.
+func NewDecoder[T any](header []string, profile *Profile, loc *time.Location) (*Decoder[T], error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	var (
+		positions = make(map[string]int, len(header))
+		missing   = make([]string, 0)
+		d         = &Decoder[T]{byName: make(map[string]int)}
+	)
+
+	names := profile.rename(header)
+	for i, name := range names {
+		if _, ok := positions[name]; !ok {
+			positions[name] = i
+		}
+	}
+
+	for _, column := range csvColumns(reflect.TypeOf((*T)(nil)).Elem()) {
+		pos, ok := positions[column.Name]
+		if !ok {
+			pos, ok = findFold(names, column.Name)
+		}
+
+		fallback, hasFallback := profile.fallback(column.Name)
+
+		if !ok {
+			if column.Required && !hasFallback {
+				missing = append(missing, column.Name)
+			}
+			pos = -1
+		}
+
+		name := column.Name
+		if ok {
+			name = strings.TrimSpace(header[pos])
+		}
+
+		d.byName[column.field] = len(d.fields)
+		d.fields = append(d.fields, decoderField{
+			index:    column.index,
+			column:   name,
+			name:     column.field,
+			pos:      pos,
+			layout:   profile.format(column.Name),
+			target:   column.Layout,
+			location: loc,
+			fallback: fallback,
+		})
+	}
+
+	if len(missing) > 0 {
+		return nil, &MissingColumnsError{Columns: missing}
+	}
+
+	return d, nil
+}
+
+// Decode sets the fields of dst from one record. Empty cells leave the
+// zero value, i.e. nil for pointers. Every cell that cannot be decoded is
+// reported in the returned FieldErrors.
+func (d *Decoder[T]) Decode(record []string, dst *T) error {
+	var (
+		v    = reflect.ValueOf(dst).Elem()
+		errs FieldErrors
+	)
+
+	for _, f := range d.fields {
+		if f.pos < 0 && f.fallback == "" {
+			continue
+		}
+
+		value := cell(record, f.pos)
+		if err := f.set(v.FieldByIndex(f.index), value); err != nil {
+			errs = append(errs, &FieldError{Column: f.column, Value: value, Message: f.column + " " + err.Error()})
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// fieldErrors places the messages of a ValidationError on the columns of
+// the record they belong to. Columns that already failed to decode are
+// skipped, their zero value would only fail validation again.
+func (d *Decoder[T]) fieldErrors(record []string, verr ValidationError, decoded FieldErrors) FieldErrors {
+	var (
+		errs   = decoded
+		failed = make(map[string]bool, len(decoded))
+	)
+
+	for _, err := range decoded {
+		failed[err.Column] = true
+	}
+
+	add := func(name, column, value string) {
+		if failed[column] {
+			return
+		}
+		for _, message := range verr[name] {
+			errs = append(errs, &FieldError{Column: column, Value: value, Message: message})
+		}
+	}
+
+	// fields in struct order, then whatever the validator reported on fields
+	// that have no column
+	for _, f := range d.fields {
+		add(f.name, f.column, cell(record, f.pos))
+	}
+
+	for _, name := range verr.names() {
+		if _, ok := d.byName[name]; !ok {
+			add(name, name, "")
+		}
+	}
+
+	return errs
+}
+
+// set converts value to the layout of a date field and falls back to the
+// default when it is empty.
+func (f *decoderField) set(field reflect.Value, value string) error {
+	if value == "" {
+		return setValue(field, f.fallback)
+	}
+
+	if f.target != "" {
+		date, err := f.date(value)
+		if err != nil {
+			return err
+		}
+		value = date
+	}
+
+	return setValue(field, value)
+}
+
+// date rewrites value to the layout of the field. Without a profile format
+// a value already in that layout is kept, anything else is read with
+// dateLayouts or as an Excel serial date.
+func (f *decoderField) date(value string) (string, error) {
+	layouts := []string{f.layout}
+
+	if f.layout == "" {
+		if _, err := time.Parse(f.target, value); err == nil {
+			return value, nil
+		}
+
+		if t, ok := excelDate(value, f.location); ok {
+			return formatDate(t, f.target), nil
+		}
+
+		layouts = dateLayouts
+	}
+
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, value, f.location); err == nil {
+			return formatDate(t, f.target), nil
+		}
+	}
+
+	if f.layout != "" {
+		return "", errors.New("harus tanggal dengan format " + f.layout + ".")
+	}
+	return "", errors.New("harus tanggal yang valid.")
+}
+
+func cell(record []string, pos int) string {
+	if pos < 0 || pos >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[pos])
+}
+
+func findFold(header []string, name string) (int, bool) {
+	for i, column := range header {
+		if strings.EqualFold(strings.TrimSpace(column), name) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// validatorName mirrors the tag name func of pkg/validator, which is the
+// name errmsg keys its messages by.
+func validatorName(field reflect.StructField) string {
+	for _, key := range []string{"json", "query", "form", "params", "prop"} {
+		name, _, _ := strings.Cut(field.Tag.Get(key), ",")
+		if name == "-" {
+			return field.Name
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+func setValue(field reflect.Value, value string) error {
+	if value == "" {
+		field.SetZero()
+		return nil
+	}
+
+	if field.Kind() == reflect.Pointer {
+		ptr := reflect.New(field.Type().Elem())
+		if err := setValue(ptr.Elem(), value); err != nil {
+			return err
+		}
+		field.Set(ptr)
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return errors.New("harus bilangan bulat.")
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return errors.New("harus bilangan bulat positif.")
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return errors.New("harus angka.")
+		}
+		field.SetFloat(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return errors.New("harus true atau false.")
+		}
+		field.SetBool(b)
+	default:
+		return errors.New("tipe " + field.Type().String() + " tidak didukung.")
+	}
+
+	return nil
+}
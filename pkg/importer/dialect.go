@@ -0,0 +1,165 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// Encodings detectDialect tells apart.
+const (
+	EncodingUTF8        = "utf-8"
+	EncodingUTF16LE     = "utf-16le"
+	EncodingUTF16BE     = "utf-16be"
+	EncodingWindows1252 = "windows-1252"
+)
+
+// sniffSize is how much of a file the encoding and delimiter are guessed
+// from.
+const sniffSize = 64 * 1024
+
+// delimiters are tried in order, the first wins a tie.
+var delimiters = []rune{',', ';', '\t', '|'}
+
+// Dialect is how a CSV file is written, as far as detectDialect can tell.
+type Dialect struct {
+	Encoding  string
+	Delimiter rune
+}
+
+// detectDialect guesses the encoding of r from its byte order mark or, for
+// files without one, from its bytes, and the delimiter from the first line.
+// It returns r decoded to UTF-8 without the mark. Spreadsheets saved as CSV
+// on an Indonesian locale are typically semicolon separated Windows-1252,
+// or UTF-16 when saved as Unicode text.
+func detectDialect(r io.Reader) (io.Reader, Dialect, error) {
+	var (
+		raw     = bufio.NewReaderSize(r, sniffSize)
+		dialect = Dialect{Encoding: EncodingUTF8, Delimiter: ','}
+		decoder *encoding.Decoder
+	)
+
+	sample, err := raw.Peek(sniffSize)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, dialect, err
+	}
+
+	switch {
+	case bytes.HasPrefix(sample, []byte{0xEF, 0xBB, 0xBF}):
+		decoder = unicode.UTF8BOM.NewDecoder()
+	case bytes.HasPrefix(sample, []byte{0xFF, 0xFE}):
+		dialect.Encoding = EncodingUTF16LE
+		decoder = unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM).NewDecoder()
+	case bytes.HasPrefix(sample, []byte{0xFE, 0xFF}):
+		dialect.Encoding = EncodingUTF16BE
+		decoder = unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM).NewDecoder()
+	default:
+		dialect.Encoding = guessEncoding(sample)
+		switch dialect.Encoding {
+		case EncodingUTF16LE:
+			decoder = unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM).NewDecoder()
+		case EncodingUTF16BE:
+			decoder = unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM).NewDecoder()
+		case EncodingWindows1252:
+			decoder = charmap.Windows1252.NewDecoder()
+		}
+	}
+
+	var decoded io.Reader = raw
+	if decoder != nil {
+		decoded = transform.NewReader(raw, decoder)
+	}
+
+	text := bufio.NewReaderSize(decoded, sniffSize)
+
+	sample, err = text.Peek(sniffSize)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, dialect, err
+	}
+	dialect.Delimiter = guessDelimiter(sample)
+
+	return text, dialect, nil
+}
+
+// guessEncoding tells UTF-16 without a byte order mark by its zero bytes,
+// ASCII being most of a CSV file, and falls back to Windows-1252 for
+// anything that is not valid UTF-8.
+func guessEncoding(sample []byte) string {
+	if len(sample) >= 2 {
+		var even, odd int
+		for i, b := range sample {
+			if b != 0 {
+				continue
+			}
+			if i%2 == 0 {
+				even++
+			} else {
+				odd++
+			}
+		}
+
+		half := len(sample) / 2
+		switch {
+		case odd > half*3/4 && even < odd/10:
+			return EncodingUTF16LE
+		case even > half*3/4 && odd < even/10:
+			return EncodingUTF16BE
+		}
+	}
+
+	if utf8.Valid(trimPartialRune(sample)) {
+		return EncodingUTF8
+	}
+
+	return EncodingWindows1252
+}
+
+// trimPartialRune drops a character cut off at the end of a sample.
+func trimPartialRune(b []byte) []byte {
+	for i := len(b) - 1; i >= 0 && i >= len(b)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(b[i]) {
+			if !utf8.FullRune(b[i:]) {
+				return b[:i]
+			}
+			break
+		}
+	}
+	return b
+}
+
+// guessDelimiter counts the candidates outside quotes on the first line and
+// picks the most frequent.
+func guessDelimiter(sample []byte) rune {
+	var (
+		counts = make(map[rune]int, len(delimiters))
+		quoted bool
+	)
+
+loop:
+	for _, r := range string(sample) {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case quoted:
+		case r == '\n' || r == '\r':
+			break loop
+		default:
+			counts[r]++
+		}
+	}
+
+	best := delimiters[0]
+	for _, d := range delimiters[1:] {
+		if counts[d] > counts[best] {
+			best = d
+		}
+	}
+
+	return best
+}
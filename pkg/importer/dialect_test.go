@@ -0,0 +1,76 @@
+package importer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+func readAll(t *testing.T, data []byte) ([][]string, Dialect) {
+	t.Helper()
+
+	src := NewCSVSource(bytes.NewReader(data))
+
+	header, err := src.Header()
+	require.NoError(t, err)
+
+	records := [][]string{header}
+	for {
+		record, err := src.Next()
+		if err != nil {
+			break
+		}
+		records = append(records, append([]string(nil), record...))
+	}
+
+	return records, src.Dialect()
+}
+
+func TestCSVSourceDialect(t *testing.T) {
+	text := "ID;Kota\na;Bekasi\nb;\"Depok; Jawa Barat\"\n"
+	expected := [][]string{{"ID", "Kota"}, {"a", "Bekasi"}, {"b", "Depok; Jawa Barat"}}
+
+	utf16le, err := unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewEncoder().String(text)
+	require.NoError(t, err)
+
+	utf16be, err := unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM).NewEncoder().String(text)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		data     []byte
+		encoding string
+	}{
+		{"utf-8", []byte(text), EncodingUTF8},
+		{"utf-8 bom", append([]byte{0xEF, 0xBB, 0xBF}, text...), EncodingUTF8},
+		{"utf-16le bom", []byte(utf16le), EncodingUTF16LE},
+		{"utf-16be", []byte(utf16be), EncodingUTF16BE},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, dialect := readAll(t, tt.data)
+			assert.Equal(t, expected, records)
+			assert.Equal(t, Dialect{Encoding: tt.encoding, Delimiter: ';'}, dialect)
+		})
+	}
+}
+
+func TestCSVSourceWindows1252(t *testing.T) {
+	data, err := charmap.Windows1252.NewEncoder().String("ID\tKota\na\tCipayung – Jakarta\n")
+	require.NoError(t, err)
+
+	records, dialect := readAll(t, []byte(data))
+	assert.Equal(t, [][]string{{"ID", "Kota"}, {"a", "Cipayung – Jakarta"}}, records)
+	assert.Equal(t, Dialect{Encoding: EncodingWindows1252, Delimiter: '\t'}, dialect)
+}
+
+func TestGuessDelimiterDefault(t *testing.T) {
+	assert.Equal(t, ',', guessDelimiter([]byte("ID\na\n")))
+	assert.Equal(t, ',', guessDelimiter([]byte(strings.Repeat("\"a;b\",c", 2))))
+}
@@ -0,0 +1,68 @@
+package importer
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// Encoder writes structs as records under the csv tags of T, the inverse of
+// Decoder, so an exported file imports back. Untagged fields are optional
+// inputs such as a password and are left out.
+type Encoder[T any] struct {
+	columns []Column
+}
+
+func NewEncoder[T any]() *Encoder[T] {
+	e := new(Encoder[T])
+	for _, column := range Columns[T]() {
+		if column.tagged {
+			e.columns = append(e.columns, column)
+		}
+	}
+	return e
+}
+
+func (e *Encoder[T]) Header() []string {
+	header := make([]string, len(e.columns))
+	for i, column := range e.columns {
+		header[i] = column.Name
+	}
+	return header
+}
+
+// Encode writes row into record, which is grown as needed and returned.
+func (e *Encoder[T]) Encode(row *T, record []string) []string {
+	var v = reflect.ValueOf(row).Elem()
+
+	record = record[:0]
+	for _, column := range e.columns {
+		record = append(record, formatValue(v.FieldByIndex(column.index)))
+	}
+
+	return record
+}
+
+func formatValue(field reflect.Value) string {
+	if field.Kind() == reflect.Pointer {
+		if field.IsNil() {
+			return ""
+		}
+		field = field.Elem()
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		return field.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(field.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(field.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(field.Float(), 'f', -1, field.Type().Bits())
+	case reflect.Bool:
+		return strconv.FormatBool(field.Bool())
+	default:
+		return fmt.Sprint(field.Interface())
+	}
+}
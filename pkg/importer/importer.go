@@ -0,0 +1,346 @@
+// Package importer streams uploaded files into the database. Records are
+// decoded and validated chunk by chunk and handed to a loader, usually a
+// Session that copies them into a staging table, so memory stays flat
+// whatever the size of the file.
+package importer
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultChunkSize = 5000
+	DefaultMaxErrors = 1000
+)
+
+var ErrEmptyFile = errors.New("file is empty")
+
+type MissingColumnsError struct {
+	Columns []string
+}
+
+func (e *MissingColumnsError) Error() string {
+	return "missing columns: " + strings.Join(e.Columns, ", ")
+}
+
+// FieldError is a cell that could not be decoded or failed validation.
+// Column is empty for errors that do not belong to one cell.
+type FieldError struct {
+	Column  string
+	Value   string
+	Message string
+}
+
+func (e *FieldError) Error() string {
+	if e.Column == "" {
+		return e.Message
+	}
+	return e.Column + ": " + e.Message
+}
+
+type FieldErrors []*FieldError
+
+func (e FieldErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// RowError is a record that could not be decoded or failed validation. Row
+// counts the header as row 1, as spreadsheets do.
+type RowError struct {
+	Row    int
+	Fields FieldErrors
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("row %d: %v", e.Row, e.Fields)
+}
+
+func (e *RowError) messages() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, err := range e.Fields {
+		messages = append(messages, err.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// IsInputError reports whether err is a fault of the file rather than of
+// the database, i.e. one the uploader can fix.
+func IsInputError(err error) bool {
+	var missingErr *MissingColumnsError
+	return errors.As(err, &missingErr) ||
+		errors.Is(err, ErrEmptyFile) ||
+		errors.Is(err, ErrSheetNotFound) ||
+		errors.Is(err, ErrUnknownConflict)
+}
+
+// Settings are the options of a run that do not depend on the row type.
+// They are set by whoever drives the import and passed along by the module
+// that owns the rows.
+type Settings struct {
+	// Progress is called after every loaded chunk.
+	Progress func(res *Result)
+	// Report, when set, receives the file back as CSV with an errors column
+	// holding the messages of each rejected row.
+	Report io.Writer
+	// SampleSize is how many valid rows are kept in the result.
+	SampleSize int
+	// DryRun rolls the import back once it is merged, see Import.
+	DryRun bool
+	// OnConflict is the strategy for keys that already exist, see Import.
+	OnConflict string
+	// Batch tags the rows the import inserts, see Target.BatchColumn.
+	Batch string
+	// Profile maps the columns of the file, nil when it matches the tags.
+	Profile *Profile
+	// Location is the zone of dates written without one, UTC when nil.
+	Location *time.Location
+}
+
+type Options[T any] struct {
+	Settings
+
+	// ChunkSize is how many rows are validated and loaded at once.
+	ChunkSize int
+	// MaxErrors caps the row errors kept in the result, every failed row is
+	// still counted.
+	MaxErrors int
+	// Validate checks a decoded row and may fill in defaults. A
+	// ValidationError is reported per column, see Validate.
+	Validate func(row *T) error
+	// Load receives every chunk of valid rows, in file order, with the file
+	// row of each.
+	Load func(ctx context.Context, chunk []T, rows []int) error
+	// Check, when set, runs after every Load on the file rows of the chunk
+	// and returns the rows that turned out invalid once loaded, e.g. with a
+	// reference to a missing row. They are rejected like the others.
+	Check func(ctx context.Context, rows []int) ([]*RowError, error)
+}
+
+type Result struct {
+	Rows   int         // rows loaded
+	Failed int         // rows rejected
+	Errors []*RowError // the first MaxErrors rejected rows
+	Sample []any       // the first SampleSize loaded rows
+
+	// Set by Import once the rows are merged. Skipped rows were loaded but
+	// left as they are, e.g. a key repeated in the file.
+	Inserted int
+	Updated  int
+	Skipped  int
+}
+
+// Run decodes every record of src and loads the valid ones in chunks. Every
+// row is checked in full: all cells that fail to decode and all validation
+// errors are collected before the row is rejected. Rejected rows are left out
+// and reported in the result; an error is only returned when the file as a
+// whole is unusable or loading fails.
+func Run[T any](ctx context.Context, src Source, opts Options[T]) (*Result, error) {
+	if opts.ChunkSize < 1 {
+		opts.ChunkSize = DefaultChunkSize
+	}
+
+	if opts.MaxErrors < 1 {
+		opts.MaxErrors = DefaultMaxErrors
+	}
+
+	header, err := src.Header()
+	if err != nil {
+		return nil, err
+	}
+
+	decoder, err := NewDecoder[T](header, opts.Profile, opts.Location)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := newReport(opts.Report, header)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		res   = &Result{Errors: make([]*RowError, 0), Sample: make([]any, 0, opts.SampleSize)}
+		chunk = make([]T, 0, opts.ChunkSize)
+		rows  = make([]int, 0, opts.ChunkSize)
+		row   = 1
+	)
+
+	reject := func(record []string, errs FieldErrors) error {
+		rowErr := &RowError{Row: row, Fields: errs}
+
+		res.Failed++
+		if len(res.Errors) < opts.MaxErrors {
+			res.Errors = append(res.Errors, rowErr)
+		}
+
+		report.add(row, record, rowErr)
+		return nil
+	}
+
+	flush := func() error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var rejected []*RowError
+
+		if len(chunk) > 0 {
+			if err := opts.Load(ctx, chunk, rows); err != nil {
+				return err
+			}
+
+			if opts.Check != nil {
+				var err error
+				if rejected, err = opts.Check(ctx, rows); err != nil {
+					return err
+				}
+			}
+		}
+
+		res.Rows += len(chunk)
+		res.reject(rejected, opts.MaxErrors)
+
+		if err := report.flush(rejected); err != nil {
+			return err
+		}
+
+		failed := make(map[int]bool, len(rejected))
+		for _, rowErr := range rejected {
+			failed[rowErr.Row] = true
+		}
+		for i := 0; i < len(chunk) && len(res.Sample) < opts.SampleSize; i++ {
+			if !failed[rows[i]] {
+				res.Sample = append(res.Sample, chunk[i])
+			}
+		}
+
+		chunk = chunk[:0]
+		rows = rows[:0]
+
+		if opts.Progress != nil {
+			opts.Progress(res)
+		}
+
+		return nil
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		// the report lines held until a flush are bounded by the chunk too
+		if len(chunk) == opts.ChunkSize || len(report.pending) >= opts.ChunkSize {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+
+		record, err := src.Next()
+		if err == io.EOF {
+			break
+		}
+		row++
+
+		if err != nil {
+			// a malformed record spoils only its row, anything else is the
+			// source failing and would fail again on every read
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, &RowError{Row: row, Fields: FieldErrors{{Message: err.Error()}}}
+			}
+
+			if err := reject(record, FieldErrors{{Message: err.Error()}}); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if isBlank(record) {
+			report.add(row, record, nil)
+			continue
+		}
+
+		var (
+			data T
+			errs FieldErrors
+		)
+
+		if err := decoder.Decode(record, &data); err != nil {
+			if !errors.As(err, &errs) {
+				errs = FieldErrors{{Message: err.Error()}}
+			}
+		}
+
+		if opts.Validate != nil {
+			if err := opts.Validate(&data); err != nil {
+				var verr ValidationError
+				if errors.As(err, &verr) {
+					errs = decoder.fieldErrors(record, verr, errs)
+				} else {
+					errs = append(errs, &FieldError{Message: err.Error()})
+				}
+			}
+		}
+
+		if len(errs) > 0 {
+			if err := reject(record, errs); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		report.add(row, record, nil)
+
+		chunk = append(chunk, data)
+		rows = append(rows, row)
+
+	}
+
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	if err := report.close(); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// reject moves rows that were loaded but turned out invalid to the failed
+// ones, keeping the errors in file order.
+func (res *Result) reject(errs []*RowError, maxErrors int) {
+	if maxErrors < 1 {
+		maxErrors = DefaultMaxErrors
+	}
+
+	res.Rows -= len(errs)
+	res.Failed += len(errs)
+
+	res.Errors = append(res.Errors, errs...)
+	sort.SliceStable(res.Errors, func(i, j int) bool { return res.Errors[i].Row < res.Errors[j].Row })
+	if len(res.Errors) > maxErrors {
+		res.Errors = res.Errors[:maxErrors]
+	}
+}
+
+func isBlank(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
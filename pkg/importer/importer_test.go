@@ -0,0 +1,213 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type row struct {
+	Id     string   `csv:"ID"`
+	Qty    int      `csv:"Qty"`
+	Price  *float64 `csv:"Price,omitempty"`
+	Note   *string  `csv:"Note,omitempty"`
+	Hidden string   `csv:"-"`
+}
+
+func run(t *testing.T, data string, chunkSize int, validate func(*row) error) ([][]row, *Result, error) {
+	t.Helper()
+
+	chunks := make([][]row, 0)
+	res, err := Run(context.Background(), NewCSVSource(strings.NewReader(data)), Options[row]{
+		ChunkSize: chunkSize,
+		Validate:  validate,
+		Load: func(_ context.Context, rows []row, _ []int) error {
+			chunks = append(chunks, append([]row(nil), rows...))
+			return nil
+		},
+	})
+
+	return chunks, res, err
+}
+
+func TestRunLoadsInChunks(t *testing.T) {
+	data := "ID,Qty,Price\na,1,1.5\nb,2,\n\nc,3,2\n"
+
+	chunks, res, err := run(t, data, 2, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, res.Rows)
+	require.Len(t, chunks, 2)
+	assert.Len(t, chunks[0], 2)
+	assert.Equal(t, "c", chunks[1][0].Id)
+
+	assert.Equal(t, 1.5, *chunks[0][0].Price)
+	assert.Nil(t, chunks[0][1].Price)
+	assert.Nil(t, chunks[0][0].Note)
+}
+
+func TestRunHeaderIsCaseInsensitive(t *testing.T) {
+	chunks, _, err := run(t, " id ,QTY\na,1\n", 0, nil)
+	require.NoError(t, err)
+	assert.Equal(t, row{Id: "a", Qty: 1}, chunks[0][0])
+}
+
+func TestRunMissingColumns(t *testing.T) {
+	_, _, err := run(t, "ID,Price\na,1\n", 0, nil)
+
+	var missing *MissingColumnsError
+	require.ErrorAs(t, err, &missing)
+	assert.Equal(t, []string{"Qty"}, missing.Columns)
+	assert.True(t, IsInputError(err))
+}
+
+func TestRunEmptyFile(t *testing.T) {
+	_, _, err := run(t, "", 0, nil)
+	assert.ErrorIs(t, err, ErrEmptyFile)
+}
+
+func TestRunRejectsBadRows(t *testing.T) {
+	validate := func(r *row) error {
+		errs := ValidationError{}
+		if r.Id == "c" {
+			errs["Id"] = append(errs["Id"], "ID tidak valid.")
+		}
+		if r.Qty < 0 {
+			errs["Qty"] = append(errs["Qty"], "Qty harus lebih dari 0.")
+		}
+		if len(errs) > 0 {
+			return errs
+		}
+		return nil
+	}
+
+	chunks, res, err := run(t, "ID,Qty,Price\na,1,\nb,x,y\nc,-3,\nd,4,\n", 0, validate)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, res.Rows)
+	assert.Equal(t, 2, res.Failed)
+	require.Len(t, chunks, 1)
+	assert.Equal(t, "d", chunks[0][1].Id)
+
+	require.Len(t, res.Errors, 2)
+	assert.Equal(t, 3, res.Errors[0].Row)
+	assert.Equal(t, FieldErrors{
+		{Column: "Qty", Value: "x", Message: "Qty harus bilangan bulat."},
+		{Column: "Price", Value: "y", Message: "Price harus angka."},
+	}, res.Errors[0].Fields)
+
+	// every failing field of the row is reported
+	assert.Equal(t, 4, res.Errors[1].Row)
+	assert.Equal(t, FieldErrors{
+		{Column: "ID", Value: "c", Message: "ID tidak valid."},
+		{Column: "Qty", Value: "-3", Message: "Qty harus lebih dari 0."},
+	}, res.Errors[1].Fields)
+}
+
+func TestRunSkipsValidationOfUndecodedCells(t *testing.T) {
+	validate := func(r *row) error {
+		if r.Qty == 0 {
+			return ValidationError{"Qty": {"Qty harus diisi."}}
+		}
+		return nil
+	}
+
+	_, res, err := run(t, "ID,Qty\na,x\n", 0, validate)
+	require.NoError(t, err)
+
+	require.Len(t, res.Errors, 1)
+	assert.Equal(t, FieldErrors{
+		{Column: "Qty", Value: "x", Message: "Qty harus bilangan bulat."},
+	}, res.Errors[0].Fields)
+}
+
+func TestRunCapsErrors(t *testing.T) {
+	res, err := Run(context.Background(), NewCSVSource(strings.NewReader("ID,Qty\na,x\nb,x\nc,x\n")), Options[row]{
+		MaxErrors: 2,
+		Load:      func(context.Context, []row, []int) error { return nil },
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, res.Failed)
+	assert.Len(t, res.Errors, 2)
+}
+
+func TestRunWritesReport(t *testing.T) {
+	var report strings.Builder
+
+	_, err := Run(context.Background(), NewCSVSource(strings.NewReader("ID,Qty\na,1\nb,x\n")), Options[row]{
+		Settings: Settings{Report: &report},
+		Load:     func(context.Context, []row, []int) error { return nil },
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "ID,Qty,errors\na,1,\nb,x,Qty harus bilangan bulat.\n", report.String())
+}
+
+func TestRunReportsRowsRejectedOnCheck(t *testing.T) {
+	var report strings.Builder
+
+	res, err := Run(context.Background(), NewCSVSource(strings.NewReader("ID,Qty\na,1\nb,x\nc,3\n")), Options[row]{
+		Settings:  Settings{Report: &report, SampleSize: 2},
+		ChunkSize: 3,
+		Load:      func(context.Context, []row, []int) error { return nil },
+		Check: func(_ context.Context, rows []int) ([]*RowError, error) {
+			assert.Equal(t, []int{2, 4}, rows)
+			return []*RowError{{Row: 2, Fields: FieldErrors{{Column: "ID", Value: "a", Message: "ID tidak ditemukan."}}}}, nil
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, res.Rows)
+	assert.Equal(t, 2, res.Failed)
+	assert.Equal(t, []any{row{Id: "c", Qty: 3}}, res.Sample)
+	assert.Equal(t, "ID,Qty,errors\na,1,ID tidak ditemukan.\nb,x,Qty harus bilangan bulat.\nc,3,\n", report.String())
+}
+
+type failingSource struct{ reads int }
+
+func (s *failingSource) Header() ([]string, error) { return []string{"ID", "Qty"}, nil }
+
+func (s *failingSource) Next() ([]string, error) {
+	s.reads++
+	return nil, errors.New("connection reset")
+}
+
+func TestRunStopsOnSourceError(t *testing.T) {
+	src := &failingSource{}
+
+	_, err := Run(context.Background(), src, Options[row]{
+		Load: func(context.Context, []row, []int) error { return nil },
+	})
+
+	var rowErr *RowError
+	require.ErrorAs(t, err, &rowErr)
+	assert.Equal(t, 2, rowErr.Row)
+	assert.Equal(t, 1, src.reads)
+}
+
+func TestRunStopsWhenCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := Run(ctx, NewCSVSource(strings.NewReader("ID,Qty\na,1\n")), Options[row]{
+		Load: func(context.Context, []row, []int) error { return nil },
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestResultReject(t *testing.T) {
+	res := &Result{Rows: 3, Failed: 1, Errors: []*RowError{{Row: 4}}}
+
+	res.reject([]*RowError{{Row: 2}, {Row: 5}}, 2)
+
+	assert.Equal(t, 1, res.Rows)
+	assert.Equal(t, 3, res.Failed)
+	require.Len(t, res.Errors, 2)
+	assert.Equal(t, 2, res.Errors[0].Row)
+	assert.Equal(t, 4, res.Errors[1].Row)
+}
@@ -0,0 +1,68 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Conflict strategies for rows whose key already exists.
+const (
+	ConflictFail   = "fail"   // the import fails on the first duplicate key
+	ConflictSkip   = "skip"   // the existing row is kept
+	ConflictUpdate = "update" // the existing row is overwritten, the last row of a key in the file wins
+)
+
+var ErrUnknownConflict = errors.New("unknown conflict strategy")
+
+// mergeQuery builds the INSERT that moves the staged rows into the target
+// table under the given conflict strategy, an empty one fails.
+func mergeQuery(table, staging string, key, columns []string, fixed map[string]string, insertOnly, keepNull []string, onConflict string) (string, error) {
+	var (
+		insertColumns = slices.Clone(columns)
+		selectColumns = slices.Clone(columns)
+		fixedColumns  = make([]string, 0, len(fixed))
+	)
+
+	for column := range fixed {
+		fixedColumns = append(fixedColumns, column)
+	}
+	sort.Strings(fixedColumns)
+
+	for _, column := range fixedColumns {
+		insertColumns = append(insertColumns, column)
+		selectColumns = append(selectColumns, fixed[column])
+	}
+
+	insert := fmt.Sprintf("INSERT INTO %s (%s)", table, strings.Join(insertColumns, ", "))
+
+	switch onConflict {
+	case "", ConflictFail:
+		return fmt.Sprintf("%s SELECT %s FROM %s ORDER BY import_row",
+			insert, strings.Join(selectColumns, ", "), staging), nil
+	case ConflictSkip:
+		return fmt.Sprintf("%s SELECT %s FROM %s ORDER BY import_row ON CONFLICT (%s) DO NOTHING",
+			insert, strings.Join(selectColumns, ", "), staging, strings.Join(key, ", ")), nil
+	case ConflictUpdate:
+		// a row may only be updated once per statement, keep the last of a key
+		sets := make([]string, 0, len(columns))
+		for _, column := range columns {
+			if slices.Contains(key, column) || slices.Contains(insertOnly, column) {
+				continue
+			}
+			if slices.Contains(keepNull, column) {
+				sets = append(sets, column+" = COALESCE(EXCLUDED."+column+", "+table+"."+column+")")
+				continue
+			}
+			sets = append(sets, column+" = EXCLUDED."+column)
+		}
+
+		return fmt.Sprintf("%s SELECT DISTINCT ON (%s) %s FROM %s ORDER BY %s, import_row DESC ON CONFLICT (%s) DO UPDATE SET %s",
+			insert, strings.Join(key, ", "), strings.Join(selectColumns, ", "), staging,
+			strings.Join(key, ", "), strings.Join(key, ", "), strings.Join(sets, ", ")), nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnknownConflict, onConflict)
+	}
+}
@@ -0,0 +1,40 @@
+package importer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeQuery(t *testing.T) {
+	var (
+		key        = []string{"id"}
+		columns    = []string{"id", "name", "password", "email"}
+		fixed      = map[string]string{"is_active": "TRUE"}
+		insertOnly = []string{"password"}
+		keepOnNull = []string{"email"}
+		insert     = "INSERT INTO users (id, name, password, email, is_active) "
+	)
+
+	tests := []struct {
+		onConflict string
+		want       string
+	}{
+		{"", insert + "SELECT id, name, password, email, TRUE FROM staging_users ORDER BY import_row"},
+		{ConflictFail, insert + "SELECT id, name, password, email, TRUE FROM staging_users ORDER BY import_row"},
+		{ConflictSkip, insert + "SELECT id, name, password, email, TRUE FROM staging_users ORDER BY import_row ON CONFLICT (id) DO NOTHING"},
+		{ConflictUpdate, insert + "SELECT DISTINCT ON (id) id, name, password, email, TRUE FROM staging_users ORDER BY id, import_row DESC ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, email = COALESCE(EXCLUDED.email, users.email)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.onConflict, func(t *testing.T) {
+			got, err := mergeQuery("users", "staging_users", key, columns, fixed, insertOnly, keepOnNull, tt.onConflict)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := mergeQuery("users", "staging_users", key, columns, fixed, insertOnly, keepOnNull, "replace")
+	assert.ErrorIs(t, err, ErrUnknownConflict)
+}
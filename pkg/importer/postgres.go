@@ -0,0 +1,257 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Target describes how rows of T reach their table. Rows are copied into a
+// temporary Table shaped like the Like table, then moved over in a single
+// INSERT, so constraint and conflict handling stay in SQL.
+type Target[T any] struct {
+	// Table is the name of the staging table, it is dropped on commit.
+	Table string
+	// Like is the table the staging table copies its columns from, and the
+	// table the rows are merged into.
+	Like string
+	// Key is the unique key conflicts are detected on.
+	Key []string
+	// Columns are the staging columns filled by Values, in order.
+	Columns []string
+	Values  func(row *T) []any
+	// Fixed are columns that are not in the file, set to an SQL expression
+	// on insert.
+	Fixed map[string]string
+	// InsertOnly columns are left as they are when a row is updated.
+	InsertOnly []string
+	// KeepOnNull columns keep their value when a row is updated with a NULL,
+	// e.g. optional columns a file may leave out.
+	KeepOnNull []string
+	// BatchColumn, when set, is filled with Settings.Batch on insert. A row
+	// keeps the batch that inserted it when a later import updates it.
+	BatchColumn string
+	// Checks reject staged rows the merge would fail on, e.g. a reference to
+	// a missing row, instead of failing the whole import.
+	Checks []Check
+	// AfterMerge runs on the same transaction once the rows are merged.
+	AfterMerge func(ctx context.Context, tx *sqlx.Tx) error
+}
+
+// Check is a condition staged rows must meet. Where selects the rows that do
+// not, on the staging table aliased s; they are left out of the merge and
+// reported with Message on Column, a staging column.
+type Check struct {
+	Column  string
+	Message string
+	Where   string
+}
+
+// Session is one import in flight. Nothing is visible to other connections
+// until Commit.
+type Session[T any] struct {
+	tx      *sqlx.Tx
+	target  Target[T]
+	columns []string
+	merge   string
+}
+
+// Begin opens a session that merges with the OnConflict strategy of
+// settings, see ConflictFail, ConflictSkip and ConflictUpdate, and tags the
+// inserted rows with its Batch.
+func Begin[T any](ctx context.Context, db *sqlx.DB, target Target[T], settings Settings) (*Session[T], error) {
+	fixed := target.Fixed
+	if target.BatchColumn != "" && settings.Batch != "" {
+		fixed = maps.Clone(target.Fixed)
+		if fixed == nil {
+			fixed = make(map[string]string, 1)
+		}
+		fixed[target.BatchColumn] = pq.QuoteLiteral(settings.Batch)
+	}
+
+	// import_row is the row of the file, the merge keeps the file order
+	columns := append(slices.Clone(target.Columns), "import_row")
+
+	merge, err := mergeQuery(target.Like, target.Table, target.Key, target.Columns, fixed, target.InsertOnly, target.KeepOnNull, settings.OnConflict)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(
+		`CREATE TEMP TABLE %s (LIKE %s INCLUDING DEFAULTS, import_row BIGINT NOT NULL) ON COMMIT DROP`,
+		pq.QuoteIdentifier(target.Table), pq.QuoteIdentifier(target.Like),
+	)
+
+	if _, err := tx.ExecContext(ctx, query); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return &Session[T]{tx: tx, target: target, columns: columns, merge: merge}, nil
+}
+
+// Load copies a chunk into the staging table with its own COPY, so the
+// chunk can be checked right away. It fits Options.Load.
+func (s *Session[T]) Load(ctx context.Context, chunk []T, rows []int) error {
+	stmt, err := s.tx.PrepareContext(ctx, pq.CopyIn(s.target.Table, s.columns...))
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for i := range chunk {
+		if _, err := stmt.ExecContext(ctx, append(s.target.Values(&chunk[i]), rows[i])...); err != nil {
+			return err
+		}
+	}
+
+	// the empty exec ends the COPY
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		return err
+	}
+
+	return stmt.Close()
+}
+
+// Check runs the checks of the target on the staged rows of the file rows
+// and removes the rows that fail any, returning them by file row. A row is
+// reported once with every check it fails. It fits Options.Check.
+func (s *Session[T]) Check(ctx context.Context, rows []int) ([]*RowError, error) {
+	if len(s.target.Checks) == 0 {
+		return nil, nil
+	}
+
+	var (
+		failed = make(map[int]*RowError)
+		staged = pq.QuoteIdentifier(s.target.Table)
+	)
+
+	for _, check := range s.target.Checks {
+		query := fmt.Sprintf(`SELECT import_row, COALESCE(s.%s::TEXT, '') FROM %s s WHERE s.import_row = ANY($1) AND (%s)`,
+			pq.QuoteIdentifier(check.Column), staged, check.Where)
+
+		result, err := s.tx.QueryContext(ctx, query, pq.Array(rows))
+		if err != nil {
+			return nil, err
+		}
+
+		for result.Next() {
+			var (
+				row   int
+				value string
+			)
+			if err := result.Scan(&row, &value); err != nil {
+				result.Close()
+				return nil, err
+			}
+
+			if failed[row] == nil {
+				failed[row] = &RowError{Row: row}
+			}
+			failed[row].Fields = append(failed[row].Fields, &FieldError{Column: check.Column, Value: value, Message: check.Message})
+		}
+		result.Close()
+
+		if err := result.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(failed) == 0 {
+		return nil, nil
+	}
+
+	errs := make([]*RowError, 0, len(failed))
+	lines := make([]int64, 0, len(failed))
+	for row, rowErr := range failed {
+		errs = append(errs, rowErr)
+		lines = append(lines, int64(row))
+	}
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Row < errs[j].Row })
+
+	query := fmt.Sprintf(`DELETE FROM %s WHERE import_row = ANY($1)`, staged)
+	if _, err := s.tx.ExecContext(ctx, query, pq.Array(lines)); err != nil {
+		return nil, err
+	}
+
+	return errs, nil
+}
+
+// Merge moves the staged rows into the target. It returns how many rows
+// the merge inserted and how many it updated, the others were skipped.
+func (s *Session[T]) Merge(ctx context.Context) (inserted, updated int, err error) {
+	// xmax is only set on rows an ON CONFLICT clause updated
+	query := `
+		WITH merged AS (` + s.merge + ` RETURNING (xmax = 0) AS inserted)
+		SELECT
+			COUNT(*) FILTER (WHERE inserted),
+			COUNT(*) FILTER (WHERE NOT inserted)
+		FROM merged
+	`
+
+	if err := s.tx.QueryRowxContext(ctx, query).Scan(&inserted, &updated); err != nil {
+		return 0, 0, err
+	}
+
+	if s.target.AfterMerge != nil {
+		if err := s.target.AfterMerge(ctx, s.tx); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	return inserted, updated, nil
+}
+
+func (s *Session[T]) Commit() error {
+	return s.tx.Commit()
+}
+
+// Rollback discards the import, it is a no-op after Commit.
+func (s *Session[T]) Rollback() error {
+	return s.tx.Rollback()
+}
+
+// Import runs src through a new Session of target with the settings of
+// opts, and commits it, or rolls it back once merged on a dry run. The Load
+// and Check of opts are replaced by the session's.
+func Import[T any](ctx context.Context, db *sqlx.DB, target Target[T], src Source, opts Options[T]) (*Result, error) {
+	session, err := Begin(ctx, db, target, opts.Settings)
+	if err != nil {
+		return nil, err
+	}
+	defer session.Rollback()
+
+	opts.Load = session.Load
+	opts.Check = session.Check
+
+	res, err := Run(ctx, src, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	res.Inserted, res.Updated, err = session.Merge(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res.Skipped = res.Rows - res.Inserted - res.Updated
+
+	if opts.DryRun {
+		return res, nil
+	}
+
+	if err := session.Commit(); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
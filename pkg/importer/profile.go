@@ -0,0 +1,276 @@
+package importer
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Profile adapts the files of a partner whose headers and dates differ from
+// the csv tags of T. Keys and values are matched case-insensitively.
+type Profile struct {
+	// Columns maps a column of the file to the csv column it stands for.
+	Columns map[string]string `json:"columns"`
+	// Formats are the date layouts of the file by csv column, in Go layout
+	// notation. Dates are rewritten to the layout the field validates
+	// against.
+	Formats map[string]string `json:"formats"`
+	// Defaults fill csv columns that are empty or missing from the file.
+	// They are taken as they are, in the layout of the field.
+	Defaults map[string]string `json:"defaults"`
+}
+
+// Column is a csv column of T.
+type Column struct {
+	Name     string `json:"name"`
+	Required bool   `json:"required"`
+	// Layout is the date layout the field validates against, if any.
+	Layout string `json:"layout,omitempty"`
+
+	index  []int
+	field  string // the name the validator reports the field under
+	tagged bool
+}
+
+// Columns lists the csv columns of T in field order.
+func Columns[T any]() []Column {
+	return csvColumns(reflect.TypeOf((*T)(nil)).Elem())
+}
+
+func csvColumns(t reflect.Type) []Column {
+	columns := make([]Column, 0, t.NumField())
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		tag, tagged := field.Tag.Lookup("csv")
+		if tag == "-" || !field.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		if !tagged || name == "" {
+			name, opts = field.Name, "omitempty"
+		}
+
+		columns = append(columns, Column{
+			Name:     name,
+			Required: opts != "omitempty",
+			Layout:   datetimeLayout(field),
+			index:    field.Index,
+			field:    validatorName(field),
+			tagged:   tagged,
+		})
+	}
+
+	return columns
+}
+
+// datetimeLayout is the parameter of the datetime rule of the field.
+func datetimeLayout(field reflect.StructField) string {
+	for _, rule := range strings.Split(field.Tag.Get("validate"), ",") {
+		if layout, ok := strings.CutPrefix(rule, "datetime="); ok {
+			return layout
+		}
+	}
+	return ""
+}
+
+// Check reports the keys of p that are not columns of columns, and the
+// formats given for columns that are not dates.
+func (p *Profile) Check(columns []Column) error {
+	var (
+		known = make(map[string]Column, len(columns))
+		errs  = make([]string, 0)
+	)
+
+	for _, column := range columns {
+		known[strings.ToLower(column.Name)] = column
+	}
+
+	for source, target := range p.Columns {
+		if _, ok := known[strings.ToLower(target)]; !ok {
+			errs = append(errs, "kolom "+target+" untuk "+source+" tidak dikenal.")
+		}
+	}
+
+	for name := range p.Formats {
+		column, ok := known[strings.ToLower(name)]
+		switch {
+		case !ok:
+			errs = append(errs, "kolom format "+name+" tidak dikenal.")
+		case column.Layout == "":
+			errs = append(errs, "kolom "+name+" bukan tanggal.")
+		}
+	}
+
+	for name := range p.Defaults {
+		if _, ok := known[strings.ToLower(name)]; !ok {
+			errs = append(errs, "kolom default "+name+" tidak dikenal.")
+		}
+	}
+
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return errors.New(strings.Join(errs, " "))
+	}
+
+	return nil
+}
+
+// rename replaces the columns of header that the profile maps.
+func (p *Profile) rename(header []string) []string {
+	names := make([]string, len(header))
+	for i, name := range header {
+		names[i] = strings.TrimSpace(name)
+		if target, ok := p.lookup(p.columns(), names[i]); ok {
+			names[i] = target
+		}
+	}
+	return names
+}
+
+func (p *Profile) format(column string) string {
+	if p == nil {
+		return ""
+	}
+	layout, _ := p.lookup(p.Formats, column)
+	return layout
+}
+
+func (p *Profile) fallback(column string) (string, bool) {
+	if p == nil {
+		return "", false
+	}
+	value, ok := p.lookup(p.Defaults, column)
+	return value, ok && value != ""
+}
+
+func (p *Profile) columns() map[string]string {
+	if p == nil {
+		return nil
+	}
+	return p.Columns
+}
+
+func (p *Profile) lookup(m map[string]string, key string) (string, bool) {
+	if value, ok := m[key]; ok {
+		return value, true
+	}
+	for k, value := range m {
+		if strings.EqualFold(strings.TrimSpace(k), key) {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// Suggestion is the column of T a column of the file most likely stands for.
+type Suggestion struct {
+	Source string  `json:"source"`
+	Column string  `json:"column"` // empty when nothing is close enough
+	Score  float64 `json:"score"`
+}
+
+// SuggestThreshold is the lowest score Suggest maps a column at.
+const SuggestThreshold = 0.6
+
+// Suggest matches each column of header to the column of columns with the
+// closest name, ignoring case, spaces and punctuation. Every column is
+// suggested once, the best scoring pairs first.
+func Suggest(header []string, columns []Column) []Suggestion {
+	type pair struct {
+		source, column int
+		score          float64
+	}
+
+	pairs := make([]pair, 0, len(header)*len(columns))
+	for i, source := range header {
+		for j, column := range columns {
+			if score := similarity(normalize(source), normalize(column.Name)); score >= SuggestThreshold {
+				pairs = append(pairs, pair{source: i, column: j, score: score})
+			}
+		}
+	}
+
+	sort.SliceStable(pairs, func(a, b int) bool {
+		return pairs[a].score > pairs[b].score
+	})
+
+	var (
+		suggestions = make([]Suggestion, len(header))
+		taken       = make(map[int]bool, len(columns))
+	)
+
+	for i, source := range header {
+		suggestions[i].Source = strings.TrimSpace(source)
+	}
+
+	for _, p := range pairs {
+		if suggestions[p.source].Column != "" || taken[p.column] {
+			continue
+		}
+		taken[p.column] = true
+		suggestions[p.source].Column = columns[p.column].Name
+		suggestions[p.source].Score = math.Round(p.score*100) / 100
+	}
+
+	return suggestions
+}
+
+func normalize(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// similarity is 1 for equal names, otherwise the better of the edit
+// distance ratio and how much of the longer name the shorter one covers.
+func similarity(a, b string) float64 {
+	if a == "" || b == "" {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+	score := 1 - float64(levenshtein(ra, rb))/float64(longest)
+
+	if strings.Contains(a, b) || strings.Contains(b, a) {
+		score = max(score, float64(min(len(ra), len(rb)))/float64(longest))
+	}
+
+	return score
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
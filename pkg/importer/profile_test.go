@@ -0,0 +1,83 @@
+package importer
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type datedRow struct {
+	Id     string `csv:"ID"`
+	Source string `csv:"Source"`
+	Date   string `csv:"Date" validate:"datetime=2006-01-02"`
+}
+
+func runProfile(t *testing.T, data string, profile *Profile) ([]datedRow, *Result, error) {
+	t.Helper()
+
+	rows := make([]datedRow, 0)
+	res, err := Run(context.Background(), NewCSVSource(strings.NewReader(data)), Options[datedRow]{
+		Settings: Settings{Profile: profile},
+		Load: func(_ context.Context, chunk []datedRow, _ []int) error {
+			rows = append(rows, chunk...)
+			return nil
+		},
+	})
+
+	return rows, res, err
+}
+
+func TestRunProfile(t *testing.T) {
+	profile := &Profile{
+		Columns:  map[string]string{"kode": "ID", "Tanggal Transaksi": "date"},
+		Formats:  map[string]string{"Date": "02/01/2006"},
+		Defaults: map[string]string{"Source": "offline"},
+	}
+
+	rows, res, err := runProfile(t, "KODE,tanggal transaksi\na,31/01/2024\nb,2024-02-01\n", profile)
+	require.NoError(t, err)
+
+	assert.Equal(t, []datedRow{{Id: "a", Source: "offline", Date: "2024-01-31"}}, rows)
+	require.Len(t, res.Errors, 1)
+	assert.Equal(t, "tanggal transaksi", res.Errors[0].Fields[0].Column)
+	assert.Equal(t, "2024-02-01", res.Errors[0].Fields[0].Value)
+}
+
+func TestRunProfileMissingColumns(t *testing.T) {
+	_, _, err := runProfile(t, "ID,Date\na,2024-01-31\n", nil)
+
+	var missingErr *MissingColumnsError
+	require.ErrorAs(t, err, &missingErr)
+	assert.Equal(t, []string{"Source"}, missingErr.Columns)
+}
+
+func TestProfileCheck(t *testing.T) {
+	columns := Columns[datedRow]()
+
+	assert.NoError(t, (&Profile{Columns: map[string]string{"kode": "id"}}).Check(columns))
+	assert.Error(t, (&Profile{Columns: map[string]string{"kode": "Code"}}).Check(columns))
+	assert.Error(t, (&Profile{Formats: map[string]string{"Source": "2006"}}).Check(columns))
+	assert.Error(t, (&Profile{Defaults: map[string]string{"Note": "-"}}).Check(columns))
+}
+
+func TestSuggest(t *testing.T) {
+	columns := []Column{
+		{Name: "TransactionID"},
+		{Name: "MemberID"},
+		{Name: "FK_PRODUCT_ID"},
+		{Name: "TransactionDatetime"},
+	}
+
+	suggestions := Suggest([]string{"transaction_id", "Product ID", "Transaction Date", "member", "Remarks"}, columns)
+
+	assert.Equal(t, []Suggestion{
+		{Source: "transaction_id", Column: "TransactionID", Score: 1},
+		{Source: "Product ID", Column: "FK_PRODUCT_ID", Score: 0.82},
+		{Source: "Transaction Date", Column: "TransactionDatetime", Score: 0.79},
+		{Source: "member", Column: "MemberID", Score: 0.75},
+		{Source: "Remarks"},
+	}, suggestions)
+}
@@ -0,0 +1,95 @@
+package importer
+
+import (
+	"encoding/csv"
+	"io"
+	"slices"
+)
+
+// ReportColumn is the column the report adds after the columns of the file.
+const ReportColumn = "errors"
+
+// report writes the annotated copy of the file, it is a no-op without a
+// writer. Lines are held until the rows loaded with them are checked, see
+// Options.Check.
+type report struct {
+	w       *csv.Writer
+	line    []string
+	pending []reportLine
+}
+
+type reportLine struct {
+	row    int
+	record []string
+	err    *RowError
+}
+
+func newReport(w io.Writer, header []string) (*report, error) {
+	if w == nil {
+		return &report{}, nil
+	}
+
+	r := &report{w: csv.NewWriter(w)}
+	if err := r.w.Write(append(append([]string(nil), header...), ReportColumn)); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// add holds a line of the file, rowErr is nil for a valid or blank row.
+func (r *report) add(row int, record []string, rowErr *RowError) {
+	if r.w == nil {
+		return
+	}
+
+	// the source reuses its record
+	r.pending = append(r.pending, reportLine{row: row, record: slices.Clone(record), err: rowErr})
+}
+
+// flush writes the held lines, with the errors of the rows rejected once
+// loaded.
+func (r *report) flush(rejected []*RowError) error {
+	if r.w == nil {
+		return nil
+	}
+
+	byRow := make(map[int]*RowError, len(rejected))
+	for _, rowErr := range rejected {
+		byRow[rowErr.Row] = rowErr
+	}
+
+	for _, line := range r.pending {
+		rowErr := line.err
+		if loaded, ok := byRow[line.row]; ok {
+			rowErr = loaded
+		}
+
+		if err := r.write(line.record, rowErr); err != nil {
+			return err
+		}
+	}
+	r.pending = r.pending[:0]
+
+	return nil
+}
+
+func (r *report) write(record []string, rowErr *RowError) error {
+	message := ""
+	if rowErr != nil {
+		message = rowErr.messages()
+	}
+
+	r.line = append(append(r.line[:0], record...), message)
+
+	return r.w.Write(r.line)
+}
+
+func (r *report) close() error {
+	if r.w == nil {
+		return nil
+	}
+
+	r.w.Flush()
+	return r.w.Error()
+}
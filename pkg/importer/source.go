@@ -0,0 +1,73 @@
+package importer
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+// Source yields the records of an uploaded file one at a time, so a file is
+// never held in memory as a whole. Next returns io.EOF after the last record.
+// Sources that hold resources also implement io.Closer.
+type Source interface {
+	Header() ([]string, error)
+	Next() ([]string, error)
+}
+
+type csvSource struct {
+	r       io.Reader
+	reader  *csv.Reader
+	header  []string
+	dialect Dialect
+}
+
+// NewCSVSource reads r in the encoding and with the delimiter it detects on
+// the first read, see Dialect.
+func NewCSVSource(r io.Reader) *csvSource {
+	return &csvSource{r: r}
+}
+
+func (s *csvSource) Header() ([]string, error) {
+	if s.header != nil {
+		return s.header, nil
+	}
+
+	if s.reader == nil {
+		text, dialect, err := detectDialect(s.r)
+		if err != nil {
+			return nil, err
+		}
+
+		s.dialect = dialect
+		s.reader = csv.NewReader(text)
+		s.reader.Comma = dialect.Delimiter
+		s.reader.FieldsPerRecord = -1
+		s.reader.ReuseRecord = true
+	}
+
+	header, err := s.reader.Read()
+	if err == io.EOF {
+		return nil, ErrEmptyFile
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// the reader reuses its record, the header must outlive the next read
+	s.header = append([]string(nil), header...)
+
+	return s.header, nil
+}
+
+func (s *csvSource) Next() ([]string, error) {
+	if _, err := s.Header(); err != nil {
+		return nil, err
+	}
+
+	return s.reader.Read()
+}
+
+// Dialect is the encoding and delimiter the file was read with, known once
+// the header is read.
+func (s *csvSource) Dialect() Dialect {
+	return s.dialect
+}
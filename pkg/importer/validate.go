@@ -0,0 +1,54 @@
+package importer
+
+import (
+	"codebase-app/pkg/errmsg"
+	"sort"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// ValidationError holds the validation messages of a row by field name, as
+// errmsg formats them for request bodies.
+type ValidationError map[string][]string
+
+func (e ValidationError) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, name := range e.names() {
+		messages = append(messages, strings.Join(e[name], "; "))
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (e ValidationError) names() []string {
+	names := make([]string, 0, len(e))
+	for name := range e {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+type Validator interface {
+	Validate(i any) error
+}
+
+// Validate runs v on row and turns its field errors into a ValidationError,
+// so Run can report each of them on its column. Other errors pass through.
+func Validate[T any](v Validator, row *T) error {
+	err := v.Validate(row)
+	if err == nil {
+		return nil
+	}
+
+	if _, ok := err.(validator.ValidationErrors); !ok {
+		return err
+	}
+
+	_, errs := errmsg.Errors(err, row)
+	if messages, ok := errs.(map[string][]string); ok && len(messages) > 0 {
+		return ValidationError(messages)
+	}
+
+	return err
+}
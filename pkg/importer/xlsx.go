@@ -0,0 +1,93 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/xuri/excelize/v2"
+)
+
+var ErrSheetNotFound = errors.New("sheet not found")
+
+var rawCells = excelize.Options{RawCellValue: true}
+
+type xlsxSource struct {
+	file   *excelize.File
+	rows   *excelize.Rows
+	header []string
+}
+
+// NewXLSXSource reads the rows of sheet, or of the first sheet when sheet is
+// empty. Rows are streamed, but the workbook itself is read whole since an
+// xlsx file is a zip archive. Cells are read as stored rather than as Excel
+// displays them, so dates come as serial numbers whatever the locale of the
+// workbook, see Decoder. The source must be closed.
+func NewXLSXSource(r io.Reader, sheet string) (*xlsxSource, error) {
+	file, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if sheet == "" {
+		sheet = file.GetSheetName(0)
+	}
+
+	if index, err := file.GetSheetIndex(sheet); err != nil || index < 0 {
+		file.Close()
+		return nil, fmt.Errorf("%w: %s", ErrSheetNotFound, sheet)
+	}
+
+	rows, err := file.Rows(sheet)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &xlsxSource{file: file, rows: rows}, nil
+}
+
+func (s *xlsxSource) Header() ([]string, error) {
+	if s.header != nil {
+		return s.header, nil
+	}
+
+	if !s.rows.Next() {
+		if err := s.rows.Error(); err != nil {
+			return nil, err
+		}
+		return nil, ErrEmptyFile
+	}
+
+	header, err := s.rows.Columns(rawCells)
+	if err != nil {
+		return nil, err
+	}
+
+	s.header = header
+
+	return s.header, nil
+}
+
+func (s *xlsxSource) Next() ([]string, error) {
+	if _, err := s.Header(); err != nil {
+		return nil, err
+	}
+
+	if !s.rows.Next() {
+		if err := s.rows.Error(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+
+	return s.rows.Columns(rawCells)
+}
+
+func (s *xlsxSource) Close() error {
+	if err := s.rows.Close(); err != nil {
+		s.file.Close()
+		return err
+	}
+	return s.file.Close()
+}
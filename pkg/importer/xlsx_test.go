@@ -0,0 +1,115 @@
+package importer
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+func workbook(t *testing.T) *bytes.Buffer {
+	t.Helper()
+
+	f := excelize.NewFile()
+	defer f.Close()
+
+	require.NoError(t, f.SetSheetRow("Sheet1", "A1", &[]any{"ignored"}))
+
+	_, err := f.NewSheet("Data")
+	require.NoError(t, err)
+	require.NoError(t, f.SetSheetRow("Data", "A1", &[]any{"ID", "Qty", "Price"}))
+	require.NoError(t, f.SetSheetRow("Data", "A2", &[]any{"a", 1, 1.5}))
+	require.NoError(t, f.SetSheetRow("Data", "A4", &[]any{"b", 2}))
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, f.Write(buf))
+
+	return buf
+}
+
+func TestXLSXSource(t *testing.T) {
+	src, err := NewXLSXSource(workbook(t), "Data")
+	require.NoError(t, err)
+	defer src.Close()
+
+	res, err := Run(context.Background(), src, Options[row]{
+		Load: func(_ context.Context, rows []row, _ []int) error {
+			assert.Equal(t, []row{
+				{Id: "a", Qty: 1, Price: func() *float64 { p := 1.5; return &p }()},
+				{Id: "b", Qty: 2},
+			}, rows)
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, res.Rows)
+}
+
+func TestXLSXSourceFirstSheet(t *testing.T) {
+	src, err := NewXLSXSource(workbook(t), "")
+	require.NoError(t, err)
+	defer src.Close()
+
+	header, err := src.Header()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ignored"}, header)
+
+	_, err = src.Next()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestXLSXSourceMissingSheet(t *testing.T) {
+	_, err := NewXLSXSource(workbook(t), "Nope")
+	assert.ErrorIs(t, err, ErrSheetNotFound)
+	assert.True(t, IsInputError(err))
+}
+
+// transaction has the columns and date rule of a product transaction import.
+type transaction struct {
+	Id        string `csv:"TransactionID"`
+	Qty       int    `csv:"Qty"`
+	CreatedAt string `csv:"TransactionDatetime" validate:"required,datetime=2006-01-02 15:04:05 MST"`
+}
+
+func TestXLSXSourceSerialDates(t *testing.T) {
+	jakarta := time.FixedZone("WIB", 7*60*60)
+
+	f := excelize.NewFile()
+	defer f.Close()
+
+	require.NoError(t, f.SetSheetRow("Sheet1", "A1", &[]any{"TransactionID", "Qty", "TransactionDatetime"}))
+	require.NoError(t, f.SetSheetRow("Sheet1", "A2", &[]any{"a", 1, time.Date(2024, 1, 31, 10, 0, 0, 0, time.UTC)}))
+	require.NoError(t, f.SetSheetRow("Sheet1", "A3", &[]any{"b", 2, 45322.5}))
+
+	style, err := f.NewStyle(&excelize.Style{NumFmt: 14})
+	require.NoError(t, err)
+	require.NoError(t, f.SetCellStyle("Sheet1", "C2", "C3", style))
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, f.Write(buf))
+
+	src, err := NewXLSXSource(buf, "")
+	require.NoError(t, err)
+	defer src.Close()
+
+	var loaded []transaction
+	res, err := Run(context.Background(), src, Options[transaction]{
+		Settings: Settings{Location: jakarta},
+		Load: func(_ context.Context, rows []transaction, _ []int) error {
+			loaded = append(loaded, rows...)
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, res.Rows)
+	assert.Empty(t, res.Errors)
+	assert.Equal(t, []transaction{
+		{Id: "a", Qty: 1, CreatedAt: "2024-01-31 03:00:00 UTC"},
+		{Id: "b", Qty: 2, CreatedAt: "2024-01-31 05:00:00 UTC"},
+	}, loaded)
+}
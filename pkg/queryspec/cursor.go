@@ -0,0 +1,225 @@
+package queryspec
+
+import (
+	"codebase-app/pkg/errmsg"
+	"codebase-app/pkg/types"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Keyset pages a list by the values of its key columns instead of an offset,
+// so deep pages cost as much as the first. The last column must be unique.
+type Keyset struct {
+	Columns []string
+	// Desc lists the newest keys first.
+	Desc bool
+	// Nullable are the key columns that may be NULL. Rows with a NULL key
+	// cannot be compared to a cursor, so they are left out of cursor pages.
+	Nullable []string
+}
+
+// Cursor is the key of the row a page starts after, or before when Prev.
+type Cursor struct {
+	Values []string `json:"v"`
+	Prev   bool     `json:"p,omitempty"`
+}
+
+// Decode reads an opaque cursor of k, nil for an empty one.
+func (k Keyset) Decode(token string) (*Cursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+
+	var (
+		cursor  = new(Cursor)
+		invalid = errmsg.NewCustomErrors(400).SetMessage("Cursor tidak valid").Add("cursor", "cursor tidak valid.")
+	)
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, invalid
+	}
+
+	if err := json.Unmarshal(raw, cursor); err != nil || len(cursor.Values) != len(k.Columns) {
+		return nil, invalid
+	}
+
+	return cursor, nil
+}
+
+// Check reports a sort in spec, cursor pages are in the order of the key.
+func (k Keyset) Check(spec *Spec) error {
+	if spec != nil && len(spec.Sorts) > 0 {
+		return errmsg.NewCustomErrors(400).SetMessage("Parameter query tidak valid").Add("sort", "sort tidak dapat digunakan dengan cursor.")
+	}
+	return nil
+}
+
+func (c Cursor) encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// Where is the condition, to append to a WHERE clause, of the rows past
+// cursor in its direction.
+func (k Keyset) Where(cursor *Cursor) (string, []any) {
+	if cursor == nil {
+		return "", nil
+	}
+
+	op := ">"
+	if k.Desc != cursor.Prev {
+		op = "<"
+	}
+
+	args := make([]any, len(cursor.Values))
+	for i, value := range cursor.Values {
+		args[i] = value
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(k.Columns)), ", ")
+
+	return " AND (" + strings.Join(k.Columns, ", ") + ") " + op + " (" + placeholders + ")", args
+}
+
+// NotNull is the condition, to append to a WHERE clause, of the rows with
+// none of the Nullable columns NULL.
+func (k Keyset) NotNull() string {
+	var b strings.Builder
+	for _, column := range k.Nullable {
+		b.WriteString(" AND " + column + " IS NOT NULL")
+	}
+	return b.String()
+}
+
+// OrderBy orders the rows past cursor nearest first. Page puts a previous
+// page back in list order.
+func (k Keyset) OrderBy(cursor *Cursor) string {
+	dir := " ASC"
+	if k.Desc != (cursor != nil && cursor.Prev) {
+		dir = " DESC"
+	}
+
+	return " ORDER BY " + strings.Join(k.Columns, dir+", ") + dir
+}
+
+// Page trims rows, fetched with a limit of limit+1 in the order of
+// Keyset.OrderBy, to a page in list order and returns the cursors of the
+// pages around it, empty at the ends of the list. key is the values of the
+// key columns of a row.
+func Page[T any](rows []T, limit int, cursor *Cursor, key func(T) []string) (page []T, next, prev string) {
+	var (
+		more     = len(rows) > limit
+		backward = cursor != nil && cursor.Prev
+	)
+
+	if more {
+		rows = rows[:limit]
+	}
+
+	if len(rows) == 0 {
+		return rows, "", ""
+	}
+
+	if backward {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	}
+
+	var (
+		first = Cursor{Values: key(rows[0]), Prev: true}
+		last  = Cursor{Values: key(rows[len(rows)-1])}
+	)
+
+	// coming from a page means there is one to go back to
+	if (backward && more) || (!backward && cursor != nil) {
+		prev = first.encode()
+	}
+	if (!backward && more) || backward {
+		next = last.encode()
+	}
+
+	return rows, next, prev
+}
+
+// PageReq is a list query to page by cursor, see SelectPage.
+type PageReq struct {
+	Keyset Keyset
+	Spec   *Spec
+	// Cursor is the next_cursor or prev_cursor of a previous page, empty for
+	// the first.
+	Cursor   string
+	Paginate int
+	// Count adds an estimated total.
+	Count bool
+}
+
+// SelectPage pages query, a SELECT ending in a WHERE clause, by the keyset
+// of req with the filter and search of its spec. key is the values of the
+// key columns of a row. A sort in the spec or an invalid cursor is a 400
+// error.
+func SelectPage[T any](ctx context.Context, db *sqlx.DB, query string, req PageReq, key func(T) []string) ([]T, types.Meta, error) {
+	var (
+		data = make([]T, 0)
+		meta = types.Meta{Paginate: req.Paginate}
+	)
+
+	if err := req.Keyset.Check(req.Spec); err != nil {
+		return nil, meta, err
+	}
+
+	cursor, err := req.Keyset.Decode(req.Cursor)
+	if err != nil {
+		return nil, meta, err
+	}
+
+	where, args := req.Spec.Where()
+	query += where + req.Keyset.NotNull()
+
+	if req.Count {
+		total, err := Estimate(ctx, db, db.Rebind(query), args...)
+		if err != nil {
+			return nil, meta, err
+		}
+		meta.TotalData = total
+		meta.TotalApproximate = true
+	}
+
+	keyWhere, keyArgs := req.Keyset.Where(cursor)
+	query += keyWhere + req.Keyset.OrderBy(cursor) + ` LIMIT ?`
+	args = append(args, keyArgs...)
+	args = append(args, req.Paginate+1)
+
+	if err := db.SelectContext(ctx, &data, db.Rebind(query), args...); err != nil {
+		return nil, meta, err
+	}
+
+	data, meta.NextCursor, meta.PrevCursor = Page(data, req.Paginate, cursor, key)
+
+	return data, meta, nil
+}
+
+// Estimate is the number of rows the planner expects query to return, from
+// table statistics rather than a count, for totals of large lists.
+func Estimate(ctx context.Context, db sqlx.QueryerContext, query string, args ...any) (int, error) {
+	var raw []byte
+	if err := sqlx.GetContext(ctx, db, &raw, "EXPLAIN (FORMAT JSON) "+query, args...); err != nil {
+		return 0, err
+	}
+
+	var plans []struct {
+		Plan struct {
+			Rows float64 `json:"Plan Rows"`
+		} `json:"Plan"`
+	}
+	if err := json.Unmarshal(raw, &plans); err != nil || len(plans) == 0 {
+		return 0, err
+	}
+
+	return int(plans[0].Plan.Rows), nil
+}
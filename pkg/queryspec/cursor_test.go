@@ -0,0 +1,96 @@
+package queryspec
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var keyset = Keyset{Columns: []string{"t.created_at", "t.id"}, Desc: true}
+
+func key(n int) []string {
+	return []string{"2024-01-01", strconv.Itoa(n)}
+}
+
+// fetch is what the database returns for cursor with a limit of limit+1
+// from rows 9 down to 0, newest first.
+func fetch(t *testing.T, cursor *Cursor, limit int) []int {
+	t.Helper()
+
+	rows := make([]int, 0)
+	switch {
+	case cursor == nil:
+		for n := 9; n >= 0 && len(rows) <= limit; n-- {
+			rows = append(rows, n)
+		}
+	case cursor.Prev:
+		from, _ := strconv.Atoi(cursor.Values[1])
+		for n := from + 1; n <= 9 && len(rows) <= limit; n++ {
+			rows = append(rows, n)
+		}
+	default:
+		from, _ := strconv.Atoi(cursor.Values[1])
+		for n := from - 1; n >= 0 && len(rows) <= limit; n-- {
+			rows = append(rows, n)
+		}
+	}
+
+	return rows
+}
+
+func TestPage(t *testing.T) {
+	page, next, prev := Page(fetch(t, nil, 4), 4, nil, key)
+	assert.Equal(t, []int{9, 8, 7, 6}, page)
+	assert.Empty(t, prev)
+
+	cursor, err := keyset.Decode(next)
+	require.NoError(t, err)
+	page, next, prev = Page(fetch(t, cursor, 4), 4, cursor, key)
+	assert.Equal(t, []int{5, 4, 3, 2}, page)
+
+	cursor, err = keyset.Decode(next)
+	require.NoError(t, err)
+	page, next, prev = Page(fetch(t, cursor, 4), 4, cursor, key)
+	assert.Equal(t, []int{1, 0}, page)
+	assert.Empty(t, next)
+
+	cursor, err = keyset.Decode(prev)
+	require.NoError(t, err)
+	page, _, prev = Page(fetch(t, cursor, 4), 4, cursor, key)
+	assert.Equal(t, []int{5, 4, 3, 2}, page)
+
+	cursor, err = keyset.Decode(prev)
+	require.NoError(t, err)
+	page, _, prev = Page(fetch(t, cursor, 4), 4, cursor, key)
+	assert.Equal(t, []int{9, 8, 7, 6}, page)
+	assert.Empty(t, prev)
+}
+
+func TestKeysetSQL(t *testing.T) {
+	where, args := keyset.Where(&Cursor{Values: key(5)})
+	assert.Equal(t, " AND (t.created_at, t.id) < (?, ?)", where)
+	assert.Equal(t, []any{"2024-01-01", "5"}, args)
+	assert.Equal(t, " ORDER BY t.created_at DESC, t.id DESC", keyset.OrderBy(nil))
+
+	where, _ = keyset.Where(&Cursor{Values: key(5), Prev: true})
+	assert.Equal(t, " AND (t.created_at, t.id) > (?, ?)", where)
+	assert.Equal(t, " ORDER BY t.created_at ASC, t.id ASC", keyset.OrderBy(&Cursor{Prev: true}))
+
+	assert.Empty(t, keyset.NotNull())
+	nullable := Keyset{Columns: keyset.Columns, Desc: true, Nullable: []string{"t.created_at"}}
+	assert.Equal(t, " AND t.created_at IS NOT NULL", nullable.NotNull())
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	_, err := keyset.Decode("not a cursor")
+	assert.Error(t, err)
+
+	_, err = keyset.Decode(Cursor{Values: []string{"1"}}.encode())
+	assert.Error(t, err)
+
+	cursor, err := keyset.Decode("")
+	assert.NoError(t, err)
+	assert.Nil(t, cursor)
+}
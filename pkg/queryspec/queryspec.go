@@ -0,0 +1,351 @@
+// Package queryspec reads the filters, sorting and search of list endpoints
+// from the query string against a whitelist of fields, and turns them into
+// SQL. Only the columns a list declares can be reached, values are always
+// bound.
+//
+//	?filter[city][eq]=Jakarta&filter[join_date][gte]=2024-01-01&sort=-join_date,id&search=jak
+package queryspec
+
+import (
+	"codebase-app/pkg/errmsg"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Op string
+
+const (
+	OpEq   Op = "eq"
+	OpIn   Op = "in"
+	OpGte  Op = "gte"
+	OpLte  Op = "lte"
+	OpLike Op = "like"
+)
+
+// Type is the kind of value of a field, it decides the operators allowed on
+// it and how values are checked.
+type Type int
+
+const (
+	Text Type = iota
+	// Number is a decimal column, values are compared as numeric.
+	Number
+	// Int is an integer column, values are bound as bigint so the indexes
+	// of integer columns stay usable.
+	Int
+	Bool
+	// Date is a date column, values are 2006-01-02.
+	Date
+	// Timestamp is a timestamp column. Values are 2006-01-02, which eq and
+	// lte take as the whole day, or RFC 3339.
+	Timestamp
+)
+
+var ops = map[Type][]Op{
+	Text:      {OpEq, OpIn, OpLike},
+	Number:    {OpEq, OpIn, OpGte, OpLte},
+	Int:       {OpEq, OpIn, OpGte, OpLte},
+	Bool:      {OpEq},
+	Date:      {OpEq, OpIn, OpGte, OpLte},
+	Timestamp: {OpEq, OpGte, OpLte},
+}
+
+// MaxIn is the most values an in filter takes.
+const MaxIn = 100
+
+// Field is a field of a list as it is named in the query string.
+type Field struct {
+	// Column is the SQL expression of the field.
+	Column string
+	Type   Type
+	// Sort allows sorting by the field.
+	Sort bool
+	// Search includes the field in the free text search.
+	Search bool
+}
+
+// Fields are the fields of a list by name.
+type Fields map[string]Field
+
+type Filter struct {
+	Field string
+	Op    Op
+	Value string
+}
+
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+// Spec is a parsed query, valid for the fields it was parsed against.
+type Spec struct {
+	Filters []Filter
+	Sorts   []Sort
+	Search  string
+
+	fields Fields
+}
+
+var filterKey = regexp.MustCompile(`^filter\[([^\]]+)\](?:\[([^\]]+)\])?$`)
+
+// Parse reads filter[field][op], sort and search from query. A filter
+// without an operator is eq. Unknown fields and operators are reported as
+// a 400 error, by parameter.
+func Parse(query map[string]string, fields Fields) (*Spec, error) {
+	var (
+		spec = &Spec{fields: fields}
+		errs = errmsg.NewCustomErrors(400).SetMessage("Parameter query tidak valid")
+	)
+
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		match := filterKey.FindStringSubmatch(key)
+		if match == nil {
+			continue
+		}
+
+		filter := Filter{Field: match[1], Op: Op(match[2]), Value: strings.TrimSpace(query[key])}
+		if filter.Op == "" {
+			filter.Op = OpEq
+		}
+
+		if err := fields.check(filter); err != "" {
+			errs.Add(key, err)
+			continue
+		}
+
+		spec.Filters = append(spec.Filters, filter)
+	}
+
+	if value := strings.TrimSpace(query["sort"]); value != "" {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.TrimSpace(name)
+			s := Sort{Field: strings.TrimPrefix(name, "-"), Desc: strings.HasPrefix(name, "-")}
+
+			if field, ok := fields[s.Field]; !ok || !field.Sort {
+				errs.Add("sort", "tidak dapat diurutkan berdasarkan "+s.Field+".")
+				continue
+			}
+
+			spec.Sorts = append(spec.Sorts, s)
+		}
+	}
+
+	spec.Search = strings.TrimSpace(query["search"])
+
+	if errs.HasErrors() {
+		return nil, errs
+	}
+
+	return spec, nil
+}
+
+// check is the problem with filter, empty when there is none.
+func (f Fields) check(filter Filter) string {
+	field, ok := f[filter.Field]
+	if !ok {
+		return "field " + filter.Field + " tidak dapat difilter."
+	}
+
+	allowed := false
+	for _, op := range ops[field.Type] {
+		allowed = allowed || op == filter.Op
+	}
+	if !allowed {
+		return "operator " + string(filter.Op) + " tidak didukung untuk " + filter.Field + "."
+	}
+
+	values := []string{filter.Value}
+	if filter.Op == OpIn {
+		values = splitIn(filter.Value)
+		if len(values) > MaxIn {
+			return "maksimal " + strconv.Itoa(MaxIn) + " nilai."
+		}
+	}
+
+	for _, value := range values {
+		if !field.Type.valid(value) {
+			return "nilai " + value + " tidak valid."
+		}
+	}
+
+	return ""
+}
+
+func (t Type) valid(value string) bool {
+	var err error
+
+	switch t {
+	case Text:
+		return value != ""
+	case Number:
+		_, err = strconv.ParseFloat(value, 64)
+	case Int:
+		_, err = strconv.ParseInt(value, 10, 64)
+	case Bool:
+		_, err = strconv.ParseBool(value)
+	case Date:
+		_, err = time.Parse(time.DateOnly, value)
+	case Timestamp:
+		if _, err = time.Parse(time.DateOnly, value); err != nil {
+			_, err = time.Parse(time.RFC3339, value)
+		}
+	}
+
+	return err == nil
+}
+
+func splitIn(value string) []string {
+	values := strings.Split(value, ",")
+	for i := range values {
+		values[i] = strings.TrimSpace(values[i])
+	}
+	return values
+}
+
+// Where is the filter and search of s as AND conditions to append to a
+// WHERE clause, with ? placeholders for the returned args. A nil Spec has
+// none.
+func (s *Spec) Where() (string, []any) {
+	if s == nil {
+		return "", nil
+	}
+
+	var (
+		b    strings.Builder
+		args = make([]any, 0, len(s.Filters))
+	)
+
+	for _, filter := range s.Filters {
+		var (
+			field    = s.fields[filter.Field]
+			column   = field.Column
+			cast     = field.Type.cast()
+			dayRange = field.Type == Timestamp && len(filter.Value) == len(time.DateOnly)
+		)
+
+		if dayRange {
+			cast = "::date"
+		}
+
+		switch {
+		case filter.Op == OpIn:
+			values := splitIn(filter.Value)
+			b.WriteString(" AND " + column + " IN (" + strings.TrimSuffix(strings.Repeat("?"+cast+", ", len(values)), ", ") + ")")
+			for _, value := range values {
+				args = append(args, value)
+			}
+		case filter.Op == OpLike:
+			b.WriteString(" AND " + column + " ILIKE ?")
+			args = append(args, "%"+EscapeLike(filter.Value)+"%")
+		case dayRange && filter.Op == OpEq:
+			b.WriteString(" AND " + column + " >= ?::date AND " + column + " < ?::date + 1")
+			args = append(args, filter.Value, filter.Value)
+		case dayRange && filter.Op == OpLte:
+			b.WriteString(" AND " + column + " < ?::date + 1")
+			args = append(args, filter.Value)
+		default:
+			b.WriteString(" AND " + column + " " + filter.Op.sql() + " ?" + cast)
+			args = append(args, filter.Value)
+		}
+	}
+
+	if s.Search != "" {
+		columns := make([]string, 0)
+		for _, name := range s.fields.names() {
+			if field := s.fields[name]; field.Search {
+				columns = append(columns, field.Column+" ILIKE ?")
+				args = append(args, "%"+EscapeLike(s.Search)+"%")
+			}
+		}
+
+		if len(columns) > 0 {
+			b.WriteString(" AND (" + strings.Join(columns, " OR ") + ")")
+		}
+	}
+
+	return b.String(), args
+}
+
+// OrderBy is the ORDER BY clause of s, or of fallback when s has no sort.
+// The fallback should end in a unique column so pages are stable.
+func (s *Spec) OrderBy(fallback string) string {
+	if s == nil || len(s.Sorts) == 0 {
+		return " ORDER BY " + fallback
+	}
+
+	var (
+		terms  = make([]string, 0, len(s.Sorts)+1)
+		unique = false
+	)
+
+	for _, sort := range s.Sorts {
+		column := s.fields[sort.Field].Column
+		unique = unique || column == fallback
+
+		if sort.Desc {
+			column += " DESC"
+		}
+		terms = append(terms, column)
+	}
+
+	if !unique {
+		terms = append(terms, fallback)
+	}
+
+	return " ORDER BY " + strings.Join(terms, ", ")
+}
+
+func (o Op) sql() string {
+	switch o {
+	case OpGte:
+		return ">="
+	case OpLte:
+		return "<="
+	default:
+		return "="
+	}
+}
+
+func (t Type) cast() string {
+	switch t {
+	case Number:
+		return "::numeric"
+	case Int:
+		return "::bigint"
+	case Bool:
+		return "::boolean"
+	case Date:
+		return "::date"
+	case Timestamp:
+		return "::timestamptz"
+	default:
+		return ""
+	}
+}
+
+func (f Fields) names() []string {
+	names := make([]string, 0, len(f))
+	for name := range f {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// EscapeLike escapes the wildcards of value so a LIKE pattern matches it
+// literally.
+func EscapeLike(value string) string {
+	return likeEscaper.Replace(value)
+}
@@ -0,0 +1,77 @@
+package queryspec
+
+import (
+	"codebase-app/pkg/errmsg"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var fields = Fields{
+	"id":         {Column: "t.id", Type: Int, Sort: true},
+	"price":      {Column: "t.price", Type: Number},
+	"name":       {Column: "t.name", Type: Text, Sort: true, Search: true},
+	"city":       {Column: "t.city", Type: Text, Search: true},
+	"join_date":  {Column: "t.join_date", Type: Date, Sort: true},
+	"created_at": {Column: "t.created_at", Type: Timestamp, Sort: true},
+}
+
+func TestParse(t *testing.T) {
+	spec, err := Parse(map[string]string{
+		"page":                    "1",
+		"filter[name]":            "a_b",
+		"filter[id][in]":          "1, 2",
+		"filter[price][gte]":      "1.5",
+		"filter[join_date][gte]":  "2024-01-01",
+		"filter[created_at][lte]": "2024-01-31",
+		"sort":                    "-join_date,id",
+		"search":                  "jak",
+	}, fields)
+	require.NoError(t, err)
+
+	where, args := spec.Where()
+	assert.Equal(t, " AND t.created_at < ?::date + 1"+
+		" AND t.id IN (?::bigint, ?::bigint)"+
+		" AND t.join_date >= ?::date"+
+		" AND t.name = ?"+
+		" AND t.price >= ?::numeric"+
+		" AND (t.city ILIKE ? OR t.name ILIKE ?)", where)
+	assert.Equal(t, []any{"2024-01-31", "1", "2", "2024-01-01", "a_b", "1.5", "%jak%", "%jak%"}, args)
+
+	assert.Equal(t, " ORDER BY t.join_date DESC, t.id", spec.OrderBy("t.id"))
+	assert.Equal(t, " ORDER BY t.join_date DESC, t.id, t.created_at", spec.OrderBy("t.created_at"))
+}
+
+func TestParseRejects(t *testing.T) {
+	_, err := Parse(map[string]string{
+		"filter[password]":      "x",
+		"filter[id]":            "1.5",
+		"filter[name][gte]":     "a",
+		"filter[join_date][eq]": "31/01/2024",
+		"filter[city][like]":    "",
+		"sort":                  "city",
+	}, fields)
+
+	var customErr *errmsg.CustomError
+	require.ErrorAs(t, err, &customErr)
+	assert.Equal(t, 400, customErr.Code)
+	assert.Len(t, customErr.Errors, 6)
+}
+
+func TestLikeEscapes(t *testing.T) {
+	spec, err := Parse(map[string]string{"filter[name][like]": "50%_off"}, fields)
+	require.NoError(t, err)
+
+	_, args := spec.Where()
+	assert.Equal(t, []any{`%50\%\_off%`}, args)
+}
+
+func TestNilSpec(t *testing.T) {
+	var spec *Spec
+
+	where, args := spec.Where()
+	assert.Empty(t, where)
+	assert.Empty(t, args)
+	assert.Equal(t, " ORDER BY t.id", spec.OrderBy("t.id"))
+}
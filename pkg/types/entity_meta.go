@@ -5,6 +5,12 @@ type Meta struct {
 	Paginate  int `json:"paginate"`
 	TotalData int `json:"total_data"`
 	TotalPage int `json:"total_page"`
+
+	// NextCursor and PrevCursor page a list in cursor mode, empty at its ends.
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+	// TotalApproximate marks TotalData as an estimate of the planner.
+	TotalApproximate bool `json:"total_approximate,omitempty"`
 }
 
 func (r *Meta) CountTotalPage(page, paginate, totalData int) {